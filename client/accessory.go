@@ -0,0 +1,75 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AccTimeUnit is the time unit of the accessory decoder activation time (see SetAccTime).
+const AccTimeUnit = 100 * time.Millisecond
+
+// Accessory represents an accessory like a turnout connected to an output of an accessory decoder.
+type Accessory struct {
+	c      *Client
+	addr   uint
+	out    byte
+	mu     sync.Mutex
+	thrown bool
+}
+
+// NewAccessory returns a new accessory instance of output out of the accessory decoder with address addr.
+func NewAccessory(c *Client, addr uint, out byte) *Accessory {
+	return &Accessory{c: c, addr: addr, out: out}
+}
+
+// Addr returns the accessory decoder address.
+func (a *Accessory) Addr() uint { return a.addr }
+
+// Out returns the accessory decoder output.
+func (a *Accessory) Out() byte { return a.out }
+
+// Thrown returns the last commanded state of the accessory.
+func (a *Accessory) Thrown() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.thrown
+}
+
+// Set sets the accessory state to thrown (true) or closed (false).
+func (a *Accessory) Set(thrown bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.c.SetAccFct(a.addr, a.out, thrown); err != nil {
+		return err
+	}
+	a.thrown = thrown
+	return nil
+}
+
+// Throw sets the accessory state to thrown.
+func (a *Accessory) Throw() error { return a.Set(true) }
+
+// Close sets the accessory state to closed.
+func (a *Accessory) Close() error { return a.Set(false) }
+
+// Pulse activates the accessory output for duration d (rounded up to AccTimeUnit).
+// The output is released by the accessory decoder after the activation time, which prevents
+// solenoid accessories from staying energized.
+func (a *Accessory) Pulse(d time.Duration) error {
+	units := (d + AccTimeUnit - 1) / AccTimeUnit
+	if units < 1 || units > 255 {
+		return fmt.Errorf("invalid accessory activation time %s - expected %s-%s", d, AccTimeUnit, 255*AccTimeUnit)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.c.SetAccTime(a.addr, a.out, byte(units)); err != nil {
+		return err
+	}
+	if _, err := a.c.SetAccFct(a.addr, a.out, true); err != nil {
+		return err
+	}
+	a.thrown = true
+	return nil
+}
@@ -0,0 +1,147 @@
+package client_test
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+	"github.com/pico-cs/go-client/client/fakecs"
+)
+
+// accStation is a test station handler accepting accessory commands.
+func accStation(cmd string, args []string) []string {
+	switch cmd {
+	case "af":
+		return []string{"=" + args[2]}
+	case "at", "as":
+		return []string{"=t"}
+	}
+	return []string{"?invcmd"}
+}
+
+func TestAccessory(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	c := newTestClient(t, recordStation(&mu, &cmds, accStation), nil)
+	a := client.NewAccessory(c, 10, 1)
+
+	if err := a.Throw(); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Thrown() {
+		t.Fatal("accessory not thrown")
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if a.Thrown() {
+		t.Fatal("accessory not closed")
+	}
+	if err := a.Pulse(250 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Thrown() {
+		t.Fatal("accessory not thrown after pulse")
+	}
+	if err := a.Pulse(0); err == nil {
+		t.Fatal("expected error on invalid activation time")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{"af 10 1 t", "af 10 1 f", "at 10 1 3", "af 10 1 t"}
+	if !slices.Equal(cmds, expected) {
+		t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+	}
+}
+
+// accStateStation returns a station handler providing the read form of the accessory commands.
+func accStateStation() stationHandler {
+	var mu sync.Mutex
+	fcts := map[string]string{}
+	status := map[string]string{}
+	return func(cmd string, args []string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		switch cmd {
+		case "h":
+			return []string{"-af <addr> <out> [t|f]: accessory function", "-as <addr> [<status>]: accessory status", "."}
+		case "af":
+			key := args[0] + " " + args[1]
+			if len(args) == 3 {
+				fcts[key] = args[2]
+			}
+			if v, ok := fcts[key]; ok {
+				return []string{"=" + v}
+			}
+		case "as":
+			if len(args) == 2 {
+				status[args[0]] = args[1]
+				return []string{"=t"}
+			}
+			if v, ok := status[args[0]]; ok {
+				return []string{"=" + v}
+			}
+		default:
+			return []string{"?invcmd"}
+		}
+		return []string{"?nodata"}
+	}
+}
+
+func TestAccState(t *testing.T) {
+	c := newTestClient(t, accStateStation(), nil)
+
+	// state unknown before the first set
+	if _, err := c.AccFct(10, 1); !errors.Is(err, client.ErrNoData) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrNoData)
+	}
+	if _, err := c.AccStatus(10); !errors.Is(err, client.ErrNoData) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrNoData)
+	}
+
+	if _, err := c.SetAccFct(10, 1, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SetAccStatus(10, 0x1f); err != nil {
+		t.Fatal(err)
+	}
+
+	fct, err := c.AccFct(10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fct {
+		t.Fatal("invalid accessory function false - expected true")
+	}
+	if _, err := c.AccFct(10, 0); !errors.Is(err, client.ErrNoData) {
+		t.Fatalf("invalid error %v for unset output - expected %v", err, client.ErrNoData)
+	}
+	status, err := c.AccStatus(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != 0x1f {
+		t.Fatalf("invalid accessory status %d - expected %d", status, 0x1f)
+	}
+}
+
+func TestAccStateNotImpl(t *testing.T) {
+	// the firmware provides the set form of the accessory commands only
+	cs := fakecs.New()
+	c := newTestClient(t, cs.Handle, nil)
+
+	if _, err := c.AccFct(10, 1); !errors.Is(err, client.ErrNotImpl) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrNotImpl)
+	}
+	if _, err := c.AccStatus(10); !errors.Is(err, client.ErrNotImpl) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrNotImpl)
+	}
+	if _, err := c.SetAccFct(10, 1, true); err != nil {
+		t.Fatal(err)
+	}
+}
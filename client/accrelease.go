@@ -0,0 +1,155 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// accTimer is a stoppable release timer (like time.Timer).
+type accTimer interface {
+	Stop() bool
+}
+
+// accOutput identifies the output of an accessory decoder.
+type accOutput struct {
+	addr uint
+	out  byte
+}
+
+// accRelease is the watchdog of the accessory decoder outputs (see WithAccAutoRelease).
+type accRelease struct {
+	maxOn     time.Duration
+	afterFunc func(d time.Duration, f func()) accTimer // time.AfterFunc (replaceable by tests)
+
+	mu      sync.Mutex
+	pending map[accOutput]accTimer // armed release timers
+	due     map[accOutput]bool     // failed releases to be resent after reconnect
+	closed  bool
+}
+
+func newAccRelease(maxOn time.Duration) *accRelease {
+	return &accRelease{
+		maxOn:     maxOn,
+		afterFunc: func(d time.Duration, f func()) accTimer { return time.AfterFunc(d, f) },
+		pending:   map[accOutput]accTimer{},
+		due:       map[accOutput]bool{},
+	}
+}
+
+// arm (re-)starts the release timer of an activated output.
+func (r *accRelease) arm(o accOutput, release func(o accOutput)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.armAfter(o, r.maxOn, release)
+}
+
+// armAfter starts the release timer of output o. The watchdog needs to be locked.
+func (r *accRelease) armAfter(o accOutput, d time.Duration, release func(o accOutput)) {
+	if r.closed {
+		return
+	}
+	if t, ok := r.pending[o]; ok {
+		t.Stop()
+	}
+	delete(r.due, o)
+	var t accTimer
+	t = r.afterFunc(d, func() {
+		r.mu.Lock()
+		if r.pending[o] != t { // disarmed or re-armed in the meantime
+			r.mu.Unlock()
+			return
+		}
+		delete(r.pending, o)
+		r.mu.Unlock()
+		release(o)
+	})
+	r.pending[o] = t
+}
+
+// disarm stops the release timer of a released output.
+func (r *accRelease) disarm(o accOutput) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.pending[o]; ok {
+		t.Stop()
+		delete(r.pending, o)
+	}
+	delete(r.due, o)
+}
+
+// failed records a release which could not be sent (like on a connection loss).
+func (r *accRelease) failed(o accOutput) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.pending[o]; !ok && !r.closed { // not re-armed in the meantime
+		r.due[o] = true
+	}
+}
+
+// rearm resends the failed releases immediately (like after a reconnect).
+// The armed release timers are kept, as the outputs are still activated.
+func (r *accRelease) rearm(release func(o accOutput)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for o := range r.due {
+		r.armAfter(o, 0, release)
+	}
+}
+
+// close stops all release timers.
+func (r *accRelease) close() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	for _, t := range r.pending {
+		t.Stop()
+	}
+	clear(r.pending)
+	clear(r.due)
+}
+
+// trackAccFct arms or disarms the release timer of an accessory output after a successful SetAccFct.
+func (c *Client) trackAccFct(addr uint, out byte, fct bool) {
+	if c.accRelease == nil {
+		return
+	}
+	o := accOutput{addr: addr, out: out}
+	if fct {
+		c.accRelease.arm(o, c.releaseAcc)
+	} else {
+		c.accRelease.disarm(o)
+	}
+}
+
+// trackAccResults arms or disarms the release timers of the accessory outputs set by the successful
+// batch commands (results[i] is the result of cmds[i]).
+func (c *Client) trackAccResults(cmds []batchCmd, results []BatchResult) {
+	if c.accRelease == nil {
+		return
+	}
+	for i, result := range results {
+		if result.Err != nil || cmds[i].cmd != cmdAccFct {
+			continue
+		}
+		if fct, err := parseBool(result.Reply); err == nil {
+			c.trackAccFct(cmds[i].args[0].(uint), cmds[i].args[1].(byte), fct)
+		}
+	}
+}
+
+// releaseAcc deactivates an accessory output exceeding the maximum on-time.
+func (c *Client) releaseAcc(o accOutput) {
+	if c.logger != nil {
+		c.logger.Warn("auto-release accessory output", "addr", o.addr, "out", o.out, "maxOn", c.accRelease.maxOn)
+	}
+	if _, err := c.SetAccFct(o.addr, o.out, false); err != nil {
+		if !isStationError(errors.Unwrap(err)) {
+			c.accRelease.failed(o)
+		}
+		c.asyncError(err)
+	}
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock running the timer functions synchronously.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Duration
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock   *fakeClock
+	expires time.Duration
+	f       func()
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	n := len(t.clock.timers)
+	t.clock.timers = slices.DeleteFunc(t.clock.timers, func(t2 *fakeTimer) bool { return t2 == t })
+	return len(t.clock.timers) != n
+}
+
+func (c *fakeClock) afterFunc(d time.Duration, f func()) accTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, expires: c.now + d, f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// advance advances the clock by d and runs the functions of the expired timers.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now += d
+	var expired []*fakeTimer
+	c.timers = slices.DeleteFunc(c.timers, func(t *fakeTimer) bool {
+		if t.expires <= c.now {
+			expired = append(expired, t)
+			return true
+		}
+		return false
+	})
+	c.mu.Unlock()
+	for _, t := range expired {
+		t.f()
+	}
+}
+
+func TestAccAutoRelease(t *testing.T) {
+	const maxOn = 2 * time.Second
+
+	newClient := func(t *testing.T) (*MockConn, *Client, *fakeClock) {
+		conn := NewMockConn()
+		conn.HandleFunc(func(cmd string, args []string) []string {
+			if cmd != cmdAccFct || len(args) != 3 {
+				return []string{string(tagNoSuccess) + etInvCmd}
+			}
+			return []string{string(tagSuccess) + args[2]}
+		})
+		c := New(conn, nil, WithAccAutoRelease(maxOn))
+		t.Cleanup(func() { c.Close() })
+		clock := &fakeClock{}
+		c.accRelease.afterFunc = clock.afterFunc
+		return conn, c, clock
+	}
+
+	released := func(conn *MockConn) int {
+		n := 0
+		for _, cmdLine := range conn.Written() {
+			if cmdLine == "af 5 1 f" {
+				n++
+			}
+		}
+		return n
+	}
+
+	setAccFct := func(t *testing.T, c *Client, fct bool) {
+		if _, err := c.SetAccFct(5, 1, fct); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("Release", func(t *testing.T) {
+		conn, c, clock := newClient(t)
+		setAccFct(t, c, true)
+
+		clock.advance(maxOn - time.Millisecond)
+		if n := released(conn); n != 0 {
+			t.Fatalf("output released %d times before max on-time", n)
+		}
+		clock.advance(time.Millisecond)
+		if n := released(conn); n != 1 {
+			t.Fatalf("output released %d times after max on-time - expected %d", n, 1)
+		}
+		clock.advance(maxOn)
+		if n := released(conn); n != 1 {
+			t.Fatalf("output released %d times - expected %d", n, 1)
+		}
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		conn, c, clock := newClient(t)
+		if _, err := c.Batch().SetAccFct(5, 1, true).Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		clock.advance(maxOn - time.Millisecond)
+		if n := released(conn); n != 0 {
+			t.Fatalf("output released %d times before max on-time", n)
+		}
+		clock.advance(time.Millisecond)
+		if n := released(conn); n != 1 {
+			t.Fatalf("output released %d times after max on-time - expected %d", n, 1)
+		}
+	})
+
+	t.Run("Reactivate", func(t *testing.T) {
+		conn, c, clock := newClient(t)
+		setAccFct(t, c, true)
+		clock.advance(maxOn / 2)
+		setAccFct(t, c, true) // restarts the on-time
+
+		clock.advance(maxOn - time.Millisecond)
+		if n := released(conn); n != 0 {
+			t.Fatalf("output released %d times before max on-time", n)
+		}
+		clock.advance(time.Millisecond)
+		if n := released(conn); n != 1 {
+			t.Fatalf("output released %d times after max on-time - expected %d", n, 1)
+		}
+	})
+
+	t.Run("Released", func(t *testing.T) {
+		conn, c, clock := newClient(t)
+		setAccFct(t, c, true)
+		clock.advance(maxOn / 2)
+		setAccFct(t, c, false)
+
+		clock.advance(maxOn)
+		if n := released(conn); n != 1 { // release of the caller only
+			t.Fatalf("output released %d times - expected %d", n, 1)
+		}
+	})
+
+	t.Run("Reconnect", func(t *testing.T) {
+		conn, c, clock := newClient(t)
+		setAccFct(t, c, true)
+
+		conn.Disconnect(nil)
+		clock.advance(maxOn) // release fails
+		if n := released(conn); n != 0 {
+			t.Fatalf("output released %d times on lost connection", n)
+		}
+
+		if err := c.Reconnect(); err != nil {
+			t.Fatal(err)
+		}
+		clock.advance(0) // release is resent immediately
+		if n := released(conn); n != 1 {
+			t.Fatalf("output released %d times after reconnect - expected %d", n, 1)
+		}
+	})
+}
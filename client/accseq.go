@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AccStep represents a step of an accessory sequence.
+type AccStep struct {
+	Delay time.Duration // wait time before the step is executed
+	Addr  uint          // accessory decoder address
+	Out   byte          // accessory decoder output
+	On    bool          // output state (ignored for pulses)
+	Pulse time.Duration // activation time of a pulse (see Accessory.Pulse) - zero: the output is set to On
+}
+
+// AccSequence represents a timed sequence of accessory activations (like a signal sequence or crossing gate animation).
+type AccSequence struct {
+	c     *Client
+	steps []AccStep
+}
+
+// NewAccSequence returns a new accessory sequence instance.
+func NewAccSequence(c *Client, steps ...AccStep) *AccSequence {
+	return &AccSequence{c: c, steps: steps}
+}
+
+// Add appends a step to the sequence.
+func (s *AccSequence) Add(step AccStep) *AccSequence {
+	s.steps = append(s.steps, step)
+	return s
+}
+
+// Steps returns the steps of the sequence.
+func (s *AccSequence) Steps() []AccStep { return s.steps }
+
+func (s *AccSequence) execStep(step AccStep) error {
+	if step.Pulse != 0 {
+		return NewAccessory(s.c, step.Addr, step.Out).Pulse(step.Pulse)
+	}
+	_, err := s.c.SetAccFct(step.Addr, step.Out, step.On)
+	return err
+}
+
+// Execute executes the steps of the sequence in order, waiting the step delay before each step.
+// The execution stops at the first failing step or if the context is cancelled.
+// The returned error identifies the index of the step not executed.
+func (s *AccSequence) Execute(ctx context.Context) error {
+	for i, step := range s.steps {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("accessory sequence step %d: %w", i, ctx.Err())
+		case <-time.After(step.Delay):
+		}
+		if err := s.execStep(step); err != nil {
+			return fmt.Errorf("accessory sequence step %d: %w", i, err)
+		}
+	}
+	return nil
+}
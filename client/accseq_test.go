@@ -0,0 +1,77 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestAccSequence(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	c := newTestClient(t, recordStation(&mu, &cmds, accStation), nil)
+
+	const delay = 20 * time.Millisecond
+
+	seq := client.NewAccSequence(c,
+		client.AccStep{Addr: 10, Out: 0, On: true},
+		client.AccStep{Delay: delay, Addr: 10, Out: 1, On: true},
+		client.AccStep{Delay: delay, Addr: 11, Out: 0, Pulse: 200 * time.Millisecond},
+	).Add(client.AccStep{Delay: delay, Addr: 10, Out: 0, On: false})
+
+	start := time.Now()
+	if err := seq.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 3*delay {
+		t.Fatalf("sequence executed in %s - expected at least %s", elapsed, 3*delay)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{"af 10 0 t", "af 10 1 t", "at 11 0 2", "af 11 0 t", "af 10 0 f"}
+	if !slices.Equal(cmds, expected) {
+		t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+	}
+}
+
+func TestAccSequenceStepError(t *testing.T) {
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		if cmd == "af" && args[0] == "12" {
+			return []string{"?invprm"}
+		}
+		return accStation(cmd, args)
+	}, nil)
+
+	seq := client.NewAccSequence(c,
+		client.AccStep{Addr: 10, On: true},
+		client.AccStep{Addr: 12, On: true},
+		client.AccStep{Addr: 13, On: true},
+	)
+	err := seq.Execute(context.Background())
+	if !errors.Is(err, client.ErrInvPrm) || !strings.Contains(err.Error(), "step 1") {
+		t.Fatalf("invalid error %v - expected step 1 %v", err, client.ErrInvPrm)
+	}
+}
+
+func TestAccSequenceCancel(t *testing.T) {
+	c := newTestClient(t, accStation, nil)
+
+	seq := client.NewAccSequence(c,
+		client.AccStep{Addr: 10, On: true},
+		client.AccStep{Delay: time.Minute, Addr: 10, On: false},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := seq.Execute(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("invalid error %v - expected %v", err, context.DeadlineExceeded)
+	}
+}
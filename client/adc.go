@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// adcCalibration is the linear calibration of an ADC input.
+type adcCalibration struct {
+	scale, offset float64
+}
+
+// adcCalibrations are the calibrations of the ADC inputs (see SetADCScale).
+type adcCalibrations struct {
+	mu           sync.Mutex
+	calibrations map[uint]adcCalibration
+}
+
+func (cs *adcCalibrations) set(input uint, cal adcCalibration) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.calibrations == nil {
+		cs.calibrations = map[uint]adcCalibration{}
+	}
+	cs.calibrations[input] = cal
+}
+
+// get returns the calibration of the ADC input (uncalibrated inputs: scale 1 and offset 0).
+func (cs *adcCalibrations) get(input uint) adcCalibration {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cal, ok := cs.calibrations[input]; ok {
+		return cal
+	}
+	return adcCalibration{scale: 1}
+}
+
+// SetADCScale sets the calibration of the ADC input converting the 'raw' ADC value to engineering units
+// (like volts or amps of a current sensor): the scaled value is raw*scale + offset (see IOADCScaled).
+// The calibration is stored by the client per input and kept over reconnects.
+func (c *Client) SetADCScale(input uint, scale, offset float64) {
+	c.adcCalibrations.set(input, adcCalibration{scale: scale, offset: offset})
+}
+
+// IOADCScaled returns the value of the ADC input in engineering units applying the calibration of the
+// input (see SetADCScale). The 'raw' value (see IOADC) is returned for uncalibrated inputs.
+func (c *Client) IOADCScaled(input uint) (float64, error) {
+	v, err := c.IOADC(input)
+	if err != nil {
+		return 0, err
+	}
+	cal := c.adcCalibrations.get(input)
+	return v*cal.scale + cal.offset, nil
+}
+
+// ADCStream delivers the samples of an ADC input (see StreamADC).
+type ADCStream struct {
+	// C delivers the samples. C is closed when the stream ends.
+	C    <-chan float64
+	done chan struct{}
+	err  error
+}
+
+// Err returns the error ending the stream (the read error or the context error).
+// Err blocks until the stream ended (C is closed).
+func (s *ADCStream) Err() error {
+	<-s.done
+	return s.err
+}
+
+// StreamADC reads the ADC input every interval and delivers the samples on the stream channel
+// until the context is done or a read error occurs. The first sample is read before StreamADC returns,
+// so that errors like an invalid input are returned directly.
+// Samples are not buffered: if the receiver is slower than interval, reads are skipped.
+func (c *Client) StreamADC(ctx context.Context, input uint, interval time.Duration) (*ADCStream, error) {
+	if interval <= 0 {
+		return nil, errors.New("stream adc error - interval needs to be greater than zero")
+	}
+	v, err := c.IOADC(input)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan float64)
+	s := &ADCStream{C: ch, done: make(chan struct{})}
+
+	go func() {
+		defer close(s.done)
+		defer close(ch)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				s.err = ctx.Err()
+				return
+			case ch <- v:
+			}
+
+			select {
+			case <-ctx.Done():
+				s.err = ctx.Err()
+				return
+			case <-ticker.C:
+			}
+
+			if v, err = c.IOADC(input); err != nil {
+				s.err = err
+				return
+			}
+		}
+	}()
+	return s, nil
+}
@@ -0,0 +1,118 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// adcStation returns a station handler replying the values in sequence and ErrIO if all values were read.
+func adcStation(values ...float64) stationHandler {
+	var mu sync.Mutex
+	return func(cmd string, args []string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case cmd != "ioadc":
+			return []string{"?invcmd"}
+		case args[0] != "4":
+			return []string{"?invprm"}
+		case len(values) == 0:
+			return []string{"?ioerr"}
+		}
+		v := values[0]
+		values = values[1:]
+		return []string{fmt.Sprintf("=%g", v)}
+	}
+}
+
+func TestStreamADC(t *testing.T) {
+	values := []float64{1.5, 2.25, 3, 4.75}
+
+	t.Run("ReadError", func(t *testing.T) {
+		c := newTestClient(t, adcStation(values...), nil)
+
+		s, err := c.StreamADC(context.Background(), 4, time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var samples []float64
+		for v := range s.C {
+			samples = append(samples, v)
+		}
+		if !slices.Equal(samples, values) {
+			t.Fatalf("invalid samples %v - expected %v", samples, values)
+		}
+		if err := s.Err(); !errors.Is(err, client.ErrIO) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrIO)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		c := newTestClient(t, adcStation(values...), nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s, err := c.StreamADC(ctx, 4, time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := <-s.C; v != values[0] {
+			t.Fatalf("invalid sample %g - expected %g", v, values[0])
+		}
+		cancel()
+		for range s.C {
+		}
+		if err := s.Err(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("invalid error %v - expected %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		c := newTestClient(t, adcStation(values...), nil)
+
+		if _, err := c.StreamADC(context.Background(), 5, time.Millisecond); !errors.Is(err, client.ErrInvPrm) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrInvPrm)
+		}
+		if _, err := c.StreamADC(context.Background(), 4, 0); err == nil {
+			t.Fatal("expected error on invalid interval")
+		}
+	})
+}
+
+func TestIOADCScaled(t *testing.T) {
+	tests := []struct {
+		name          string
+		calibrate     bool
+		scale, offset float64
+		raw, expected float64
+	}{
+		{"Uncalibrated", false, 0, 0, 2048, 2048},
+		{"Volts", true, 3.3 / 4096, 0, 2048, 1.65},
+		{"Amps", true, 0.01, -0.5, 150, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestClient(t, adcStation(test.raw), nil)
+			if test.calibrate {
+				c.SetADCScale(4, test.scale, test.offset)
+			}
+			c.SetADCScale(5, 100, 100) // calibration of another input
+
+			v, err := c.IOADCScaled(4)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(v-test.expected) > 1e-9 {
+				t.Fatalf("invalid scaled value %g - expected %g", v, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package client
+
+import "fmt"
+
+// DCC decoder address ranges.
+const (
+	MinShortAddr = 1     // lowest short (primary) address
+	MaxShortAddr = 127   // highest short (primary) address
+	MinLongAddr  = 128   // lowest long (extended) address
+	MaxLongAddr  = 10239 // highest long (extended) address
+)
+
+// DecoderAddress represents a DCC multifunction decoder address.
+// The address records if the decoder is addressed by its short address (CV 1)
+// or by its long address (CV 17 and 18). Use ShortAddr or LongAddr to create
+// a validated address.
+type DecoderAddress struct {
+	no   uint
+	long bool
+}
+
+// ShortAddr returns a short decoder address. The address no needs to be in the range MinShortAddr-MaxShortAddr.
+func ShortAddr(no uint) (DecoderAddress, error) {
+	if no < MinShortAddr || no > MaxShortAddr {
+		return DecoderAddress{}, fmt.Errorf("invalid short address %d - expected %d-%d", no, MinShortAddr, MaxShortAddr)
+	}
+	return DecoderAddress{no: no}, nil
+}
+
+// LongAddr returns a long decoder address. The address no needs to be in the range MinLongAddr-MaxLongAddr.
+func LongAddr(no uint) (DecoderAddress, error) {
+	if no < MinLongAddr || no > MaxLongAddr {
+		return DecoderAddress{}, fmt.Errorf("invalid long address %d - expected %d-%d", no, MinLongAddr, MaxLongAddr)
+	}
+	return DecoderAddress{no: no, long: true}, nil
+}
+
+// Uint returns the address number to be used with the loco methods accepting a plain address.
+func (a DecoderAddress) Uint() uint { return a.no }
+
+// IsLong returns true if a is a long address, false otherwise.
+func (a DecoderAddress) IsLong() bool { return a.long }
+
+func (a DecoderAddress) String() string {
+	if a.long {
+		return fmt.Sprintf("long %d", a.no)
+	}
+	return fmt.Sprintf("short %d", a.no)
+}
+
+// CV1718 returns the CV17 and CV18 values of a long address.
+// The values correspond to the values calculated by the command station (see Client.LocoCV1718).
+func (a DecoderAddress) CV1718() (byte, byte, error) {
+	if !a.long {
+		return 0, 0, fmt.Errorf("invalid address %s - expected long address", a)
+	}
+	return byte(0xc0 | a.no>>8), byte(a.no), nil
+}
+
+// LocoCV1718Addr is the DecoderAddress variant of LocoCV1718. An error is returned for a
+// short address without calling the command station.
+func (c *Client) LocoCV1718Addr(addr DecoderAddress) (byte, byte, error) {
+	if !addr.long {
+		return 0, 0, fmt.Errorf("invalid address %s - expected long address", addr)
+	}
+	return c.LocoCV1718(addr.no)
+}
+
+// decoder address CV indices
+const (
+	cv1Idx  = 1
+	cv17Idx = 17
+	cv18Idx = 18
+)
+
+// SetLocoLongAddress changes the address of the loco with address currentAddr to newLongAddr
+// via main track programming. Each step is verified by the value confirmed by the command station.
+//
+// For a long address (MinLongAddr-MaxLongAddr) CV 17 and 18 are calculated by the command station
+// (see LocoCV1718) and written in this order, as decoders apply CV 17 only together with CV 18.
+// CV 29 bit 5 is set last, so that the decoder does not switch to an incomplete long address.
+// For a short address (MinShortAddr-MaxShortAddr) CV 1 is written and CV 29 bit 5 is cleared
+// afterwards, switching the loco back to short addressing.
+//
+// The sequence is stopped at the first failing step. After the address switch the loco responds
+// to newLongAddr only.
+func (c *Client) SetLocoLongAddress(currentAddr, newLongAddr uint) error {
+	if newLongAddr <= MaxShortAddr {
+		addr, err := ShortAddr(newLongAddr)
+		if err != nil {
+			return err
+		}
+		if err := c.verifyLocoCVByte(currentAddr, cv1Idx, byte(addr.no)); err != nil {
+			return fmt.Errorf("set loco %d short address %d: %w", currentAddr, addr.no, err)
+		}
+		if err := c.verifyLocoCV29Bit5(currentAddr, false); err != nil {
+			return fmt.Errorf("set loco %d short address %d: %w", currentAddr, addr.no, err)
+		}
+		return nil
+	}
+
+	addr, err := LongAddr(newLongAddr)
+	if err != nil {
+		return err
+	}
+	cv17, cv18, err := c.LocoCV1718(addr.no)
+	if err != nil {
+		return fmt.Errorf("set loco %d long address %d: cv 17 and 18: %w", currentAddr, addr.no, err)
+	}
+	if expCV17, expCV18, _ := addr.CV1718(); cv17 != expCV17 || cv18 != expCV18 {
+		return fmt.Errorf("set loco %d long address %d: invalid cv 17 %d and 18 %d - expected %d and %d", currentAddr, addr.no, cv17, cv18, expCV17, expCV18)
+	}
+	if err := c.verifyLocoCVByte(currentAddr, cv17Idx, cv17); err != nil {
+		return fmt.Errorf("set loco %d long address %d: %w", currentAddr, addr.no, err)
+	}
+	if err := c.verifyLocoCVByte(currentAddr, cv18Idx, cv18); err != nil {
+		return fmt.Errorf("set loco %d long address %d: %w", currentAddr, addr.no, err)
+	}
+	if err := c.verifyLocoCV29Bit5(currentAddr, true); err != nil {
+		return fmt.Errorf("set loco %d long address %d: %w", currentAddr, addr.no, err)
+	}
+	return nil
+}
+
+// verifyLocoCVByte sets the indexed CV byte value of a loco and verifies the confirmed value.
+func (c *Client) verifyLocoCVByte(addr, idx uint, val byte) error {
+	v, err := c.SetLocoCVByte(addr, idx, val)
+	if err != nil {
+		return fmt.Errorf("cv %d: %w", idx, err)
+	}
+	if v != val {
+		return fmt.Errorf("cv %d: invalid confirmed value %d - expected %d", idx, v, val)
+	}
+	return nil
+}
+
+// verifyLocoCV29Bit5 sets the CV 29 bit 5 value of a loco and verifies the confirmed value.
+func (c *Client) verifyLocoCV29Bit5(addr uint, bit bool) error {
+	v, err := c.SetLocoCV29Bit5(addr, bit)
+	if err != nil {
+		return fmt.Errorf("cv 29 bit 5: %w", err)
+	}
+	if v != bit {
+		return fmt.Errorf("cv 29 bit 5: invalid confirmed value %t - expected %t", v, bit)
+	}
+	return nil
+}
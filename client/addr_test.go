@@ -0,0 +1,135 @@
+package client_test
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+	"github.com/pico-cs/go-client/client/fakecs"
+)
+
+func TestDecoderAddress(t *testing.T) {
+	tests := []struct {
+		no          uint
+		short, long bool // valid as short / long address
+	}{
+		{0, false, false},
+		{1, true, false},
+		{3, true, false},
+		{127, true, false},
+		{128, false, true},
+		{1024, false, true},
+		{10239, false, true},
+		{10240, false, false},
+	}
+
+	for _, test := range tests {
+		addr, err := client.ShortAddr(test.no)
+		if (err == nil) != test.short {
+			t.Errorf("short address %d: invalid error %v", test.no, err)
+		}
+		if err == nil && (addr.Uint() != test.no || addr.IsLong()) {
+			t.Errorf("short address %d: invalid address %s", test.no, addr)
+		}
+		addr, err = client.LongAddr(test.no)
+		if (err == nil) != test.long {
+			t.Errorf("long address %d: invalid error %v", test.no, err)
+		}
+		if err == nil && (addr.Uint() != test.no || !addr.IsLong()) {
+			t.Errorf("long address %d: invalid address %s", test.no, addr)
+		}
+	}
+}
+
+func TestDecoderAddressCV1718(t *testing.T) {
+	c := newTestClient(t, fakecs.New().Handle, nil)
+
+	for _, no := range []uint{128, 255, 256, 1024, 4711, 10239} {
+		addr, err := client.LongAddr(no)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cv17, cv18, err := addr.CV1718()
+		if err != nil {
+			t.Fatal(err)
+		}
+		csCV17, csCV18, err := c.LocoCV1718Addr(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cv17 != csCV17 || cv18 != csCV18 {
+			t.Errorf("address %d: cv17 %d cv18 %d - command station cv17 %d cv18 %d", no, cv17, cv18, csCV17, csCV18)
+		}
+		if back := uint(cv17&0x3f)<<8 | uint(cv18); back != no {
+			t.Errorf("address %d: cv17 %d cv18 %d decode to %d", no, cv17, cv18, back)
+		}
+	}
+
+	addr, err := client.ShortAddr(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := addr.CV1718(); err == nil {
+		t.Fatal("expected error on short address cv 17 and 18")
+	}
+	if _, _, err := c.LocoCV1718Addr(addr); err == nil {
+		t.Fatal("expected error on short address cv 17 and 18")
+	}
+}
+
+// addrStation is a test station handler accepting the main track address programming commands.
+// The confirmed value of CV 18 is corrupted if corruptCV18 is set.
+func addrStation(corruptCV18 bool) stationHandler {
+	return func(cmd string, args []string) []string {
+		switch {
+		case cmd == "lcv1718" && len(args) == 1:
+			return fakecs.New().Handle(cmd, args)
+		case cmd == "lcvbyte" && len(args) == 3:
+			if corruptCV18 && args[1] == "18" {
+				return []string{"=1"}
+			}
+			return []string{"=" + args[2]}
+		case cmd == "lcv29bit5" && len(args) == 2:
+			return []string{"=" + args[1]}
+		}
+		return []string{"?invcmd"}
+	}
+}
+
+func TestSetLocoLongAddress(t *testing.T) {
+	tests := []struct {
+		name        string
+		addr        uint
+		corruptCV18 bool
+		fail        bool
+		expected    []string
+	}{
+		{"Long", 1024, false, false, []string{"lcv1718 1024", "lcvbyte 3 17 196", "lcvbyte 3 18 0", "lcv29bit5 3 t"}},
+		{"LongMin", 128, false, false, []string{"lcv1718 128", "lcvbyte 3 17 192", "lcvbyte 3 18 128", "lcv29bit5 3 t"}},
+		{"Short", 127, false, false, []string{"lcvbyte 3 1 127", "lcv29bit5 3 f"}},
+		{"VerifyFailure", 1024, true, true, []string{"lcv1718 1024", "lcvbyte 3 17 196", "lcvbyte 3 18 0"}},
+		{"InvalidShort", 0, false, true, nil},
+		{"InvalidLong", 10240, false, true, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var cmds []string
+
+			c := newTestClient(t, recordStation(&mu, &cmds, addrStation(test.corruptCV18)), nil)
+
+			err := c.SetLocoLongAddress(3, test.addr)
+			if (err != nil) != test.fail {
+				t.Fatalf("invalid error %v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !slices.Equal(cmds, test.expected) {
+				t.Fatalf("invalid commands %v - expected %v", cmds, test.expected)
+			}
+		})
+	}
+}
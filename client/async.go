@@ -0,0 +1,94 @@
+package client
+
+// asyncError reports an error of an async command to the async error handler (if any).
+func (c *clientState) asyncError(err error) {
+	if c.asyncErrHandler != nil {
+		c.asyncErrHandler(err)
+	}
+}
+
+// callAsync writes the command without waiting for the reply.
+func (c *Client) callAsync(done func(reply string), cmd string, args ...any) error {
+	if err := c.acquire(); err != nil {
+		return err
+	}
+	defer c.release()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := &pendingReply{cmd: cmd, args: args, done: done}
+	if !c.replies.push(p) {
+		return &CallError{Cmd: cmd, Args: args, Err: c.lastReadErr}
+	}
+	if err := c.write(cmd, args); err != nil {
+		c.replies.remove(p)
+		return &CallError{Cmd: cmd, Args: args, Err: err}
+	}
+	return nil
+}
+
+// SetLocoSpeed128Async is the fire-and-forget variant of SetLocoSpeed128 intended for high-frequency
+// updates (like a throttle slider). The command is written without waiting for the command station reply,
+// so that the caller is not throttled by the round trip latency.
+//
+// The reply is consumed by the client in the background. As there is no per command result, only
+// validation and write errors are returned; command station errors are reported to the
+// async error handler (see WithAsyncErrorHandler). The cache (see WithCache) is updated on
+// a successful reply.
+//
+// If coalescing is enabled (see WithCoalescing) the update is queued instead and superseded by
+// a later update of the same loco property. After the client was closed (or while it is shut down,
+// see Shutdown) the update is rejected with ErrClientClosed.
+func (c *Client) SetLocoSpeed128Async(addr, speed uint) error {
+	if err := validateSpeed128(speed); err != nil {
+		return err
+	}
+	if c.coalesce != nil {
+		return c.coalesce.enqueue(coalesceKey{cmd: cmdLocoSpeed128, addr: addr}, func() error {
+			_, err := c.SetLocoSpeed128(addr, speed)
+			return err
+		})
+	}
+	return c.callAsync(func(reply string) {
+		if speed, err := parseUint(reply); err == nil {
+			c.cache.setSpeed(addr, speed)
+			c.state.setSpeed(addr, speed)
+		}
+	}, cmdLocoSpeed128, addr, speed)
+}
+
+// SetLocoDirAsync is the fire-and-forget variant of SetLocoDir (see SetLocoSpeed128Async).
+func (c *Client) SetLocoDirAsync(addr uint, dir bool) error {
+	if c.coalesce != nil {
+		return c.coalesce.enqueue(coalesceKey{cmd: cmdLocoDir, addr: addr}, func() error {
+			_, err := c.SetLocoDir(addr, dir)
+			return err
+		})
+	}
+	return c.callAsync(func(reply string) {
+		if dir, err := parseBool(reply); err == nil {
+			c.cache.setDir(addr, dir)
+			c.state.setDir(addr, dir)
+		}
+	}, cmdLocoDir, addr, dir)
+}
+
+// SetLocoFctAsync is the fire-and-forget variant of SetLocoFct (see SetLocoSpeed128Async).
+func (c *Client) SetLocoFctAsync(addr, no uint, fct bool) error {
+	if err := validateFct(no); err != nil {
+		return err
+	}
+	if c.coalesce != nil {
+		return c.coalesce.enqueue(coalesceKey{cmd: cmdLocoFct, addr: addr, no: no}, func() error {
+			_, err := c.SetLocoFct(addr, no, fct)
+			return err
+		})
+	}
+	return c.callAsync(func(reply string) {
+		if fct, err := parseBool(reply); err == nil {
+			c.cache.setFct(addr, no, fct)
+			c.state.setFct(addr, no, fct)
+		}
+	}, cmdLocoFct, addr, no, fct)
+}
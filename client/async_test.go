@@ -0,0 +1,140 @@
+package client_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// latencyStation serves conn replying each command after delay (pipelined, like a network round trip).
+// The station replies the loco speed command (ls) and the temperature command (t), a loco address 0
+// is replied with an invalid parameter error.
+func latencyStation(conn net.Conn, delay time.Duration) {
+	type cmdLine struct {
+		line string
+		t    time.Time
+	}
+	lines := make(chan cmdLine, 1000)
+
+	go func() {
+		defer close(lines)
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\r')
+			if err != nil {
+				return
+			}
+			lines <- cmdLine{line: strings.TrimSuffix(strings.TrimPrefix(line, "+"), "\r"), t: time.Now()}
+		}
+	}()
+
+	go func() {
+		speed := "0"
+		for l := range lines {
+			time.Sleep(time.Until(l.t.Add(delay)))
+			fields := strings.Fields(l.line)
+			var reply string
+			switch {
+			case fields[0] == "t":
+				reply = "=25.5"
+			case fields[0] == "ls" && fields[1] == "0":
+				reply = "?invprm"
+			case fields[0] == "ls" && len(fields) == 3:
+				speed = fields[2]
+				reply = "=" + speed
+			case fields[0] == "ls":
+				reply = "=" + speed
+			default:
+				reply = "?invcmd"
+			}
+			if _, err := fmt.Fprintf(conn, "%s\n", reply); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func newLatencyClient(t *testing.T, delay time.Duration, opts ...client.Option) *client.Client {
+	clientConn, stationConn := net.Pipe()
+	latencyStation(stationConn, delay)
+	c := client.New(&pipeConn{Conn: clientConn}, nil, opts...)
+	t.Cleanup(func() {
+		c.Close()
+		stationConn.Close()
+	})
+	return c
+}
+
+func TestAsync(t *testing.T) {
+	const (
+		delay = 10 * time.Millisecond
+		n     = 50
+	)
+
+	var mu sync.Mutex
+	var errs []error
+
+	c := newLatencyClient(t, delay, client.WithCache(), client.WithAsyncErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}))
+
+	start := time.Now()
+	for i := range n {
+		if err := c.SetLocoSpeed128Async(3, uint(i+2)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// async calls must not wait for the replies
+	if d := time.Since(start); d >= n*delay/2 {
+		t.Fatalf("async calls took %s - expected < %s", d, n*delay/2)
+	}
+
+	// the synchronous call gets its own reply and not a pending async reply
+	speed, err := c.LocoSpeed128(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if speed != n+1 {
+		t.Fatalf("invalid speed %d - expected %d", speed, n+1)
+	}
+	// cache is updated by the async replies
+	if speed, ok := c.CachedLocoSpeed128(3); !ok || speed != n+1 {
+		t.Fatalf("invalid cached speed %d %t - expected %d", speed, ok, n+1)
+	}
+
+	// station error is reported to the handler
+	if err := c.SetLocoSpeed128Async(0, 10); err != nil {
+		t.Fatal(err)
+	}
+	temp, err := c.Temp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp != 25.5 {
+		t.Fatalf("invalid temperature %f - expected %f", temp, 25.5)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 1 {
+		t.Fatalf("invalid number of async errors %d - expected %d", len(errs), 1)
+	}
+	var callErr *client.CallError
+	if !errors.As(errs[0], &callErr) || !errors.Is(errs[0], client.ErrInvPrm) || callErr.Cmd != "ls" {
+		t.Fatalf("invalid async error %v", errs[0])
+	}
+
+	// validation errors are returned directly
+	if err := c.SetLocoSpeed128Async(3, client.MaxSpeed128+1); err == nil {
+		t.Fatal("expected error on invalid speed")
+	}
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+type batchCmd struct {
+	cmd  string
+	args []any
+}
+
+// BatchResult is the result of a batch command.
+type BatchResult struct {
+	Cmd   string
+	Reply string
+	Err   error
+}
+
+// Batch queues commands to be sent to the command station at once.
+// All commands are written back-to-back and the replies are collected in command order,
+// which saves the per command round trip latency.
+type Batch struct {
+	c    *Client
+	cmds []batchCmd
+	err  error // error of an invalid queued command
+}
+
+// Batch returns a new command batch.
+func (c *Client) Batch() *Batch { return &Batch{c: c} }
+
+// addValid queues the command and records the validation error err of the command (if any).
+func (b *Batch) addValid(err error, cmd string, args ...any) *Batch {
+	if err != nil {
+		b.err = errors.Join(b.err, fmt.Errorf("batch command %d %s: %w", len(b.cmds), cmd, err))
+	}
+	return b.add(cmd, args...)
+}
+
+// addIO queues the io command with the io command value ioCmd.
+func (b *Batch) addIO(cmd string, ioCmd uint, args ...any) *Batch {
+	return b.add(cmd, append([]any{ioCmd}, args...)...)
+}
+
+func (b *Batch) add(cmd string, args ...any) *Batch {
+	b.cmds = append(b.cmds, batchCmd{cmd: cmd, args: args})
+	return b
+}
+
+// isStationError returns true if the error is a command station error reply, false otherwise.
+func isStationError(err error) bool {
+	if err == ErrUnknown {
+		return true
+	}
+	for _, stationErr := range errorMap {
+		if err == stationErr {
+			return true
+		}
+	}
+	return false
+}
+
+// Run sends the queued commands and returns the results in command order.
+// A command station error of a single command does not stop the processing of the remaining commands,
+// but is returned in the command result and as part of the combined error.
+// A connection error or timeout aborts the batch.
+// Like for single commands, the command station and connection errors are of type *CallError.
+// If the number of in-flight commands is limited (see WithMaxInFlight), the commands are sent
+// in chunks of at most the limit of commands.
+// The values of the successful commands are recorded in the state cache (see WithStateCache) and
+// the activated accessory outputs are auto-released (see WithAccAutoRelease).
+func (b *Batch) Run() ([]BatchResult, error) {
+	results, err := b.run()
+	b.c.state.setResults(b.cmds, results)
+	b.c.trackAccResults(b.cmds, results)
+	return results, err
+}
+
+func (b *Batch) run() ([]BatchResult, error) {
+	c := b.c
+
+	if b.err != nil { // do not send any command of an invalid batch
+		return nil, b.err
+	}
+
+	if err := c.acquire(); err != nil {
+		return nil, err
+	}
+	defer c.release()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	size := len(b.cmds)
+	if c.maxInFlight > 0 {
+		size = c.maxInFlight
+	}
+
+	results := make([]BatchResult, 0, len(b.cmds))
+	var errs []error
+	for start := 0; start < len(b.cmds); start += size {
+		chunk := b.cmds[start:min(start+size, len(b.cmds))]
+		ps := make([]*pendingReply, 0, len(chunk))
+		for _, cmd := range chunk {
+			p := newSyncReply(cmd.cmd, cmd.args)
+			if !c.replies.push(p) {
+				c.replies.remove(ps...)
+				return results, &CallError{Cmd: cmd.cmd, Args: cmd.args, Err: c.lastReadErr}
+			}
+			ps = append(ps, p)
+			c.writeCmd(cmd.cmd, cmd.args)
+		}
+		if err := c.flush(); err != nil {
+			c.replies.remove(ps...)
+			return results, &CallError{Cmd: chunk[0].cmd, Args: chunk[0].args, Err: err}
+		}
+
+		for i, cmd := range chunk {
+			result := BatchResult{Cmd: cmd.cmd}
+			reply, err := c.read(ps[i], c.timeout)
+			switch {
+			case err != nil && isStationError(err):
+				result.Err = &CallError{Cmd: cmd.cmd, Args: cmd.args, Err: err}
+				errs = append(errs, fmt.Errorf("batch command %d: %w", start+i, result.Err))
+			case err != nil:
+				// the replies of the remaining commands might still arrive
+				c.replies.abandon(c.timeout, ps[i+1:]...)
+				return results, &CallError{Cmd: cmd.cmd, Args: cmd.args, Err: err}
+			default:
+				v, ok := reply.(string)
+				if !ok {
+					return results, &CallError{Cmd: cmd.cmd, Args: cmd.args, Err: fmt.Errorf("invalid reply message type %T", reply)}
+				}
+				result.Reply = v
+			}
+			results = append(results, result)
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// SetCV queues a SetCV command.
+func (b *Batch) SetCV(idx CVIdx, val byte) *Batch { return b.add(cmdCV, idx, val) }
+
+// SetLocoDir queues a SetLocoDir command.
+func (b *Batch) SetLocoDir(addr uint, dir bool) *Batch { return b.add(cmdLocoDir, addr, dir) }
+
+// SetLocoSpeed128 queues a SetLocoSpeed128 command.
+func (b *Batch) SetLocoSpeed128(addr, speed uint) *Batch {
+	return b.addValid(validateSpeed128(speed), cmdLocoSpeed128, addr, speed)
+}
+
+// SetLocoFct queues a SetLocoFct command.
+func (b *Batch) SetLocoFct(addr, no uint, fct bool) *Batch {
+	return b.addValid(validateFct(no), cmdLocoFct, addr, no, fct)
+}
+
+// SetLocoCVByte queues a SetLocoCVByte command.
+func (b *Batch) SetLocoCVByte(addr, idx uint, val byte) *Batch {
+	return b.add(cmdLocoCVByte, addr, idx, val)
+}
+
+// SetLocoCVBit queues a SetLocoCVBit command.
+func (b *Batch) SetLocoCVBit(addr, idx uint, bit byte, val bool) *Batch {
+	return b.add(cmdLocoCVBit, addr, idx, bit, val)
+}
+
+// SetAccFct queues a SetAccFct command.
+func (b *Batch) SetAccFct(addr uint, out byte, fct bool) *Batch {
+	return b.add(cmdAccFct, addr, out, fct)
+}
+
+// SetAccTime queues a SetAccTime command.
+func (b *Batch) SetAccTime(addr uint, out, time byte) *Batch {
+	return b.add(cmdAccTime, addr, out, time)
+}
+
+// SetAccStatus queues a SetAccStatus command.
+func (b *Batch) SetAccStatus(addr uint, status byte) *Batch {
+	return b.add(cmdAccStatus, addr, status)
+}
+
+// SetIOVal queues a SetIOVal command.
+func (b *Batch) SetIOVal(cmd, gpio uint, value bool) *Batch {
+	return b.addIO(cmdIOVal, cmd, gpio, value)
+}
+
+// SetIODir queues a SetIODir command.
+func (b *Batch) SetIODir(cmd, gpio uint, value bool) *Batch {
+	return b.addIO(cmdIODir, cmd, gpio, value)
+}
+
+// SetIOUp queues a SetIOUp command.
+func (b *Batch) SetIOUp(cmd, gpio uint, value bool) *Batch {
+	return b.addIO(cmdIOUp, cmd, gpio, value)
+}
+
+// SetIODown queues a SetIODown command.
+func (b *Batch) SetIODown(cmd, gpio uint, value bool) *Batch {
+	return b.addIO(cmdIODown, cmd, gpio, value)
+}
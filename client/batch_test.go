@@ -0,0 +1,71 @@
+package client_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestBatch(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		mu.Lock()
+		cmds = append(cmds, strings.Join(append([]string{cmd}, args...), " "))
+		mu.Unlock()
+		switch cmd {
+		case "lf":
+			if args[1] == "28" { // function not supported by decoder
+				return []string{"?invprm"}
+			}
+			return []string{"=" + args[2]}
+		case "ls":
+			return []string{"=" + args[1]}
+		}
+		return []string{"?invcmd"}
+	}, nil)
+
+	b := c.Batch()
+	b.SetLocoFct(3, 0, true)
+	b.SetLocoFct(3, 28, true)
+	b.SetLocoSpeed128(3, 40)
+	results, err := b.Run()
+	if !errors.Is(err, client.ErrInvPrm) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrInvPrm)
+	}
+
+	expected := []client.BatchResult{
+		{Cmd: "lf", Reply: "t"},
+		{Cmd: "lf", Err: client.ErrInvPrm},
+		{Cmd: "ls", Reply: "40"},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("invalid number of results %d - expected %d", len(results), len(expected))
+	}
+	for i, result := range results {
+		if result.Cmd != expected[i].Cmd || result.Reply != expected[i].Reply || !errors.Is(result.Err, expected[i].Err) {
+			t.Fatalf("invalid result %d %v - expected %v", i, result, expected[i])
+		}
+	}
+
+	// reply stream is still in sync
+	speed, err := c.SetLocoSpeed128(3, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if speed != 50 {
+		t.Fatalf("invalid speed %d - expected %d", speed, 50)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expectedCmds := []string{"lf 3 0 t", "lf 3 28 t", "ls 3 40", "ls 3 50"}
+	if !slices.Equal(cmds, expectedCmds) {
+		t.Fatalf("invalid commands %v - expected %v", cmds, expectedCmds)
+	}
+}
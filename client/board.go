@@ -2,6 +2,8 @@ package client
 
 import (
 	"fmt"
+	"net"
+	"slices"
 	"strings"
 )
 
@@ -26,30 +28,78 @@ func (t BoardType) String() string {
 	return btTexts[t]
 }
 
-const (
-	numBoardMinValue = 2
-	numBoardMaxValue = 3
+// MarshalText implements the encoding.TextMarshaler interface (board type name).
+func (t BoardType) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (t *BoardType) UnmarshalText(text []byte) error {
+	i := slices.Index(btTexts, string(text))
+	if i < 0 {
+		return fmt.Errorf("invalid board type %q", text)
+	}
+	*t = BoardType(i)
+	return nil
+}
+
+// GPIOs available on the board header (GPIO 23, 24, 25 and 29 are used internally by the Pico W).
+var (
+	gpiosPico  = []uint{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 25, 26, 27, 28}
+	gpiosPicoW = []uint{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 26, 27, 28}
 )
 
+var btGPIOs = map[BoardType][]uint{BtPico: gpiosPico, BtPicoW: gpiosPicoW}
+
+// GPIOs returns the numbers of the GPIOs available for the IO commands of the board type.
+// For the Raspberry Pi Pico GPIO 25 (on-board LED) is included.
+func (t BoardType) GPIOs() []uint {
+	return slices.Clone(btGPIOs[t])
+}
+
+// NumGPIO returns the number of GPIOs available for the IO commands of the board type.
+func (t BoardType) NumGPIO() int { return len(btGPIOs[t]) }
+
+const numBoardMinValue = 2
+
 // Board hold information of the Pico board.
+//
+// The board reply has the format
+//
+//	<type> <id> [<mac>] [<firmware version> [<capability>...]]
+//
+// where the MAC address is only send by boards with network support (Pico W) and the firmware
+// version and capabilities are only send by newer firmware versions.
 type Board struct {
-	Type BoardType
-	ID   string
-	MAC  string
+	Type         BoardType `json:"type"`
+	ID           string    `json:"id"`
+	MAC          string    `json:"mac,omitempty"`
+	Firmware     string    `json:"firmware,omitempty"`     // firmware version (empty if not reported by the firmware)
+	Capabilities []string  `json:"capabilities,omitempty"` // capabilities supported by the firmware
 }
 
+// Supports returns true if the firmware reports the capability cap, false otherwise.
+func (b *Board) Supports(cap string) bool { return slices.Contains(b.Capabilities, cap) }
+
 func parseBoard(s string) (*Board, error) {
 	values := strings.Split(s, " ")
 	l := len(values)
-	if l < numBoardMinValue || l > numBoardMaxValue {
-		return nil, fmt.Errorf("parse board error - invalid number of values %d - expected %d-%d", l, numBoardMinValue, numBoardMaxValue)
+	if l < numBoardMinValue {
+		return nil, fmt.Errorf("parse board error - invalid number of values %d - expected at least %d", l, numBoardMinValue)
 	}
 	board := &Board{}
 	board.Type = btValues[values[0]]
 
 	board.ID = values[1]
-	if l > 2 {
-		board.MAC = values[2]
+	values = values[2:]
+	if len(values) > 0 {
+		if _, err := net.ParseMAC(values[0]); err == nil {
+			board.MAC, values = values[0], values[1:]
+		}
+	}
+	if len(values) > 0 {
+		board.Firmware, values = values[0], values[1:]
+	}
+	if len(values) > 0 {
+		board.Capabilities = values
 	}
 	return board, nil
 }
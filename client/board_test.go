@@ -0,0 +1,126 @@
+package client_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestBoardGPIOs(t *testing.T) {
+	tests := []struct {
+		boardType client.BoardType
+		numGPIO   int
+		included  []uint
+		excluded  []uint
+	}{
+		{client.BtPico, 27, []uint{0, 22, 25, 26, 28}, []uint{23, 24, 29}},
+		{client.BtPicoW, 26, []uint{0, 22, 26, 28}, []uint{23, 24, 25, 29}},
+		{client.BtUnknown, 0, nil, []uint{0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.boardType.String(), func(t *testing.T) {
+			gpios := test.boardType.GPIOs()
+			if test.boardType.NumGPIO() != test.numGPIO || len(gpios) != test.numGPIO {
+				t.Fatalf("invalid number of GPIOs %d - expected %d", len(gpios), test.numGPIO)
+			}
+			for _, gpio := range test.included {
+				if !slices.Contains(gpios, gpio) {
+					t.Errorf("GPIO %d missing", gpio)
+				}
+			}
+			for _, gpio := range test.excluded {
+				if slices.Contains(gpios, gpio) {
+					t.Errorf("GPIO %d not expected", gpio)
+				}
+			}
+		})
+	}
+}
+
+func TestBoardReply(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		board client.Board
+	}{
+		{"Pico", "pico E66038B713849D31", client.Board{Type: client.BtPico, ID: "E66038B713849D31"}},
+		{"PicoW", "pico_w E66038B713849D31 28:cd:c1:00:00:00", client.Board{Type: client.BtPicoW, ID: "E66038B713849D31", MAC: "28:cd:c1:00:00:00"}},
+		{"PicoFirmware", "pico E66038B713849D31 v0.10.0", client.Board{Type: client.BtPico, ID: "E66038B713849D31", Firmware: "v0.10.0"}},
+		{"PicoCapabilities", "pico E66038B713849D31 v0.10.0 adc railcom", client.Board{Type: client.BtPico, ID: "E66038B713849D31", Firmware: "v0.10.0", Capabilities: []string{"adc", "railcom"}}},
+		{"PicoWCapabilities", "pico_w E66038B713849D31 28:cd:c1:00:00:00 v0.10.0 adc", client.Board{Type: client.BtPicoW, ID: "E66038B713849D31", MAC: "28:cd:c1:00:00:00", Firmware: "v0.10.0", Capabilities: []string{"adc"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestClient(t, func(cmd string, args []string) []string {
+				if cmd != "b" {
+					return []string{"?invcmd"}
+				}
+				return []string{"=" + test.reply}
+			}, nil)
+
+			board, err := c.Board()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if board.Type != test.board.Type || board.ID != test.board.ID || board.MAC != test.board.MAC ||
+				board.Firmware != test.board.Firmware || !slices.Equal(board.Capabilities, test.board.Capabilities) {
+				t.Fatalf("invalid board %+v - expected %+v", board, test.board)
+			}
+			for _, capability := range test.board.Capabilities {
+				if !board.Supports(capability) {
+					t.Errorf("capability %s not supported", capability)
+				}
+			}
+			if board.Supports("unknown") {
+				t.Error("unexpected support of capability unknown")
+			}
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		c := newTestClient(t, func(cmd string, args []string) []string { return []string{"=pico"} }, nil)
+		if _, err := c.Board(); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestBoardJSON(t *testing.T) {
+	tests := []struct {
+		board    client.Board
+		expected string
+	}{
+		{client.Board{Type: client.BtPico, ID: "E66038B713849D31"}, `{"type":"Raspberry Pi Pico","id":"E66038B713849D31"}`},
+		{
+			client.Board{Type: client.BtPicoW, ID: "E66038B713849D31", MAC: "28:cd:c1:00:00:00", Firmware: "v0.10.0", Capabilities: []string{"adc"}},
+			`{"type":"Raspberry Pi Pico W","id":"E66038B713849D31","mac":"28:cd:c1:00:00:00","firmware":"v0.10.0","capabilities":["adc"]}`,
+		},
+	}
+
+	for _, test := range tests {
+		b, err := json.Marshal(test.board)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.expected {
+			t.Fatalf("invalid json %s - expected %s", b, test.expected)
+		}
+		var board client.Board
+		if err := json.Unmarshal(b, &board); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(board, test.board) {
+			t.Fatalf("invalid board %+v - expected %+v", board, test.board)
+		}
+	}
+
+	var board client.Board
+	if err := json.Unmarshal([]byte(`{"type":"Arduino"}`), &board); err == nil {
+		t.Fatal("expected error on invalid board type")
+	}
+}
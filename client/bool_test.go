@@ -0,0 +1,54 @@
+package client_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestSingleBoolReply(t *testing.T) {
+	dir := true
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		switch {
+		case cmd != "ld":
+			return []string{"?invcmd"}
+		case len(args) == 1:
+		case args[1] == "~":
+			dir = !dir
+		case args[1] == "t" || args[1] == "f":
+			dir = args[1] == "t"
+		default:
+			return []string{"=invalid"}
+		}
+		if dir {
+			return []string{"=t"}
+		}
+		return []string{"=f"}
+	}, nil)
+
+	steps := []struct {
+		name string
+		fct  func() (bool, error)
+		dir  bool
+	}{
+		{"Get", func() (bool, error) { return c.LocoDir(3) }, true},
+		{"Toggle", func() (bool, error) { return c.ToggleLocoDir(3) }, false},
+		{"Toggle", func() (bool, error) { return c.ToggleLocoDir(3) }, true},
+		{"Set", func() (bool, error) { return c.SetLocoDir(3, false) }, false},
+		{"Get", func() (bool, error) { return c.LocoDir(3) }, false},
+	}
+	for _, step := range steps {
+		dir, err := step.fct()
+		if err != nil {
+			t.Fatalf("%s: %s", step.name, err)
+		}
+		if dir != step.dir {
+			t.Fatalf("%s: invalid direction %t - expected %t", step.name, dir, step.dir)
+		}
+	}
+
+	if _, err := c.LocoFct(3, 0); !errors.Is(err, client.ErrInvCmd) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrInvCmd)
+	}
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"sync"
+)
+
+type fctKey struct {
+	addr, no uint
+}
+
+// cache stores the last commanded loco values. A nil cache is disabled.
+type cache struct {
+	mu     sync.Mutex
+	speeds map[uint]uint
+	dirs   map[uint]bool
+	fcts   map[fctKey]bool
+}
+
+func newCache() *cache {
+	return &cache{speeds: map[uint]uint{}, dirs: map[uint]bool{}, fcts: map[fctKey]bool{}}
+}
+
+func (c *cache) setSpeed(addr, speed uint) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.speeds[addr] = speed
+}
+
+func (c *cache) setDir(addr uint, dir bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dirs[addr] = dir
+}
+
+func (c *cache) setFct(addr, no uint, fct bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fcts[fctKey{addr: addr, no: no}] = fct
+}
+
+func (c *cache) speed(addr uint) (uint, bool) {
+	if c == nil {
+		return 0, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	speed, ok := c.speeds[addr]
+	return speed, ok
+}
+
+func (c *cache) dir(addr uint) (bool, bool) {
+	if c == nil {
+		return false, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dir, ok := c.dirs[addr]
+	return dir, ok
+}
+
+func (c *cache) fct(addr, no uint) (bool, bool) {
+	if c == nil {
+		return false, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fct, ok := c.fcts[fctKey{addr: addr, no: no}]
+	return fct, ok
+}
+
+func (c *cache) deleteLoco(addr uint) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.speeds, addr)
+	delete(c.dirs, addr)
+	for key := range c.fcts {
+		if key.addr == addr {
+			delete(c.fcts, key)
+		}
+	}
+}
+
+func (c *cache) reset() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.speeds)
+	clear(c.dirs)
+	clear(c.fcts)
+}
+
+// CachedLocoSpeed128 returns the last commanded speed of a loco and true, or false if no speed is cached.
+func (c *Client) CachedLocoSpeed128(addr uint) (uint, bool) { return c.cache.speed(addr) }
+
+// CachedLocoDir returns the last commanded direction of a loco and true, or false if no direction is cached.
+func (c *Client) CachedLocoDir(addr uint) (bool, bool) { return c.cache.dir(addr) }
+
+// CachedLocoFct returns the last commanded function value of a loco and true, or false if no value is cached.
+func (c *Client) CachedLocoFct(addr, no uint) (bool, bool) { return c.cache.fct(addr, no) }
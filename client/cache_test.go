@@ -0,0 +1,89 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// locoStation is a test station handler accepting loco set commands.
+func locoStation(cmd string, args []string) []string {
+	switch cmd {
+	case "ls", "ld":
+		return []string{"=" + args[len(args)-1]}
+	case "lf":
+		if args[2] == "~" {
+			return []string{"=t"}
+		}
+		return []string{"=" + args[2]}
+	case "rd":
+		return []string{"=" + args[0]}
+	case "rr":
+		return []string{"=t"}
+	}
+	return []string{"?invcmd"}
+}
+
+func TestCache(t *testing.T) {
+	c := newTestClient(t, locoStation, nil, client.WithCache())
+
+	if _, ok := c.CachedLocoSpeed128(3); ok {
+		t.Fatal("unexpected cached speed")
+	}
+
+	if _, err := c.SetLocoSpeed128(3, 40); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SetLocoDir(3, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ToggleLocoFct(3, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SetLocoSpeed128(5, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if speed, ok := c.CachedLocoSpeed128(3); !ok || speed != 40 {
+		t.Fatalf("invalid cached speed %d %t - expected %d", speed, ok, 40)
+	}
+	if dir, ok := c.CachedLocoDir(3); !ok || !dir {
+		t.Fatalf("invalid cached direction %t %t - expected %t", dir, ok, true)
+	}
+	if fct, ok := c.CachedLocoFct(3, 2); !ok || !fct {
+		t.Fatalf("invalid cached function %t %t - expected %t", fct, ok, true)
+	}
+
+	// invalidate loco 3
+	if _, err := c.RefreshBufferDelete(3); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.CachedLocoSpeed128(3); ok {
+		t.Fatal("unexpected cached speed after delete")
+	}
+	if _, ok := c.CachedLocoFct(3, 2); ok {
+		t.Fatal("unexpected cached function after delete")
+	}
+	if _, ok := c.CachedLocoSpeed128(5); !ok {
+		t.Fatal("missing cached speed of loco 5")
+	}
+
+	// invalidate all
+	if _, err := c.RefreshBufferReset(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.CachedLocoSpeed128(5); ok {
+		t.Fatal("unexpected cached speed after reset")
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	c := newTestClient(t, locoStation, nil)
+
+	if _, err := c.SetLocoSpeed128(3, 40); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.CachedLocoSpeed128(3); ok {
+		t.Fatal("unexpected cached speed of disabled cache")
+	}
+}
@@ -0,0 +1,99 @@
+package client_test
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestCallError(t *testing.T) {
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		if cmd == "ls" {
+			return []string{"?invprm"}
+		}
+		return []string{"?invcmd"}
+	}, nil)
+
+	tests := []struct {
+		name string
+		fn   func() error
+		cmd  string
+		args []any
+		err  error
+		msg  string
+	}{
+		{"SetLocoSpeed128", func() error { _, err := c.SetLocoSpeed128(3, 20); return err }, "ls", []any{uint(3), uint(20)}, client.ErrInvPrm, "command +ls 3 20: invalid parameter"},
+		{"SetLocoFct", func() error { _, err := c.SetLocoFct(3, 1, true); return err }, "lf", []any{uint(3), uint(1), true}, client.ErrInvCmd, "command +lf 3 1 t: invalid command"},
+		{"Temp", func() error { _, err := c.Temp(); return err }, "t", nil, client.ErrInvCmd, "command +t: invalid command"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.fn()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("invalid error %v - expected %v", err, test.err)
+			}
+			var callErr *client.CallError
+			if !errors.As(err, &callErr) {
+				t.Fatalf("invalid error type %T - expected %T", err, callErr)
+			}
+			if callErr.Cmd != test.cmd || !slices.Equal(callErr.Args, test.args) || callErr.Err != test.err {
+				t.Fatalf("invalid call error %+v - expected cmd %s args %v error %v", callErr, test.cmd, test.args, test.err)
+			}
+			if err.Error() != test.msg {
+				t.Fatalf("invalid error message %q - expected %q", err, test.msg)
+			}
+		})
+	}
+
+	// sentinel errors are not wrapped
+	if errors.Unwrap(client.ErrInvPrm) != nil {
+		t.Fatal("sentinel error is wrapping an error")
+	}
+}
+
+func TestBatchCallError(t *testing.T) {
+	t.Run("Station", func(t *testing.T) {
+		c := newTestClient(t, func(cmd string, args []string) []string {
+			if cmd == "ls" {
+				return []string{"?invprm"}
+			}
+			return []string{"=" + args[len(args)-1]}
+		}, nil)
+
+		results, err := c.Batch().SetLocoDir(3, true).SetLocoSpeed128(3, 20).Run()
+		var callErr *client.CallError
+		if !errors.As(err, &callErr) {
+			t.Fatalf("invalid error type %T - expected %T", err, callErr)
+		}
+		if len(results) != 2 {
+			t.Fatalf("invalid number of results %d - expected %d", len(results), 2)
+		}
+		if !errors.As(results[1].Err, &callErr) {
+			t.Fatalf("invalid result error type %T - expected %T", results[1].Err, callErr)
+		}
+		if callErr.Cmd != "ls" || !slices.Equal(callErr.Args, []any{uint(3), uint(20)}) || callErr.Err != client.ErrInvPrm {
+			t.Fatalf("invalid call error %+v", callErr)
+		}
+	})
+
+	t.Run("Connection", func(t *testing.T) {
+		conn := client.NewMockConn()
+		c := client.New(conn, nil)
+		defer c.Close()
+
+		conn.Disconnect(io.ErrUnexpectedEOF)
+
+		_, err := c.Batch().SetLocoDir(3, true).Run()
+		var callErr *client.CallError
+		if !errors.As(err, &callErr) {
+			t.Fatalf("invalid error type %T - expected %T", err, callErr)
+		}
+		if callErr.Cmd != "ld" || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("invalid call error %+v", callErr)
+		}
+	})
+}
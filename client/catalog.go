@@ -0,0 +1,104 @@
+package client
+
+import (
+	"strings"
+)
+
+// CommandArg represents an argument of a command station command (see CommandInfo).
+type CommandArg struct {
+	Name     string   // argument name (like "addr"), empty for arguments with choices only
+	Choices  []string // valid values (like "t", "f" and "~"), nil for arbitrary values
+	Optional bool
+}
+
+func (a CommandArg) String() string {
+	s := strings.Join(a.Choices, "|")
+	if a.Name != "" {
+		s = "<" + a.Name + ">"
+	}
+	if a.Optional {
+		return "[" + s + "]"
+	}
+	return s
+}
+
+// CommandInfo represents a command station command parsed from the help texts (see Commands).
+type CommandInfo struct {
+	Name        string       // command name (like "cv")
+	Args        []CommandArg // command arguments
+	Syntax      string       // command syntax as provided by the command station (like "cv <idx> [<value>]")
+	Description string       // command description (like "command station cv")
+}
+
+func (ci CommandInfo) String() string { return ci.Syntax + ": " + ci.Description }
+
+// parseCommandArgs parses the argument fields of a command syntax.
+// Arguments are enclosed in angle brackets (like "<addr>") or are a list of choices separated by '|' (like "t|f|~"),
+// optional arguments are enclosed in square brackets (like "[<value>]"), which might enclose more than one argument.
+// Bare words are accepted as argument names.
+func parseCommandArgs(fields []string) []CommandArg {
+	var args []CommandArg
+	depth := 0 // optional nesting depth
+	for _, field := range fields {
+		open := len(field) - len(strings.TrimLeft(field, "["))
+		closing := len(field) - len(strings.TrimRight(field, "]"))
+		depth += open
+		arg := CommandArg{Optional: depth > 0}
+		depth = max(depth-closing, 0)
+
+		value := strings.TrimSpace(field[open : len(field)-closing])
+		if value == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(value, "<") && strings.HasSuffix(value, ">"):
+			arg.Name = value[1 : len(value)-1]
+		case strings.Contains(value, "|"):
+			arg.Choices = strings.Split(value, "|")
+		default:
+			arg.Name = value
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+// parseCommandInfos parses the help lines into command infos.
+// A help line starts with the command syntax followed by a colon and the description (like "cv <idx> [<value>]: command station cv").
+// A leading command start tag ('+') of the command name and additional blanks are ignored.
+// Lines without command syntax (like empty lines or texts) are skipped.
+func parseCommandInfos(lines []string) []CommandInfo {
+	var infos []CommandInfo
+	for _, line := range lines {
+		syntax, description, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(syntax)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = strings.TrimPrefix(fields[0], string(tagStart))
+		if fields[0] == "" {
+			continue
+		}
+		infos = append(infos, CommandInfo{
+			Name:        fields[0],
+			Args:        parseCommandArgs(fields[1:]),
+			Syntax:      strings.Join(fields, " "),
+			Description: strings.TrimSpace(description),
+		})
+	}
+	return infos
+}
+
+// Commands returns the commands supported by the command station parsed from the help texts (see Help),
+// like for building a command palette or validating command arguments.
+// Help lines not following the command syntax format are skipped.
+func (c *Client) Commands() ([]CommandInfo, error) {
+	lines, err := c.Help()
+	if err != nil {
+		return nil, err
+	}
+	return parseCommandInfos(lines), nil
+}
@@ -0,0 +1,90 @@
+package client_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestCommands(t *testing.T) {
+	conn := client.NewMockConn()
+	conn.Reply("h",
+		"-h: help",
+		"-b: board info",
+		"-cv <idx> [<value>]: command station cv",
+		"-lf <addr> <no> [t|f|~]: loco function",
+		"-+ioval <cmd> <gpio> [t|f|~]:  io value",
+		"-iocfg  <cmd> [<gpio> <dir>]: configure io",
+		"-pcvbit addr idx bit [t|f]: loco cv bit",
+		"-", // empty line
+		"-no command syntax",
+		"-: no command name",
+		".",
+	)
+	c := client.NewClient(conn)
+	defer c.Close()
+
+	optional := func(name string) client.CommandArg { return client.CommandArg{Name: name, Optional: true} }
+	required := func(name string) client.CommandArg { return client.CommandArg{Name: name} }
+	bools := func(choices ...string) client.CommandArg {
+		return client.CommandArg{Choices: choices, Optional: true}
+	}
+
+	want := []client.CommandInfo{
+		{Name: "h", Syntax: "h", Description: "help"},
+		{Name: "b", Syntax: "b", Description: "board info"},
+		{
+			Name:        "cv",
+			Args:        []client.CommandArg{required("idx"), optional("value")},
+			Syntax:      "cv <idx> [<value>]",
+			Description: "command station cv",
+		},
+		{
+			Name:        "lf",
+			Args:        []client.CommandArg{required("addr"), required("no"), bools("t", "f", "~")},
+			Syntax:      "lf <addr> <no> [t|f|~]",
+			Description: "loco function",
+		},
+		{
+			Name:        "ioval",
+			Args:        []client.CommandArg{required("cmd"), required("gpio"), bools("t", "f", "~")},
+			Syntax:      "ioval <cmd> <gpio> [t|f|~]",
+			Description: "io value",
+		},
+		{
+			Name:        "iocfg",
+			Args:        []client.CommandArg{required("cmd"), optional("gpio"), optional("dir")},
+			Syntax:      "iocfg <cmd> [<gpio> <dir>]",
+			Description: "configure io",
+		},
+		{
+			Name:        "pcvbit",
+			Args:        []client.CommandArg{required("addr"), required("idx"), required("bit"), bools("t", "f")},
+			Syntax:      "pcvbit addr idx bit [t|f]",
+			Description: "loco cv bit",
+		},
+	}
+
+	infos, err := c.Commands()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != len(want) {
+		t.Fatalf("invalid number of commands %d - expected %d", len(infos), len(want))
+	}
+	for i, info := range infos {
+		if !reflect.DeepEqual(info, want[i]) {
+			t.Errorf("command %d: invalid info %#v - expected %#v", i, info, want[i])
+		}
+	}
+
+	// argument syntax
+	if s := infos[3].Args[2].String(); s != "[t|f|~]" {
+		t.Errorf("invalid argument syntax %s - expected %s", s, "[t|f|~]")
+	}
+	// command syntax
+	if s := infos[2].String(); s != "cv <idx> [<value>]: command station cv" {
+		t.Errorf("invalid command syntax %s - expected %s", s, "cv <idx> [<value>]: command station cv")
+	}
+}
@@ -3,10 +3,15 @@ package client
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"reflect"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,9 +31,12 @@ const (
 const (
 	charTrue   = 't'
 	charFalse  = 'f'
-	charToggle = "~"
+	charToggle = '~'
 )
 
+// Toggle is the command argument toggling a boolean value (like the direction of a loco).
+type Toggle struct{}
+
 func formatBool(b bool) byte {
 	if b {
 		return charTrue
@@ -43,6 +51,9 @@ const (
 	cmdTemp                = "t"
 	cmdCV                  = "cv"
 	cmdMTE                 = "mte"
+	cmdShort               = "short"
+	cmdDefaultSpeedSteps   = "dss"
+	cmdClientCount         = "cc"
 	cmdLocoDir             = "ld"
 	cmdLocoSpeed128        = "ls"
 	cmdLocoFct             = "lf"
@@ -51,6 +62,7 @@ const (
 	cmdLocoCV29Bit5        = "lcv29bit5"
 	cmdLocoLaddr           = "lladdr"
 	cmdLocoCV1718          = "lcv1718"
+	cmdProgCVByte          = "pcvbyte"
 	cmdAccFct              = "af"
 	cmdAccTime             = "at"
 	cmdAccStatus           = "as"
@@ -94,6 +106,49 @@ var (
 	ErrUnknown   = errors.New("unknown error")
 )
 
+// errReadTimeout is returned if the command station does not reply in time.
+var errReadTimeout = errors.New("read timeout")
+
+// ErrBusy is returned if the maximum number of in-flight commands is reached (see WithMaxInFlight)
+// or by TryCall if a command is running.
+var ErrBusy = errors.New("client busy")
+
+// ErrClientClosed is returned for commands called after the client was shut down (see Shutdown).
+var ErrClientClosed = errors.New("client closed")
+
+// ErrConnDead is returned if the connection is considered dead after consecutive read timeouts (see WithMaxTimeouts).
+var ErrConnDead = errors.New("connection dead")
+
+// CallError is returned by the client methods if a command fails and records the failed command and arguments.
+// The cause (like a command station error ErrInvPrm) is available via errors.Is and errors.As.
+type CallError struct {
+	Cmd  string
+	Args []any
+	Err  error
+}
+
+func (e *CallError) Error() string {
+	var b strings.Builder
+	b.WriteString("command ")
+	b.WriteByte(tagStart)
+	b.WriteString(e.Cmd)
+	for _, arg := range e.Args {
+		b.WriteByte(' ')
+		// the formatters of the client are not available: fall back to the default format
+		if s, err := formatArg(nil, arg); err == nil {
+			b.WriteString(s)
+		} else {
+			fmt.Fprint(&b, arg)
+		}
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+// Unwrap returns the cause of the command failure.
+func (e *CallError) Unwrap() error { return e.Err }
+
 var errorMap = map[string]error{
 	etInvCmd:    ErrInvCmd,
 	etInvPrm:    ErrInvPrm,
@@ -119,38 +174,136 @@ const (
 	CVBidiTE                    // BiDi (microseconds to power on before start of 5th sync bit)
 )
 
+// cvTexts needs to be kept in sync with the CV index constants.
+var cvTexts = []string{"MT", "NumSyncBit", "NumRepeat", "NumRepeatCV", "NumRepeatAcc", "BidiTS", "BidiTE"}
+
+func (idx CVIdx) String() string {
+	if int(idx) >= len(cvTexts) {
+		return fmt.Sprintf("CVIdx(%d)", byte(idx))
+	}
+	return cvTexts[idx]
+}
+
+// ParseCVIdx returns the CV index of the CV name (like "NumSyncBit").
+func ParseCVIdx(name string) (CVIdx, bool) {
+	i := slices.Index(cvTexts, name)
+	if i < 0 {
+		return 0, false
+	}
+	return CVIdx(i), true
+}
+
+// DefaultMaxLineSize is the default maximum size of a reply line (see WithMaxLineSize).
+const DefaultMaxLineSize = bufio.MaxScanTokenSize
+
+// initialLineBufSize is the initial size of the reply line buffer.
+const initialLineBufSize = 4096
+
 const (
-	replyChSize = 1
-	pushChSize  = 100
-	timeout     = 30
+	defaultTimeout      = 30 * time.Second
+	defaultWriteTimeout = 5 * time.Second
+	progTimeout         = 60 * time.Second // programming track commands wait for the decoder acknowledgement
 )
 
 // Client represents a command station client instance.
+//
+// The client methods are safe for concurrent use. As the command station processes one command
+// at a time, the commands are serialized: a command waits until the running command
+// (like a long running programming track command) is finished. Use TryCall to skip a command
+// instead of waiting.
 type Client struct {
-	conn        Conn
-	handler     func(msg Msg, err error)
-	mu          sync.Mutex // mutex for call
-	w           *bufio.Writer
-	wg          *sync.WaitGroup
-	replyCh     <-chan any
-	lastReadErr error
-}
-
-// New returns a new client instance.
-func New(conn Conn, handler func(msg Msg, err error)) *Client {
-	c := &Client{
-		conn:    conn,
-		handler: handler,
-		w:       bufio.NewWriter(conn),
-	}
+	*clientState // state shared with the background goroutines, so that the client itself can be garbage collected
+}
+
+// clientState is the state of a client instance.
+type clientState struct {
+	conn            Conn
+	handler         func(msg Msg, err error)
+	mu              sync.Mutex // mutex for call
+	w               *bufio.Writer
+	wg              *sync.WaitGroup
+	replies         *replyQueue // reply correlation of the current connection
+	lastReadErr     error
+	evict           evictFilter
+	cache           *cache
+	timeout         time.Duration
+	writeTimeout    time.Duration
+	maxTimeouts     int
+	numTimeouts     int // consecutive read timeouts
+	autoConnect     bool
+	gen             int // connection generation (incremented on each successful restart)
+	maxInFlight     int
+	inFlight        chan struct{} // in-flight command semaphore (nil: no limit)
+	stats           stats
+	busyErr         bool // return ErrBusy instead of waiting for a free in-flight slot
+	logger          *slog.Logger
+	disconnected    time.Time // time the reader detected the end of the connection
+	cmdMu           sync.Mutex
+	commands        map[string]CommandInfo // command station commands (nil: not queried yet)
+	gpios           []uint                 // command station board GPIOs (nil: not queried yet)
+	gpioWatchers    gpioWatchers
+	gate            callGate
+	retryAttempts   int // retry attempts of read commands on transient errors
+	retryDelay      time.Duration
+	asyncErrHandler func(err error)
+	coalesce        *coalesceQueue // coalescing queue of the async loco updates (nil: disabled)
+	pushBufSize     int
+	pushPolicy      PushPolicy
+	syncMode        bool        // replies are read inline on the calling goroutine (see NewSync)
+	syncReader      *syncReader // reply reader of the current connection in synchronous mode
+	leakWarning     bool        // warn if the client is garbage collected without being closed
+	accRelease      *accRelease // watchdog of the accessory outputs (nil: disabled)
+	maxLineSize     int         // maximum size of a reply line
+	adcCalibrations adcCalibrations
+	state           *stateCache // state cache replayed by Resync (nil: disabled)
+	formatters      formatters  // command argument formatters (see WithFormatter)
+	echoSkip        bool        // skip command echo lines (see WithEchoSkip)
+}
+
+// NewClient returns a new client instance configured by the options (like WithHandler or WithTimeout).
+func NewClient(conn Conn, opts ...Option) *Client {
+	c := newClient(conn, opts)
 	c.startup()
+	if c.coalesce != nil {
+		go c.coalesce.run(c.asyncError)
+	}
+	return c
+}
+
+// New returns a new client instance with the push message handler handler (see WithHandler).
+//
+// Deprecated: use NewClient with option WithHandler.
+func New(conn Conn, handler func(msg Msg, err error), opts ...Option) *Client {
+	return NewClient(conn, append([]Option{WithHandler(handler)}, opts...)...)
+}
+
+func newClient(conn Conn, opts []Option) *Client {
+	c := &Client{clientState: &clientState{
+		conn:         conn,
+		timeout:      defaultTimeout,
+		writeTimeout: defaultWriteTimeout,
+		pushBufSize:  defaultPushBufSize,
+		maxLineSize:  DefaultMaxLineSize,
+	}}
+	c.w = bufio.NewWriter(countWriter{w: conn, n: &c.stats.bytesOut})
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.maxInFlight > 0 {
+		c.inFlight = make(chan struct{}, c.maxInFlight)
+	}
+	c.watchLeak()
 	return c
 }
 
 func (c *Client) startup() {
 	c.wg = new(sync.WaitGroup)
-	var pushCh <-chan string
-	c.replyCh, pushCh = c.reader(c.wg)
+	c.replies = &replyQueue{}
+	if c.syncMode {
+		c.syncReader = newSyncReader(c.clientState, c.replies)
+		return
+	}
+	pushCh := c.reader(c.wg, c.replies)
 	c.pusher(c.wg, pushCh, c.handler)
 }
 
@@ -160,27 +313,142 @@ func (c *Client) shutdown() error {
 	return err
 }
 
-func (c *Client) reconnect() error {
+// reconnect returns the number of connect attempts and the error of the last attempt.
+func (c *Client) reconnect() (int, error) {
 	var err error
 	for i := 0; i < reconnectRetry; i++ {
 		time.Sleep(reconnectWait)
 		if err = c.conn.Connect(); err == nil {
-			return nil
+			return i + 1, nil
 		}
 	}
-	return err
+	return reconnectRetry, err
 }
 
-// Reconnect reconnects the client.
-func (c *Client) Reconnect() error {
-	c.shutdown() //nolint: errcheck
-	if err := c.reconnect(); err != nil {
+// reconnectBackoff reconnects like reconnect, but doubles the wait time after each failed attempt
+// (up to maxReconnectWait) and reports the attempts as ReconnectMsg to the handler.
+func (c *Client) reconnectBackoff() (int, error) {
+	return c.reconnectBackoffContext(context.Background())
+}
+
+// reconnectBackoffContext is like reconnectBackoff, but stops waiting for the next attempt if the context is done.
+func (c *Client) reconnectBackoffContext(ctx context.Context) (int, error) {
+	var err error
+	wait := reconnectWait
+	for i := 0; i < reconnectRetry; i++ {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done(): // i attempts were made so far
+			timer.Stop()
+			err = errors.Join(ctx.Err(), err)
+			c.notify(&ReconnectMsg{Attempt: i, Done: true, Err: err})
+			return i, err
+		}
+		c.notify(&ReconnectMsg{Attempt: i + 1})
+		if err = c.connectContext(ctx); err == nil {
+			c.notify(&ReconnectMsg{Attempt: i + 1, Done: true})
+			return i + 1, nil
+		}
+		wait = min(2*wait, maxReconnectWait)
+	}
+	c.notify(&ReconnectMsg{Attempt: reconnectRetry, Done: true, Err: err})
+	return reconnectRetry, err
+}
+
+// contextConnector is implemented by connections supporting a context aware connect (like TCPClient and Serial).
+type contextConnector interface {
+	ConnectContext(ctx context.Context) error
+}
+
+// connectContext connects via ConnectContext if supported by the connection and via Connect otherwise.
+func (c *Client) connectContext(ctx context.Context) error {
+	if cc, ok := c.conn.(contextConnector); ok {
+		return cc.ConnectContext(ctx)
+	}
+	return c.conn.Connect()
+}
+
+// notify reports a client generated message to the handler.
+func (c *Client) notify(msg Msg) {
+	if c.handler != nil {
+		c.handler(msg, nil)
+	}
+}
+
+// logReconnect logs the outcome of a reconnect.
+func (c *Client) logReconnect(disconnected time.Duration, attempts int, err error) {
+	if c.logger == nil {
+		return
+	}
+	if err != nil {
+		c.logger.Warn("reconnect", "disconnected", disconnected, "attempts", attempts, "ok", false, "error", err)
+		return
+	}
+	c.logger.Info("reconnect", "disconnected", disconnected, "attempts", attempts, "ok", true)
+}
+
+// restart shuts the client down and restarts it after the connection was re-established by connect.
+// The client needs to be locked. Concurrent restarts are collapsed: a restart waiting for the lock
+// returns without reconnecting if the client was restarted successfully in the meantime.
+func (c *Client) restart(connect func() (int, error)) error {
+	gen := c.gen
+	c.conn.Close() //nolint: errcheck
+	wg := c.wg
+	// wait for reader and pusher without lock, as the push message handler might call client methods
+	c.mu.Unlock()
+	wg.Wait()
+	c.mu.Lock()
+	if c.gen != gen {
+		return nil
+	}
+
+	c.cache.reset()
+	c.resetCommands()
+	c.numTimeouts = 0
+	attempts, err := connect()
+	c.logReconnect(time.Since(c.disconnected), attempts, err)
+	if err != nil {
 		return err
 	}
+	c.w.Reset(countWriter{w: c.conn, n: &c.stats.bytesOut}) // clear write error of the previous connection
 	c.startup()
+	if c.accRelease != nil {
+		c.accRelease.rearm(c.releaseAcc)
+	}
+	c.gen++
+	c.stats.reconnects.Add(1)
 	return nil
 }
 
+// Reconnect reconnects the client.
+// If a logger is set (see WithLogger) a log entry with the disconnected duration,
+// the number of connect attempts and the outcome is written.
+// Reconnect is safe for concurrent use: concurrent calls do not reconnect more than once
+// if the connection was re-established successfully.
+func (c *Client) Reconnect() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.restart(c.reconnect)
+}
+
+// ReconnectWithBackoff reconnects the client like Reconnect, but doubles the wait time between
+// the connect attempts (starting with 500 milliseconds, up to 8 seconds and 10 attempts at most).
+// The attempts are reported as ReconnectMsg to the handler (see WithAutoReconnect).
+// Connections implementing ConnectContext (like TCPClient and Serial) are connected with the context.
+// If the context is done while waiting for the next attempt, the reconnect is stopped and the context
+// error (joined with the error of the last attempt) is returned; the final ReconnectMsg reports the
+// number of attempts made so far. On exhaustion the error of the last
+// attempt is returned.
+func (c *Client) ReconnectWithBackoff(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.restart(func() (int, error) { return c.reconnectBackoffContext(ctx) })
+}
+
+// MaxLineSize returns the maximum size of a reply line (see WithMaxLineSize).
+func (c *Client) MaxLineSize() int { return c.maxLineSize }
+
 // IsSerialConn returns true if the connection is serial, false otherwise.
 func (c *Client) IsSerialConn() bool {
 	_, ok := c.conn.(*Serial)
@@ -188,7 +456,87 @@ func (c *Client) IsSerialConn() bool {
 }
 
 // Close closes the client connection.
-func (c *Client) Close() error { return c.shutdown() }
+// Commands still buffered are flushed (best-effort) before the connection is closed,
+// a flush error is returned together with the close error. Updates queued by coalescing
+// (see WithCoalescing) are dropped, use Shutdown to send them before closing.
+func (c *Client) Close() error {
+	c.unwatchLeak()
+	var flushErr error
+	// an in-flight command holding the lock flushes its commands itself
+	if c.mu.TryLock() {
+		if c.w.Buffered() > 0 {
+			if err := c.flush(); err != nil {
+				flushErr = fmt.Errorf("flush on close - %w", err)
+			}
+		}
+		c.mu.Unlock()
+	}
+	c.coalesce.close()
+	c.accRelease.close()
+	err := c.shutdown()
+	c.gpioWatchers.close()
+	return errors.Join(flushErr, err)
+}
+
+// Shutdown gracefully closes the client: the updates queued by coalescing (see WithCoalescing) are sent,
+// new commands are rejected with ErrClientClosed, the in-flight commands are finished and the client
+// is closed afterwards, so that final values (like the last speed of a loco) reach the command station.
+// If the context is done before the queued updates are sent and the in-flight commands are finished
+// the client is closed nevertheless (dropping the queued updates and failing the in-flight commands)
+// and the context error is returned.
+// In contrast Close closes the connection immediately, dropping queued updates and failing in-flight commands.
+func (c *Client) Shutdown(ctx context.Context) error {
+	ctxErr := c.coalesce.drain(ctx)
+	if ctxErr == nil {
+		select {
+		case <-c.gate.close():
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+		}
+	}
+	return errors.Join(ctxErr, c.Close())
+}
+
+// callGate keeps track of the in-flight calls and rejects new calls after it was closed.
+type callGate struct {
+	mu     sync.Mutex
+	closed bool
+	active int
+	idle   chan struct{} // closed when the gate is closed and no call is active
+}
+
+func (g *callGate) enter() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return ErrClientClosed
+	}
+	g.active++
+	return nil
+}
+
+func (g *callGate) leave() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.active--
+	if g.closed && g.active == 0 {
+		close(g.idle)
+	}
+}
+
+// close closes the gate and returns a channel which is closed when no call is active anymore.
+func (g *callGate) close() <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.closed {
+		g.closed = true
+		g.idle = make(chan struct{})
+		if g.active == 0 {
+			close(g.idle)
+		}
+	}
+	return g.idle
+}
 
 type replyKind int
 
@@ -199,9 +547,10 @@ const (
 	rkEOR
 	rkPush
 	rkError
+	rkEcho
 )
 
-func (c *Client) parseReply(buf []byte) (replyKind, string) {
+func (c *clientState) parseReply(buf []byte) (replyKind, string) {
 	for i, b := range buf {
 		switch b {
 		case tagSuccess:
@@ -214,144 +563,422 @@ func (c *Client) parseReply(buf []byte) (replyKind, string) {
 			return rkEOR, ""
 		case tagPush:
 			return rkPush, string(buf[i+1:])
+		case tagStart:
+			if c.echoSkip {
+				// command echo (firmware echoing commands): the echoed arguments might contain tags
+				// and must not be parsed as reply
+				return rkEcho, string(buf[i+1:])
+			}
 		}
 	}
 	return rkNone, ""
 }
 
-func (c *Client) reader(wg *sync.WaitGroup) (<-chan any, <-chan string) {
+// lineHandler handles the reply lines of a connection.
+type lineHandler struct {
+	c        *clientState
+	replies  *replyQueue
+	push     func(msg string)
+	multi    bool
+	multiMsg []string
+}
+
+// handle parses a reply line and dispatches the reply to the command waiting for it.
+func (h *lineHandler) handle(line []byte) {
+	c := h.c
 
-	replyCh := make(chan any, replyChSize)
-	pushCh := make(chan string, pushChSize)
+	if c.debugEnabled() {
+		c.logger.Debug("read", "line", string(line))
+	}
+
+	rk, msg := c.parseReply(line)
+	switch rk {
+	default: // ignore
+	case rkError:
+		// an error aborts a multi-reply: the lines received so far are discarded
+		// and the error is the reply of the command (no end of reply follows)
+		h.multi, h.multiMsg = false, nil
+		if err, ok := errorMap[msg]; ok {
+			c.dispatchReply(h.replies, err)
+		} else {
+			if c.logger != nil {
+				c.logger.Warn("unknown error reply", "error", msg)
+			}
+			c.dispatchReply(h.replies, ErrUnknown)
+		}
+	case rkSingle:
+		c.dispatchReply(h.replies, msg)
+	case rkPush:
+		h.push(msg)
+	case rkMulti:
+		if p, ok := h.replies.peek(); ok && p.stream != nil {
+			// streaming command: the lines are handed over as they arrive and the
+			// end of reply is dispatched as multi-reply without lines
+			p.stream(msg)
+			return
+		}
+		if !h.multi {
+			h.multiMsg = []string{}
+			h.multi = true
+		}
+		h.multiMsg = append(h.multiMsg, msg)
+	case rkEOR:
+		if !h.multi { // multi-reply without lines
+			h.multiMsg = []string{}
+		}
+		c.dispatchReply(h.replies, h.multiMsg)
+		h.multi, h.multiMsg = false, nil
+	}
+}
+
+// readerDone records the end of the connection and fails the commands waiting for a reply.
+func (c *clientState) readerDone(replies *replyQueue, err error) {
+	if errors.Is(err, bufio.ErrTooLong) {
+		err = fmt.Errorf("read reply line exceeding %d bytes (see WithMaxLineSize) - %w", c.maxLineSize, err)
+		if c.logger != nil {
+			c.logger.Warn("read", "error", err)
+		}
+	}
+	c.lastReadErr = err
+	if c.lastReadErr == nil {
+		c.lastReadErr = io.EOF
+	}
+	c.disconnected = time.Now()
+	c.closeReplies(replies)
+}
+
+func (c *clientState) reader(wg *sync.WaitGroup, replies *replyQueue) <-chan string {
+
+	pushCh := make(chan string, c.pushBufSize)
 
 	go func() {
 		defer wg.Done()
 
-		scanner := bufio.NewScanner(c.conn)
-
-		multi := false
-		var multiMsg []string
+		scanner := bufio.NewScanner(countReader{r: c.conn, n: &c.stats.bytesIn})
+		scanner.Buffer(make([]byte, 0, min(c.maxLineSize, initialLineBufSize)), c.maxLineSize)
+		h := &lineHandler{c: c, replies: replies, push: func(msg string) { c.sendPush(pushCh, msg) }}
 
 		for scanner.Scan() {
 			if err := scanner.Err(); err != nil {
-				replyCh <- err
-			}
-
-			//log.Printf("message: %s", scanner.Text())
-
-			rk, msg := c.parseReply(scanner.Bytes())
-			switch rk {
-			default: // ignore
-			case rkError:
-				if err, ok := errorMap[msg]; ok {
-					replyCh <- err
-				} else {
-					replyCh <- ErrUnknown
-				}
-			case rkSingle:
-				replyCh <- msg
-			case rkPush:
-				pushCh <- msg
-			case rkMulti:
-				if !multi {
-					multiMsg = []string{}
-					multi = true
-				}
-				multiMsg = append(multiMsg, msg)
-			case rkEOR:
-				replyCh <- multiMsg
-				multi = false
+				c.dispatchReply(replies, err)
 			}
+			h.handle(scanner.Bytes())
 		}
 
-		close(replyCh)
+		c.readerDone(replies, scanner.Err())
 		close(pushCh)
 	}()
 
 	wg.Add(1)
-	return replyCh, pushCh
+	return pushCh
 }
 
-func (c *Client) pusher(wg *sync.WaitGroup, pushCh <-chan string, handler func(Msg, error)) {
+func (c *clientState) pusher(wg *sync.WaitGroup, pushCh <-chan string, handler func(Msg, error)) {
 	go func() {
 		defer wg.Done()
 
 		for s := range pushCh {
+			msg, err := parseMsg(s)
+			if err != nil {
+				c.stats.incPush(MkUnknown)
+				if c.logger != nil {
+					c.logger.Warn("parse push message", "line", s, "error", err)
+				}
+			} else {
+				c.stats.incPush(msg.Kind())
+				if msg, ok := msg.(*IOIEMsg); ok {
+					c.gpioWatchers.dispatch(msg)
+				}
+			}
 			if handler != nil {
-				handler(parseMsg(s))
+				handler(msg, err)
 			}
 		}
 	}()
 	wg.Add(1)
 }
 
+// writeDeadliner is implemented by connections supporting write deadlines (like network connections).
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// flush flushes the write buffer. For connections supporting write deadlines the write timeout
+// is applied, so that a stalled peer does not block the client.
+func (c *Client) flush() error {
+	if d, ok := c.conn.(writeDeadliner); ok && c.writeTimeout > 0 {
+		d.SetWriteDeadline(time.Now().Add(c.writeTimeout)) //nolint: errcheck
+		defer d.SetWriteDeadline(time.Time{})              //nolint: errcheck
+	}
+	if err := c.w.Flush(); err != nil {
+		// the writer keeps the error: reset, so that only the command not written fails
+		c.w.Reset(countWriter{w: c.conn, n: &c.stats.bytesOut})
+		return err
+	}
+	return nil
+}
+
 func (c *Client) write(cmd string, args []any) error {
-	c.w.WriteByte(tagStart) //nolint: errcheck
-	c.w.WriteString(cmd)    //nolint: errcheck
+	c.writeCmd(cmd, args)
+	if err := c.flush(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// cmdWriter is implemented by the buffers a command is formatted to.
+type cmdWriter interface {
+	WriteByte(c byte) error
+	WriteString(s string) (int, error)
+}
+
+// formatArg returns the command line representation of a command argument.
+func formatArg(f formatters, arg any) (string, error) {
+	rv := reflect.ValueOf(arg)
+	if !rv.IsValid() {
+		return "", errors.New("invalid argument nil")
+	}
+	if format, ok := f[rv.Type()]; ok {
+		return format(arg), nil
+	}
+	switch arg := arg.(type) {
+	case Toggle:
+		return string(charToggle), nil
+	case rawArg:
+		return string(arg), nil
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return string(formatBool(rv.Bool())), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	default:
+		return "", fmt.Errorf("invalid argument %[1]v type %[1]T", arg)
+	}
+}
+
+// formatCmd formats the command line (without line terminator) to w.
+func formatCmd(w cmdWriter, f formatters, cmd string, args []any) {
+	w.WriteByte(tagStart) //nolint: errcheck
+	w.WriteString(cmd)    //nolint: errcheck
 	for _, arg := range args {
 		// argument separator
-		c.w.WriteByte(' ') //nolint: errcheck
-
-		rv := reflect.ValueOf(arg)
-		switch rv.Kind() {
-		case reflect.Bool:
-			c.w.WriteByte(formatBool(rv.Bool())) //nolint: errcheck
-		case reflect.Uint8, reflect.Uint:
-			c.w.WriteString(strconv.FormatUint(rv.Uint(), 10)) //nolint: errcheck
-		case reflect.String:
-			c.w.WriteString(rv.String()) //nolint: errcheck
-		default:
-			panic(fmt.Sprintf("invalid argument %[1]v type %[1]T", arg)) // should never happen
+		w.WriteByte(' ') //nolint: errcheck
+
+		s, err := formatArg(f, arg)
+		if err != nil {
+			panic(err) // should never happen (see rawArgs)
 		}
+		w.WriteString(s) //nolint: errcheck
+	}
+}
+
+// writeCmd writes the command to the write buffer without flushing.
+func (c *Client) writeCmd(cmd string, args []any) {
+	if c.debugEnabled() {
+		var b strings.Builder
+		formatCmd(&b, c.formatters, cmd, args)
+		c.logger.Debug("write", "line", b.String())
 	}
+	formatCmd(c.w, c.formatters, cmd, args)
 	c.w.WriteByte('\r') //nolint: errcheck
-	if err := c.w.Flush(); err != nil {
+	c.stats.cmds.Add(1)
+}
+
+// debugEnabled returns true if debug logging is enabled, false otherwise.
+func (c *clientState) debugEnabled() bool {
+	return c.logger != nil && c.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// send writes the command and queues it for its reply (see read).
+func (c *Client) send(cmd string, args []any) (*pendingReply, error) {
+	p := newSyncReply(cmd, args)
+	if err := c.sendPending(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// sendPending queues and writes the command p.
+func (c *Client) sendPending(p *pendingReply) error {
+	if !c.replies.push(p) {
+		return c.lastReadErr
+	}
+	if err := c.write(p.cmd, p.args); err != nil {
+		c.replies.remove(p)
 		return err
 	}
 	return nil
 }
 
-func (c *Client) read() (any, error) {
+// read waits for the reply of the command p. A timed out command is abandoned for a grace period of timeout,
+// so that a late reply is discarded and not returned to a subsequent command (see replyQueue).
+func (c *Client) read(p *pendingReply, timeout time.Duration) (any, error) {
+	if c.syncReader != nil {
+		return c.readSync(p, timeout)
+	}
 	select {
-	case reply, ok := <-c.replyCh:
-		if !ok {
-			return nil, c.lastReadErr
-		}
-		if err, ok := reply.(error); ok { // is error reply?
-			return nil, err
-		}
-		return reply, nil
+	case reply, ok := <-p.ch:
+		return c.received(reply, ok)
+	case <-time.After(timeout):
+		return c.timedOut(p, timeout)
+	}
+}
 
-	case <-time.After(timeout * time.Second):
-		return nil, fmt.Errorf("read timeout after %d seconds", timeout)
+// received returns the reply received from the reply channel of a command.
+func (c *Client) received(reply any, ok bool) (any, error) {
+	if !ok {
+		return nil, c.lastReadErr
 	}
+	c.stats.replies.Add(1)
+	c.numTimeouts = 0
+	if err, ok := reply.(error); ok { // is error reply?
+		return nil, err
+	}
+	return reply, nil
+}
+
+// timedOut abandons the command p after a read timeout.
+func (c *Client) timedOut(p *pendingReply, timeout time.Duration) (any, error) {
+	c.replies.abandon(timeout, p)
+	c.stats.timeouts.Add(1)
+	c.numTimeouts++
+	if c.maxTimeouts > 0 && c.numTimeouts >= c.maxTimeouts {
+		// station is not responding anymore: close connection to stop the reader
+		c.conn.Close() //nolint: errcheck
+		return nil, fmt.Errorf("%w: %d consecutive read timeouts", ErrConnDead, c.numTimeouts)
+	}
+	return nil, fmt.Errorf("%w after %s", errReadTimeout, timeout)
+}
+
+// isConnError returns true if the error is caused by the connection, false otherwise.
+func isConnError(err error) bool {
+	return err != nil && !isStationError(err) && !errors.Is(err, errReadTimeout)
+}
+
+// retry re-executes a command failed with error err after an automatic reconnect
+// (see WithAutoReconnect). The client needs to be locked.
+func (c *Client) retry(err error, fn func() error) error {
+	if !c.autoConnect || !isConnError(err) {
+		return err
+	}
+	if rerr := c.restart(c.reconnectBackoff); rerr != nil {
+		return fmt.Errorf("%w (reconnect failed - %w)", err, rerr)
+	}
+	return fn()
+}
+
+// acquire acquires an in-flight command slot.
+func (c *Client) acquire() error {
+	if err := c.gate.enter(); err != nil {
+		return err
+	}
+	if c.inFlight == nil {
+		return nil
+	}
+	if !c.busyErr {
+		c.inFlight <- struct{}{}
+		return nil
+	}
+	select {
+	case c.inFlight <- struct{}{}:
+		return nil
+	default:
+		c.gate.leave()
+		return ErrBusy
+	}
+}
+
+// release releases an in-flight command slot.
+func (c *Client) release() {
+	if c.inFlight != nil {
+		<-c.inFlight
+	}
+	c.gate.leave()
+}
+
+// TryCall calls fn if no command is running and returns ErrBusy otherwise, so that an optional
+// command (like a periodic poll) can be skipped instead of queuing up behind a long running command.
+// Please note that fn might still wait for commands started concurrently after the check.
+func (c *Client) TryCall(fn func() error) error {
+	if !c.mu.TryLock() {
+		return ErrBusy
+	}
+	c.mu.Unlock()
+	return fn()
 }
 
 func (c *Client) call(cmd string, args ...any) error {
+	if err := c.acquire(); err != nil {
+		return err
+	}
+	defer c.release()
+
 	// guarantee:
 	// - writing is not 'interleaved' and
 	// - reply order
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	return c.write(cmd, args)
+	fn := func() error { return c.write(cmd, args) }
+	if err := c.retry(fn(), fn); err != nil {
+		return &CallError{Cmd: cmd, Args: args, Err: err}
+	}
+	return nil
 }
 
 func (c *Client) callReply(cmd string, args ...any) (any, error) {
+	return c.callReplyTimeout(c.timeout, cmd, args...)
+}
+
+func (c *Client) callReplyTimeout(timeout time.Duration, cmd string, args ...any) (any, error) {
+	res, _, err := c.roundTrip(timeout, cmd, args...)
+	return res, err
+}
+
+// roundTrip sends the command and returns the reply together with the round trip time of the
+// successful attempt. The time waiting for the client lock is not part of the round trip time.
+func (c *Client) roundTrip(timeout time.Duration, cmd string, args ...any) (any, time.Duration, error) {
+	if err := c.acquire(); err != nil {
+		return nil, 0, err
+	}
+	defer c.release()
+
 	// guarantee:
 	// - writing is not 'interleaved' and
 	// - reply order
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if err := c.write(cmd, args); err != nil {
-		return nil, err
+	var res any
+	var rtt time.Duration
+	fn := func() (err error) {
+		start := time.Now()
+		p, err := c.send(cmd, args)
+		if err != nil {
+			return err
+		}
+		if res, err = c.read(p, timeout); err != nil {
+			return err
+		}
+		rtt = time.Since(start)
+		c.stats.observeLatency(rtt)
+		return nil
+	}
+	if err := c.retryRead(c.retry(fn(), fn), cmd, args, fn); err != nil {
+		return nil, 0, &CallError{Cmd: cmd, Args: args, Err: err}
 	}
-	return c.read()
+	return res, rtt, nil
 }
 
 func (c *Client) singleReply(cmd string, args ...any) (string, error) {
-	res, err := c.callReply(cmd, args...)
+	return c.singleReplyTimeout(c.timeout, cmd, args...)
+}
+
+func (c *Client) singleReplyTimeout(timeout time.Duration, cmd string, args ...any) (string, error) {
+	res, err := c.callReplyTimeout(timeout, cmd, args...)
 	if err != nil {
 		return "", err
 	}
@@ -362,6 +989,14 @@ func (c *Client) singleReply(cmd string, args ...any) (string, error) {
 	return string(v), nil
 }
 
+func (c *Client) singleBoolReply(cmd string, args ...any) (bool, error) {
+	v, err := c.singleReply(cmd, args...)
+	if err != nil {
+		return false, err
+	}
+	return parseBool(v)
+}
+
 func (c *Client) multiReply(cmd string, args ...any) ([]string, error) {
 	res, err := c.callReply(cmd, args...)
 	if err != nil {
@@ -383,6 +1018,52 @@ func (c *Client) Help() ([]string, error) {
 	return v, nil
 }
 
+// parseCommands returns the command infos of the help lines keyed by command name (see parseCommandInfos).
+func parseCommands(lines []string) map[string]CommandInfo {
+	infos := parseCommandInfos(lines)
+	commands := make(map[string]CommandInfo, len(infos))
+	for _, info := range infos {
+		commands[info.Name] = info
+	}
+	return commands
+}
+
+// HasCommand returns true if the command station supports the command name (like "ff"), false otherwise.
+// The command list is queried via Help on first call and cached until the client is reconnected.
+func (c *Client) HasCommand(name string) (bool, error) {
+	_, ok, err := c.commandInfo(name)
+	return ok, err
+}
+
+// commandInfo returns the info of the command name. ok is false if the command station does not
+// support the command. The command infos are cached like in HasCommand.
+func (c *Client) commandInfo(name string) (info CommandInfo, ok bool, err error) {
+	c.cmdMu.Lock()
+	commands := c.commands
+	c.cmdMu.Unlock()
+
+	if commands == nil {
+		// query without holding cmdMu, as a restart holding the client lock resets the commands
+		lines, err := c.Help()
+		if err != nil {
+			return CommandInfo{}, false, err
+		}
+		commands = parseCommands(lines)
+		c.cmdMu.Lock()
+		c.commands = commands
+		c.cmdMu.Unlock()
+	}
+	info, ok = commands[name]
+	return info, ok, nil
+}
+
+func (c *Client) resetCommands() {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+	c.commands = nil
+	c.gpios = nil
+}
+
 // Board returns board information like controller type and unique id.
 func (c *Client) Board() (*Board, error) {
 	v, err := c.singleReply(cmdBoard)
@@ -392,16 +1073,22 @@ func (c *Client) Board() (*Board, error) {
 	return parseBoard(v)
 }
 
-// Store stores the command station CVs on flash.
-func (c *Client) Store() (bool, error) {
-	v, err := c.singleReply(cmdStore)
+// Latency returns the round trip time of a board command. The time waiting for other
+// calls to complete is not included. See Stats for a rolling latency estimate of all calls.
+func (c *Client) Latency() (time.Duration, error) {
+	_, rtt, err := c.roundTrip(c.timeout, cmdBoard)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
-	return strconv.ParseBool(v)
+	return rtt, nil
+}
+
+// Store stores the command station CVs on flash.
+func (c *Client) Store() (bool, error) {
+	return c.singleBoolReply(cmdStore)
 }
 
-// Temp returns the temperature of the command station.
+// Temp returns the temperature of the command station in degree Celsius (see Temperature).
 func (c *Client) Temp() (float64, error) {
 	v, err := c.singleReply(cmdTemp)
 	if err != nil {
@@ -410,6 +1097,26 @@ func (c *Client) Temp() (float64, error) {
 	return strconv.ParseFloat(v, 64)
 }
 
+// Temperature represents a temperature in degree Celsius.
+type Temperature float64
+
+// Celsius returns the temperature in degree Celsius.
+func (t Temperature) Celsius() float64 { return float64(t) }
+
+// Fahrenheit returns the temperature in degree Fahrenheit.
+func (t Temperature) Fahrenheit() float64 { return float64(t)*9/5 + 32 }
+
+func (t Temperature) String() string { return strconv.FormatFloat(float64(t), 'f', -1, 64) + "°C" }
+
+// Temperature returns the temperature of the command station.
+func (c *Client) Temperature() (Temperature, error) {
+	v, err := c.Temp()
+	if err != nil {
+		return 0, err
+	}
+	return Temperature(v), nil
+}
+
 // CV returns the value of a command station CV.
 func (c *Client) CV(idx CVIdx) (byte, error) {
 	v, err := c.singleReply(cmdCV, idx)
@@ -428,53 +1135,200 @@ func (c *Client) SetCV(idx CVIdx, val byte) (byte, error) {
 	return parseByte(v)
 }
 
+// SetCVChanged sets the value of a command station CV and reports if the value was changed.
+// In contrast to SetCV a command station 'no change' reply (ErrNoChange) is not reported as error,
+// but as unchanged value val.
+func (c *Client) SetCVChanged(idx CVIdx, val byte) (byte, bool, error) {
+	v, err := c.SetCV(idx, val)
+	switch {
+	case errors.Is(err, ErrNoChange):
+		return val, false, nil
+	case err != nil:
+		return 0, false, err
+	}
+	return v, true, nil
+}
+
 // MTE returns true if the main track DCC sigal generation is enabled, false otherwise.
 func (c *Client) MTE() (bool, error) {
-	v, err := c.singleReply(cmdMTE)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.singleBoolReply(cmdMTE)
 }
 
 // SetMTE sets main track DCC sigal generation whether to enabled or disabled.
 func (c *Client) SetMTE(enabled bool) (bool, error) {
-	v, err := c.singleReply(cmdMTE, enabled)
-	if err != nil {
+	return c.singleBoolReply(cmdMTE, enabled)
+}
+
+// ShortLatched returns true if the command station latched track power off after a short circuit, false otherwise.
+// ShortLatched requires the command station to provide the short circuit latch command "short"
+// (see HasCommand), otherwise an error wrapping ErrNotImpl is returned.
+func (c *Client) ShortLatched() (bool, error) {
+	if err := c.requireCommand(cmdShort); err != nil {
 		return false, err
 	}
-	return strconv.ParseBool(v)
+	return c.singleBoolReply(cmdShort)
+}
+
+// ClearShort resets a latched short circuit, so that the track power can be re-enabled via SetMTE.
+// Like ShortLatched, ClearShort returns an error wrapping ErrNotImpl if the command station does not
+// provide the short circuit latch command.
+func (c *Client) ClearShort() error {
+	if err := c.requireCommand(cmdShort); err != nil {
+		return err
+	}
+	latched, err := c.singleBoolReply(cmdShort, false)
+	if err != nil {
+		return err
+	}
+	if latched {
+		return errors.New("short circuit latch could not be cleared")
+	}
+	return nil
+}
+
+// requireCommand returns an error wrapping ErrNotImpl if the command station does not support
+// the command name (see HasCommand). Commands not supported by every firmware version are gated,
+// so that they are only sent to command stations advertising them.
+func (c *Client) requireCommand(name string) error {
+	ok, err := c.HasCommand(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &CallError{Cmd: name, Err: ErrNotImpl}
+	}
+	return nil
+}
+
+// ClientCount returns the number of clients currently connected to the command station
+// (like multiple TCP clients connected to a Pico W).
+// ClientCount requires the command station to provide the client count command "cc"
+// (see HasCommand), otherwise an error wrapping ErrNotImpl is returned.
+func (c *Client) ClientCount() (uint, error) {
+	if err := c.requireCommand(cmdClientCount); err != nil {
+		return 0, err
+	}
+	v, err := c.singleReply(cmdClientCount)
+	if err != nil {
+		return 0, err
+	}
+	return parseUint(v)
+}
+
+// SpeedSteps represents a DCC speed step mode.
+type SpeedSteps uint
+
+// Speed step modes.
+const (
+	SpeedSteps14  SpeedSteps = 14
+	SpeedSteps28  SpeedSteps = 28
+	SpeedSteps128 SpeedSteps = 128
+)
+
+func (s SpeedSteps) valid() bool {
+	return s == SpeedSteps14 || s == SpeedSteps28 || s == SpeedSteps128
+}
+
+func (c *Client) speedStepsReply(args ...any) (SpeedSteps, error) {
+	if err := c.requireCommand(cmdDefaultSpeedSteps); err != nil {
+		return 0, err
+	}
+	v, err := c.singleReply(cmdDefaultSpeedSteps, args...)
+	if err != nil {
+		return 0, err
+	}
+	u, err := parseUint(v)
+	if err != nil {
+		return 0, err
+	}
+	steps := SpeedSteps(u)
+	if !steps.valid() {
+		return 0, fmt.Errorf("invalid speed steps %d", steps)
+	}
+	return steps, nil
+}
+
+// DefaultSpeedSteps returns the speed step mode used by the command station for new locos.
+// DefaultSpeedSteps requires the command station to provide the default speed step command "dss"
+// (see HasCommand), otherwise an error wrapping ErrNotImpl is returned.
+func (c *Client) DefaultSpeedSteps() (SpeedSteps, error) {
+	return c.speedStepsReply()
+}
+
+// SetDefaultSpeedSteps sets the speed step mode used by the command station for new locos.
+// Like DefaultSpeedSteps, SetDefaultSpeedSteps returns an error wrapping ErrNotImpl if the command
+// station does not provide the default speed step command.
+func (c *Client) SetDefaultSpeedSteps(steps SpeedSteps) (SpeedSteps, error) {
+	if !steps.valid() {
+		return 0, fmt.Errorf("invalid speed steps %d - expected 14, 28 or 128", steps)
+	}
+	return c.speedStepsReply(uint(steps))
+}
+
+// requireReadForm returns an error wrapping ErrNotImpl if the command station does not support
+// the read form of the command name with n arguments: the command needs to be advertised with
+// the arguments following the first n arguments being optional (like "as <addr> [<status>]").
+// Commands whose read form is not provided by every firmware version are gated this way, so that
+// the read form is only sent to command stations advertising it.
+func (c *Client) requireReadForm(name string, n int) error {
+	info, ok, err := c.commandInfo(name)
+	if err != nil {
+		return err
+	}
+	if !ok || len(info.Args) < n || (len(info.Args) > n && !info.Args[n].Optional) {
+		return &CallError{Cmd: name, Err: ErrNotImpl}
+	}
+	return nil
 }
 
 // LocoDir returns the direction of a loco.
 // true : forward direction
 // false: backward direction
 func (c *Client) LocoDir(addr uint) (bool, error) {
-	v, err := c.singleReply(cmdLocoDir, addr)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.singleBoolReply(cmdLocoDir, addr)
 }
 
 // SetLocoDir sets the direction of a loco.
 // true : forward direction
 // false: backward direction
 func (c *Client) SetLocoDir(addr uint, dir bool) (bool, error) {
-	v, err := c.singleReply(cmdLocoDir, addr, dir)
+	v, err := c.singleBoolReply(cmdLocoDir, addr, dir)
 	if err != nil {
 		return false, err
 	}
-	return strconv.ParseBool(v)
+	c.cache.setDir(addr, v)
+	c.state.setDir(addr, v)
+	return v, nil
 }
 
 // ToggleLocoDir toggles the direction of a loco.
 func (c *Client) ToggleLocoDir(addr uint) (bool, error) {
-	v, err := c.singleReply(cmdLocoDir, addr, charToggle)
+	v, err := c.singleBoolReply(cmdLocoDir, addr, Toggle{})
 	if err != nil {
 		return false, err
 	}
-	return strconv.ParseBool(v)
+	c.cache.setDir(addr, v)
+	c.state.setDir(addr, v)
+	return v, nil
+}
+
+// MaxLocoFct is the highest loco function number.
+const MaxLocoFct = rbuf.MaxFct
+
+// validateSpeed128 returns an error if speed is not a valid 128 speed step value.
+func validateSpeed128(speed uint) error {
+	if speed > MaxSpeed128 {
+		return fmt.Errorf("invalid speed %d - expected 0-%d", speed, MaxSpeed128)
+	}
+	return nil
+}
+
+// validateFct returns an error if no is not a valid loco function number.
+func validateFct(no uint) error {
+	if no > MaxLocoFct {
+		return fmt.Errorf("invalid function number %d - expected 0-%d", no, MaxLocoFct)
+	}
+	return nil
 }
 
 // LocoSpeed128 returns the speed of a loco.
@@ -494,38 +1348,189 @@ func (c *Client) LocoSpeed128(addr uint) (uint, error) {
 // 1    : emergency stop
 // 2-127: 126 speed steps
 func (c *Client) SetLocoSpeed128(addr, speed uint) (uint, error) {
+	if err := validateSpeed128(speed); err != nil {
+		return 0, err
+	}
 	v, err := c.singleReply(cmdLocoSpeed128, addr, speed)
 	if err != nil {
 		return 0, err
 	}
-	return parseUint(v)
+	speed, err = parseUint(v)
+	if err != nil {
+		return 0, err
+	}
+	c.cache.setSpeed(addr, speed)
+	c.state.setSpeed(addr, speed)
+	return speed, nil
+}
+
+// Speed step values shared by all speed step modes.
+const (
+	SpeedStop  = 0 // stop
+	SpeedEStop = 1 // emergency stop
+)
+
+// Maximal speed values of the speed step modes.
+const (
+	MaxSpeed14  = 15  // 14 speed steps
+	MaxSpeed28  = 29  // 28 speed steps
+	MaxSpeed128 = 127 // 126 speed steps
+)
+
+// convertSpeed converts a speed value between speed step modes with from and to number of speed values.
+// Stop and emergency stop are kept, the speed steps are scaled and rounded.
+// A moving speed is never converted to stop.
+func convertSpeed(speed, from, to uint) uint {
+	if speed <= SpeedEStop {
+		return speed
+	}
+	step := min(speed-1, from-1) // 1 .. number of steps
+	numFrom, numTo := from-1, to-1
+	step = (step*numTo + numFrom/2) / numFrom
+	return max(step, 1) + 1
+}
+
+// SpeedFrom14 converts a 14 speed step value to a 128 speed step value.
+// 0    : stop
+// 1    : emergency stop
+// 2-15 : 14 speed steps
+func SpeedFrom14(step uint) uint { return convertSpeed(step, MaxSpeed14, MaxSpeed128) }
+
+// SpeedTo14 converts a 128 speed step value to a 14 speed step value.
+func SpeedTo14(speed128 uint) uint { return convertSpeed(speed128, MaxSpeed128, MaxSpeed14) }
+
+// SpeedFrom28 converts a 28 speed step value to a 128 speed step value.
+// 0    : stop
+// 1    : emergency stop
+// 2-29 : 28 speed steps
+func SpeedFrom28(step uint) uint { return convertSpeed(step, MaxSpeed28, MaxSpeed128) }
+
+// SpeedTo28 converts a 128 speed step value to a 28 speed step value.
+func SpeedTo28(speed128 uint) uint { return convertSpeed(speed128, MaxSpeed128, MaxSpeed28) }
+
+// EmergencyStopAll sends an emergency stop to all locos in the refresh buffer.
+// In contrast to disabling the main track DCC signal generation via SetMTE, which
+// stops all locos at once by switching off the track signal, the locos stay addressable
+// and keep their functions (like lights) switched on.
+// Errors of single locos do not stop the processing of the remaining locos but are returned combined.
+func (c *Client) EmergencyStopAll() error {
+	buf, err := c.RefreshBuffer()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	addrs := map[uint]bool{}
+	for _, entry := range buf.Entries {
+		addr := entry.Addr()
+		if addrs[addr] {
+			continue
+		}
+		addrs[addr] = true
+		if _, err := c.SetLocoSpeed128(addr, SpeedEStop); err != nil {
+			errs = append(errs, fmt.Errorf("emergency stop loco %d: %w", addr, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // LocoFct returns a function value of a loco.
 func (c *Client) LocoFct(addr, no uint) (bool, error) {
-	v, err := c.singleReply(cmdLocoFct, addr, no)
-	if err != nil {
+	if err := validateFct(no); err != nil {
 		return false, err
 	}
-	return strconv.ParseBool(v)
+	return c.singleBoolReply(cmdLocoFct, addr, no)
 }
 
 // SetLocoFct sets a function value of a loco.
 func (c *Client) SetLocoFct(addr, no uint, fct bool) (bool, error) {
-	v, err := c.singleReply(cmdLocoFct, addr, no, fct)
+	if err := validateFct(no); err != nil {
+		return false, err
+	}
+	v, err := c.singleBoolReply(cmdLocoFct, addr, no, fct)
 	if err != nil {
 		return false, err
 	}
-	return strconv.ParseBool(v)
+	c.cache.setFct(addr, no, v)
+	c.state.setFct(addr, no, v)
+	return v, nil
 }
 
 // ToggleLocoFct toggles a function value of a loco.
 func (c *Client) ToggleLocoFct(addr, no uint) (bool, error) {
-	v, err := c.singleReply(cmdLocoFct, addr, no, charToggle)
+	if err := validateFct(no); err != nil {
+		return false, err
+	}
+	v, err := c.singleBoolReply(cmdLocoFct, addr, no, Toggle{})
 	if err != nil {
 		return false, err
 	}
-	return strconv.ParseBool(v)
+	c.cache.setFct(addr, no, v)
+	c.state.setFct(addr, no, v)
+	return v, nil
+}
+
+// maxFctGroup is the highest function number of a function group (see SetLocoFctGroup).
+const maxFctGroup = 31
+
+// SetLocoFctGroup sets the functions 0-31 of a loco selected by mask (bit n: function n) to the
+// corresponding bit values of values. As the command station does not support a function group command
+// the functions are set via a single command batch (see Batch).
+// Errors of single functions do not stop the processing of the remaining functions but are returned combined.
+func (c *Client) SetLocoFctGroup(addr uint, mask, values uint32) error {
+	b := c.Batch()
+	var nos []uint
+	for no := uint(0); no <= maxFctGroup; no++ {
+		if mask&(1<<no) != 0 {
+			b.SetLocoFct(addr, no, values&(1<<no) != 0)
+			nos = append(nos, no)
+		}
+	}
+	if len(nos) == 0 {
+		return nil
+	}
+	results, err := b.Run()
+	if err != nil && len(results) != len(nos) { // connection error or timeout
+		return err
+	}
+	var errs []error
+	for i, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("set loco %d fct %d: %w", addr, nos[i], result.Err))
+			continue
+		}
+		v, err := parseBool(result.Reply)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("set loco %d fct %d: %w", addr, nos[i], err))
+			continue
+		}
+		c.cache.setFct(addr, nos[i], v)
+		c.state.setFct(addr, nos[i], v)
+	}
+	return errors.Join(errs...)
+}
+
+// LocoFctGroup returns the values of the functions 0-31 of a loco (bit n: function n).
+// The functions are read via a single command batch (see Batch).
+func (c *Client) LocoFctGroup(addr uint) (uint32, error) {
+	b := c.Batch()
+	for no := uint(0); no <= maxFctGroup; no++ {
+		b.add(cmdLocoFct, addr, no)
+	}
+	results, err := b.Run()
+	if err != nil {
+		return 0, err
+	}
+	var values uint32
+	for no, result := range results {
+		v, err := parseBool(result.Reply)
+		if err != nil {
+			return 0, fmt.Errorf("loco %d fct %d: %w", addr, no, err)
+		}
+		if v {
+			values |= 1 << no
+		}
+	}
+	return values, nil
 }
 
 // SetLocoCVByte sets the indexed CV byte value of a loco.
@@ -537,22 +1542,52 @@ func (c *Client) SetLocoCVByte(addr, idx uint, val byte) (byte, error) {
 	return parseByte(v)
 }
 
-// SetLocoCVBit sets the indexed CV bit value of a loco.
-func (c *Client) SetLocoCVBit(addr, idx uint, bit byte, val bool) (bool, error) {
-	v, err := c.singleReply(cmdLocoCVBit, addr, idx, bit, val)
+// SetLocoCVBytes sets the indexed CV byte values of a loco in ascending CV index order
+// and returns the confirmed values.
+// On error the processing is stopped and the values confirmed so far are returned together with
+// an error identifying the failing CV index.
+func (c *Client) SetLocoCVBytes(addr uint, cvs map[uint]byte) (map[uint]byte, error) {
+	idxs := make([]uint, 0, len(cvs))
+	for idx := range cvs {
+		idxs = append(idxs, idx)
+	}
+	slices.Sort(idxs)
+	confirmed := make(map[uint]byte, len(cvs))
+	for _, idx := range idxs {
+		v, err := c.SetLocoCVByte(addr, idx, cvs[idx])
+		if err != nil {
+			return confirmed, fmt.Errorf("set loco %d cv %d: %w", addr, idx, err)
+		}
+		confirmed[idx] = v
+	}
+	return confirmed, nil
+}
+
+// ReadLocoCVByte reads the indexed CV byte value of a loco on the programming track (service mode).
+// The loco needs to be placed on an isolated programming track, as all decoders connected to the
+// programming track would respond. As the command station needs to detect the decoder acknowledgement
+// the read may take several seconds. ErrNoData is returned if no decoder acknowledged the read.
+// ReadLocoCVByte requires the command station to provide the programming track command "pcvbyte"
+// (see HasCommand), otherwise an error wrapping ErrNotImpl is returned.
+func (c *Client) ReadLocoCVByte(idx uint) (byte, error) {
+	if err := c.requireCommand(cmdProgCVByte); err != nil {
+		return 0, err
+	}
+	v, err := c.singleReplyTimeout(progTimeout, cmdProgCVByte, idx)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
-	return strconv.ParseBool(v)
+	return parseByte(v)
+}
+
+// SetLocoCVBit sets the indexed CV bit value of a loco.
+func (c *Client) SetLocoCVBit(addr, idx uint, bit byte, val bool) (bool, error) {
+	return c.singleBoolReply(cmdLocoCVBit, addr, idx, bit, val)
 }
 
 // SetLocoCV29Bit5 sets the CV 29 bit 5 value of a loco.
 func (c *Client) SetLocoCV29Bit5(addr uint, bit bool) (bool, error) {
-	v, err := c.singleReply(cmdLocoCV29Bit5, addr, bit)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.singleBoolReply(cmdLocoCV29Bit5, addr, bit)
 }
 
 // SetLocoLaddr sets the long address of a loco.
@@ -582,143 +1617,139 @@ func (c *Client) IOADC(input uint) (float64, error) {
 	return strconv.ParseFloat(v, 64)
 }
 
+// AccFct returns the function value of an accessory decoder on output out.
+// ErrNoData is returned if the output state is not known by the command station
+// (like no function value was set since the command station start).
+// AccFct requires the command station to provide the read form of the accessory function command "af"
+// (see Commands), otherwise an error wrapping ErrNotImpl is returned.
+func (c *Client) AccFct(addr uint, out byte) (bool, error) {
+	if err := c.requireReadForm(cmdAccFct, 2); err != nil {
+		return false, err
+	}
+	return c.singleBoolReply(cmdAccFct, addr, out)
+}
+
 // SetAccFct sets the function value of an accessory decoder on output out.
+// If the auto-release is enabled (see WithAccAutoRelease) an activated output is deactivated
+// after the maximum on-time.
 func (c *Client) SetAccFct(addr uint, out byte, fct bool) (bool, error) {
-	v, err := c.singleReply(cmdAccFct, addr, out, fct)
+	fct, err := c.singleBoolReply(cmdAccFct, addr, out, fct)
 	if err != nil {
 		return false, err
 	}
-	return strconv.ParseBool(v)
+	c.trackAccFct(addr, out, fct)
+	return fct, nil
 }
 
 // SetAccTime sets the activation time of an accessory decoder on output out.
 func (c *Client) SetAccTime(addr uint, out, time byte) (bool, error) {
-	v, err := c.singleReply(cmdAccTime, addr, out, time)
+	return c.singleBoolReply(cmdAccTime, addr, out, time)
+}
+
+// AccStatus returns the status byte of an extended accessory decoder.
+// ErrNoData is returned if the status is not known by the command station
+// (like no status was set since the command station start).
+// AccStatus requires the command station to provide the read form of the accessory status command "as"
+// (see Commands), otherwise an error wrapping ErrNotImpl is returned.
+func (c *Client) AccStatus(addr uint) (byte, error) {
+	if err := c.requireReadForm(cmdAccStatus, 1); err != nil {
+		return 0, err
+	}
+	v, err := c.singleReply(cmdAccStatus, addr)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
-	return strconv.ParseBool(v)
+	return parseByte(v)
 }
 
 // SetAccStatus sets the status byte of an extended accessory decoder.
 func (c *Client) SetAccStatus(addr uint, status byte) (bool, error) {
-	v, err := c.singleReply(cmdAccStatus, addr, status)
+	return c.singleBoolReply(cmdAccStatus, addr, status)
+}
+
+// IO command values selecting the GPIOs addressed by the IO methods (parameter cmd).
+// The current firmware supports IOCmdLocal only. Other values are sent unchanged (like for IO targets
+// of future firmware versions), a command station not supporting the value replies ErrInvPrm.
+const (
+	IOCmdLocal = 0 // GPIOs of the command station board (see BoardType.GPIOs)
+)
+
+// ioBoolReply sends the io command and records the value of a set or toggle command in the state cache.
+func (c *Client) ioBoolReply(name string, cmd uint, args ...any) (bool, error) {
+	v, err := c.singleBoolReply(name, append([]any{cmd}, args...)...)
 	if err != nil {
 		return false, err
 	}
-	return strconv.ParseBool(v)
+	if len(args) == 2 { // set or toggle
+		if gpio, ok := args[0].(uint); ok {
+			c.state.setIO(name, cmd, gpio, v)
+		}
+	}
+	return v, nil
 }
 
 // IOVal returns the boolean value of the GPIO.
 func (c *Client) IOVal(cmd, gpio uint) (bool, error) {
-	v, err := c.singleReply(cmdIOVal, cmd, gpio)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIOVal, cmd, gpio)
 }
 
 // SetIOVal sets the boolean value of the GPIO.
 func (c *Client) SetIOVal(cmd, gpio uint, value bool) (bool, error) {
-	v, err := c.singleReply(cmdIOVal, cmd, gpio, value)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIOVal, cmd, gpio, value)
 }
 
 // ToggleIOVal toggles the value of the GPIO.
 func (c *Client) ToggleIOVal(cmd, gpio uint) (bool, error) {
-	v, err := c.singleReply(cmdIOVal, cmd, gpio, charToggle)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIOVal, cmd, gpio, Toggle{})
 }
 
 // IODir returns the direction of the GPIO.
 // false: in
 // true:  out
 func (c *Client) IODir(cmd, gpio uint) (bool, error) {
-	v, err := c.singleReply(cmdIODir, cmd, gpio)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIODir, cmd, gpio)
 }
 
 // SetIODir sets the direction of the GPIO.
 // false: in
 // true:  out
 func (c *Client) SetIODir(cmd, gpio uint, value bool) (bool, error) {
-	v, err := c.singleReply(cmdIODir, cmd, gpio, value)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIODir, cmd, gpio, value)
 }
 
 // ToggleIODir toggles the direction of the GPIO.
 func (c *Client) ToggleIODir(cmd, gpio uint) (bool, error) {
-	v, err := c.singleReply(cmdIODir, cmd, gpio, charToggle)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIODir, cmd, gpio, Toggle{})
 }
 
 // IOUp returns the pull-up status of the GPIO.
 func (c *Client) IOUp(cmd, gpio uint) (bool, error) {
-	v, err := c.singleReply(cmdIOUp, cmd, gpio)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIOUp, cmd, gpio)
 }
 
 // SetIOUp sets the pull-up status of the GPIO.
 func (c *Client) SetIOUp(cmd, gpio uint, value bool) (bool, error) {
-	v, err := c.singleReply(cmdIOUp, cmd, gpio, value)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIOUp, cmd, gpio, value)
 }
 
 // ToggleIOUp toggles the pull-up status of the GPIO.
 func (c *Client) ToggleIOUp(cmd, gpio uint) (bool, error) {
-	v, err := c.singleReply(cmdIOUp, cmd, gpio, charToggle)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIOUp, cmd, gpio, Toggle{})
 }
 
 // IODown returns the pull-down status of the GPIO.
 func (c *Client) IODown(cmd, gpio uint) (bool, error) {
-	v, err := c.singleReply(cmdIODown, cmd, gpio)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIODown, cmd, gpio)
 }
 
 // SetIODown sets the pull-down status of the GPIO.
 func (c *Client) SetIODown(cmd, gpio uint, value bool) (bool, error) {
-	v, err := c.singleReply(cmdIODown, cmd, gpio, value)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIODown, cmd, gpio, value)
 }
 
 // ToggleIODown toggles the pull-down status of the GPIO.
 func (c *Client) ToggleIODown(cmd, gpio uint) (bool, error) {
-	v, err := c.singleReply(cmdIODown, cmd, gpio, charToggle)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.ioBoolReply(cmdIODown, cmd, gpio, Toggle{})
 }
 
 // RefreshBuffer returns the command station refresh buffer (debugging).
@@ -732,19 +1763,27 @@ func (c *Client) RefreshBuffer() (*rbuf.Buffer, error) {
 
 // RefreshBufferReset resets the refresh buffer (debugging).
 func (c *Client) RefreshBufferReset() (bool, error) {
-	v, err := c.singleReply(cmdRefreshBufferReset)
+	c.evict.beginReset()
+	v, err := c.singleBoolReply(cmdRefreshBufferReset)
+	c.evict.endReset(err == nil)
 	if err != nil {
 		return false, err
 	}
-	return strconv.ParseBool(v)
+	c.cache.reset()
+	c.state.resetLocos()
+	return v, nil
 }
 
 // RefreshBufferDelete deletes address addr from refresh buffer (debugging).
 func (c *Client) RefreshBufferDelete(addr uint) (uint, error) {
+	c.evict.beginDelete(addr)
 	v, err := c.singleReply(cmdRefreshBufferDelete, addr)
+	c.evict.endDelete(addr, err == nil)
 	if err != nil {
 		return 0, err
 	}
+	c.cache.deleteLoco(addr)
+	c.state.deleteLoco(addr)
 	return parseUint(v)
 }
 
@@ -759,11 +1798,7 @@ func (c *Client) Flash() (*flash.Flash, error) {
 
 // FlashFormat formats the command station flash (debugging).
 func (c *Client) FlashFormat() (bool, error) {
-	v, err := c.singleReply(cmdFlashFormat)
-	if err != nil {
-		return false, err
-	}
-	return strconv.ParseBool(v)
+	return c.singleBoolReply(cmdFlashFormat)
 }
 
 var rebootWait = 5 * time.Second
@@ -228,11 +228,18 @@ func testTCPClient(t *testing.T) {
 	testRun(conn, t)
 }
 
+func testMock(t *testing.T) {
+	conn := client.NewMockConn()
+	conn.HandleFunc(mockStation())
+	testRun(conn, t)
+}
+
 func TestClient(t *testing.T) {
 	tests := []struct {
 		name string
 		fct  func(t *testing.T)
 	}{
+		{"Mock", testMock},
 		{"Serial", testSerial},
 		{"TCPClient", testTCPClient},
 	}
@@ -0,0 +1,50 @@
+package client_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestClientCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		help  string
+		reply string
+		count uint
+		err   error
+	}{
+		{"Single", "-cc: client count", "=1", 1, nil},
+		{"Multiple", "-cc: client count", "=3", 3, nil},
+		{"NotImpl", "-h: help", "", 0, client.ErrNotImpl},
+		{"Invalid", "-cc: client count", "=x", 0, errors.New("invalid")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestClient(t, func(cmd string, args []string) []string {
+				switch cmd {
+				case "h":
+					return []string{test.help, "."}
+				case "cc":
+					return []string{test.reply}
+				}
+				return []string{"?invcmd"}
+			}, nil)
+
+			count, err := c.ClientCount()
+			switch {
+			case test.err == nil && err != nil:
+				t.Fatal(err)
+			case test.err != nil && err == nil:
+				t.Fatalf("expected error %v", test.err)
+			case errors.Is(test.err, client.ErrNotImpl) && !errors.Is(err, client.ErrNotImpl):
+				t.Fatalf("invalid error %v - expected %v", err, test.err)
+			}
+			if count != test.count {
+				t.Fatalf("invalid client count %d - expected %d", count, test.count)
+			}
+		})
+	}
+}
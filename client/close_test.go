@@ -0,0 +1,43 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestCloseFlush(t *testing.T) {
+	conn := NewMockConn()
+	c := New(conn, nil)
+
+	// queue commands without flush (like a batch)
+	c.mu.Lock()
+	c.writeCmd(cmdLocoSpeed128, []any{uint(3), uint(40)})
+	c.writeCmd(cmdLocoDir, []any{uint(3), true})
+	c.mu.Unlock()
+
+	if written := conn.Written(); len(written) != 0 {
+		t.Fatalf("commands written before close %v", written)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if written, expected := conn.Written(), []string{"ls 3 40", "ld 3 t"}; !slices.Equal(written, expected) {
+		t.Fatalf("invalid written commands %v - expected %v", written, expected)
+	}
+}
+
+func TestCloseFlushError(t *testing.T) {
+	conn := NewMockConn()
+	c := New(conn, nil)
+
+	c.mu.Lock()
+	c.writeCmd(cmdLocoSpeed128, []any{uint(3), uint(40)})
+	c.mu.Unlock()
+
+	conn.Disconnect(io.ErrClosedPipe)
+	if err := c.Close(); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("invalid error %v - expected %v", err, io.ErrClosedPipe)
+	}
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// coalesceKey identifies a loco property (like the speed of a loco).
+type coalesceKey struct {
+	cmd  string
+	addr uint
+	no   uint // function number (loco function command only)
+}
+
+// coalesceQueue is an outbound queue keeping only the latest update of each loco property.
+// The updates are sent in the order of their latest enqueue by a single background goroutine,
+// waiting for each reply before sending the next update.
+type coalesceQueue struct {
+	mu      sync.Mutex
+	keys    []coalesceKey
+	fns     map[coalesceKey]func() error
+	signal  chan struct{}
+	done    chan struct{}
+	busy    bool          // update being sent
+	drained chan struct{} // closed when the queue is drained (nil: not draining)
+}
+
+func newCoalesceQueue() *coalesceQueue {
+	return &coalesceQueue{
+		fns:    map[coalesceKey]func() error{},
+		signal: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// enqueue queues the update fn of the property key superseding a queued update of the same property.
+// ErrClientClosed is returned if the queue is closed or drained.
+func (q *coalesceQueue) enqueue(key coalesceKey, fn func() error) error {
+	q.mu.Lock()
+	select {
+	case <-q.done:
+		q.mu.Unlock()
+		return ErrClientClosed
+	default:
+	}
+	if q.drained != nil {
+		q.mu.Unlock()
+		return ErrClientClosed
+	}
+	if _, ok := q.fns[key]; ok {
+		// move the property to the end to keep the order of the latest updates
+		for i, k := range q.keys {
+			if k == key {
+				q.keys = append(q.keys[:i], q.keys[i+1:]...)
+				break
+			}
+		}
+	}
+	q.keys = append(q.keys, key)
+	q.fns[key] = fn
+	q.mu.Unlock()
+
+	select {
+	case q.signal <- struct{}{}:
+	default: // already signaled
+	}
+	return nil
+}
+
+// dequeue returns the oldest update. ok is false if the queue is empty.
+func (q *coalesceQueue) dequeue() (fn func() error, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.keys) == 0 {
+		return nil, false
+	}
+	key := q.keys[0]
+	q.keys = q.keys[1:]
+	fn = q.fns[key]
+	delete(q.fns, key)
+	q.busy = true
+	return fn, true
+}
+
+// sent marks the update returned by dequeue as sent.
+func (q *coalesceQueue) sent() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.busy = false
+	q.checkDrained()
+}
+
+// checkDrained closes the drained channel if the queue is drained. The queue needs to be locked.
+func (q *coalesceQueue) checkDrained() {
+	if q.drained == nil || q.busy || len(q.keys) != 0 {
+		return
+	}
+	select {
+	case <-q.drained:
+	default:
+		close(q.drained)
+	}
+}
+
+// drain rejects new updates and waits until the queued updates are sent. If the context is done
+// before, the context error is returned. A nil queue is a no-op.
+func (q *coalesceQueue) drain(ctx context.Context) error {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	if q.drained == nil {
+		q.drained = make(chan struct{})
+		q.checkDrained()
+	}
+	drained := q.drained
+	q.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-q.done: // closed: the queued updates are dropped
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run sends the queued updates until the queue is closed.
+func (q *coalesceQueue) run(errFn func(err error)) {
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-q.signal:
+		}
+		for {
+			select {
+			case <-q.done:
+				return
+			default:
+			}
+			fn, ok := q.dequeue()
+			if !ok {
+				break
+			}
+			if err := fn(); err != nil {
+				errFn(err)
+			}
+			q.sent()
+		}
+	}
+}
+
+// close stops the queue. Queued updates are dropped (see drain). A nil queue is a no-op.
+func (q *coalesceQueue) close() {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	select {
+	case <-q.done:
+	default:
+		close(q.done)
+	}
+}
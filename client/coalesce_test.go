@@ -0,0 +1,161 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestCoalescing(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+
+	c := newTestClient(t, recordStation(&mu, &cmds, func(cmd string, args []string) []string {
+		once.Do(func() {
+			// block the first command, so that the following burst is queued
+			close(started)
+			<-release
+		})
+		return []string{"=" + args[len(args)-1]}
+	}), nil, client.WithCoalescing())
+
+	if err := c.SetLocoSpeed128Async(3, 1); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	// burst
+	for speed := uint(2); speed <= 50; speed++ {
+		if err := c.SetLocoSpeed128Async(3, speed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.SetLocoDirAsync(3, false); err != nil {
+		t.Fatal(err)
+	}
+	for speed := uint(51); speed <= 100; speed++ {
+		if err := c.SetLocoSpeed128Async(3, speed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.SetLocoFctAsync(3, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetLocoSpeed128Async(4, 10); err != nil { // other loco
+		t.Fatal(err)
+	}
+	close(release)
+
+	expected := []string{"ls 3 1", "ld 3 f", "ls 3 100", "lf 3 0 t", "ls 4 10"}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(cmds)
+		mu.Unlock()
+		if n >= len(expected) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond) // no further commands expected
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(cmds, expected) {
+		t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+	}
+}
+
+func TestCoalescingClosed(t *testing.T) {
+	c := newTestClient(t, mockStation(), nil, client.WithCoalescing())
+	c.Close()
+
+	if err := c.SetLocoSpeed128Async(3, 1); !errors.Is(err, client.ErrClientClosed) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrClientClosed)
+	}
+	if err := c.SetLocoDirAsync(3, true); !errors.Is(err, client.ErrClientClosed) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrClientClosed)
+	}
+	if err := c.SetLocoFctAsync(3, 0, true); !errors.Is(err, client.ErrClientClosed) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrClientClosed)
+	}
+}
+
+func TestCoalescingShutdown(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+
+	c := newTestClient(t, recordStation(&mu, &cmds, func(cmd string, args []string) []string {
+		once.Do(func() {
+			// block the first command, so that the following updates are queued
+			close(started)
+			<-release
+		})
+		return []string{"=" + args[len(args)-1]}
+	}), nil, client.WithCoalescing())
+
+	if err := c.SetLocoSpeed128Async(3, 1); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	for speed := uint(2); speed <= 10; speed++ {
+		if err := c.SetLocoSpeed128Async(3, speed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.Shutdown(context.Background()) }()
+	select {
+	case err := <-errCh:
+		t.Fatalf("shutdown did not wait for the queued updates - %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetLocoSpeed128Async(3, 20); !errors.Is(err, client.ErrClientClosed) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrClientClosed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{"ls 3 1", "ls 3 10"} // last value sent before closing
+	if !slices.Equal(cmds, expected) {
+		t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+	}
+}
+
+func TestCoalescingShutdownTimeout(t *testing.T) {
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		return nil // no reply
+	}, nil, client.WithCoalescing(), client.WithTimeout(time.Minute))
+
+	for speed := uint(1); speed <= 2; speed++ {
+		if err := c.SetLocoSpeed128Async(3, speed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("invalid error %v - expected %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+
+	"github.com/pico-cs/go-client/client/flash"
+	"github.com/pico-cs/go-client/client/rbuf"
+)
+
+// CommandStation is the interface of the command station commands implemented by Client.
+//
+// Code depending on CommandStation instead of *Client can be tested with a fake command station
+// implementation. The client specific methods (like the connection handling, the cache and the statistics)
+// are not part of the interface.
+type CommandStation interface {
+	// command station
+	Help() ([]string, error)
+	Board() (*Board, error)
+	Store() (bool, error)
+	Temp() (float64, error)
+	Temperature() (Temperature, error)
+	CV(idx CVIdx) (byte, error)
+	SetCV(idx CVIdx, val byte) (byte, error)
+	SetCVChanged(idx CVIdx, val byte) (byte, bool, error)
+	AllCVs() (map[CVIdx]byte, error)
+	SetCVs(cvs map[CVIdx]byte) (map[CVIdx]byte, error)
+	StoreAndVerify() ([]CVIdx, error)
+	ClientCount() (uint, error)
+	DefaultSpeedSteps() (SpeedSteps, error)
+	SetDefaultSpeedSteps(steps SpeedSteps) (SpeedSteps, error)
+
+	// main track
+	MTE() (bool, error)
+	SetMTE(enabled bool) (bool, error)
+	EnableTrack(ctx context.Context) error
+	DisableTrack(ctx context.Context) error
+	TrackStatus(currentInput uint) (*TrackStatus, error)
+	ShortLatched() (bool, error)
+	ClearShort() error
+	EmergencyStopAll() error
+
+	// loco decoders
+	LocoDir(addr uint) (bool, error)
+	SetLocoDir(addr uint, dir bool) (bool, error)
+	ToggleLocoDir(addr uint) (bool, error)
+	LocoSpeed128(addr uint) (uint, error)
+	SetLocoSpeed128(addr, speed uint) (uint, error)
+	LocoFct(addr, no uint) (bool, error)
+	SetLocoFct(addr, no uint, fct bool) (bool, error)
+	ToggleLocoFct(addr, no uint) (bool, error)
+	LocoFctGroup(addr uint) (uint32, error)
+	SetLocoFctGroup(addr uint, mask, values uint32) error
+	SetLocoCVByte(addr, idx uint, val byte) (byte, error)
+	SetLocoCVBytes(addr uint, cvs map[uint]byte) (map[uint]byte, error)
+	SetLocoCVBit(addr, idx uint, bit byte, val bool) (bool, error)
+	SetLocoCV29Bit5(addr uint, bit bool) (bool, error)
+	SetLocoLaddr(addr, laddr uint) (uint, error)
+	LocoCV1718(addr uint) (byte, byte, error)
+	LocoCV1718Addr(addr DecoderAddress) (byte, byte, error)
+	SetLocoLongAddress(currentAddr, newLongAddr uint) error
+	SetLocoConsist(addr uint, cv19 CV19) (CV19, error)
+	ReadLocoCVByte(idx uint) (byte, error)
+	ReadLocoConsist() (CV19, error)
+
+	// accessory decoders
+	AccFct(addr uint, out byte) (bool, error)
+	SetAccFct(addr uint, out byte, fct bool) (bool, error)
+	SetAccTime(addr uint, out, time byte) (bool, error)
+	AccStatus(addr uint) (byte, error)
+	SetAccStatus(addr uint, status byte) (bool, error)
+
+	// GPIOs
+	IOADC(input uint) (float64, error)
+	IOADCScaled(input uint) (float64, error)
+	IOVal(cmd, gpio uint) (bool, error)
+	SetIOVal(cmd, gpio uint, value bool) (bool, error)
+	ToggleIOVal(cmd, gpio uint) (bool, error)
+	IOValMask(cmd uint) (uint32, error)
+	SetIOValMask(cmd uint, mask, values uint32) error
+	IODir(cmd, gpio uint) (bool, error)
+	SetIODir(cmd, gpio uint, value bool) (bool, error)
+	ToggleIODir(cmd, gpio uint) (bool, error)
+	IOUp(cmd, gpio uint) (bool, error)
+	SetIOUp(cmd, gpio uint, value bool) (bool, error)
+	ToggleIOUp(cmd, gpio uint) (bool, error)
+	IODown(cmd, gpio uint) (bool, error)
+	SetIODown(cmd, gpio uint, value bool) (bool, error)
+	ToggleIODown(cmd, gpio uint) (bool, error)
+
+	// debugging
+	RefreshBuffer() (*rbuf.Buffer, error)
+	RefreshBufferReset() (bool, error)
+	RefreshBufferDelete(addr uint) (uint, error)
+	Flash() (*flash.Flash, error)
+	FlashFormat() (bool, error)
+	Reboot() error
+}
+
+var _ CommandStation = (*Client)(nil)
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// Transport names.
+const (
+	TransportSerial    = "serial"
+	TransportTCP       = "tcp"
+	TransportTLS       = "tls"
+	TransportUDP       = "udp"
+	TransportWebSocket = "websocket"
+)
+
+// Config represents a client configuration including the connection transport.
+// It can be serialized (like to JSON) to reproduce a client setup.
+//
+// Options which cannot be serialized (like the handler, the logger or the argument formatters)
+// are not part of the configuration. The TLS transport verifies the server certificate against
+// the system root certificates unless TLSInsecureSkipVerify is set.
+type Config struct {
+	Transport string `json:"transport"` // TransportSerial, TransportTCP, TransportTLS, TransportUDP or TransportWebSocket
+
+	// serial transport (see SerialConfig)
+	PortName          string          `json:"portName,omitempty"`          // serial port name (empty: default port)
+	BaudRate          int             `json:"baudRate,omitempty"`          // serial baud rate (zero: default)
+	DataBits          int             `json:"dataBits,omitempty"`          // serial data bits (zero: default)
+	Parity            serial.Parity   `json:"parity,omitempty"`            // serial parity (zero: default)
+	StopBits          serial.StopBits `json:"stopBits,omitempty"`          // serial stop bits (zero: default)
+	SerialReadTimeout time.Duration   `json:"serialReadTimeout,omitempty"` // serial read timeout (zero: default)
+
+	// tcp, tls and udp transport
+	Host                  string        `json:"host,omitempty"`
+	Port                  string        `json:"port,omitempty"`                  // empty: DefaultTCPPort
+	DialTimeout           time.Duration `json:"dialTimeout,omitempty"`           // tcp dial timeout (zero: DefaultDialTimeout)
+	TLSServerName         string        `json:"tlsServerName,omitempty"`         // tls server name (empty: host)
+	TLSInsecureSkipVerify bool          `json:"tlsInsecureSkipVerify,omitempty"` // tls without server certificate verification
+
+	// websocket transport
+	URL string `json:"url,omitempty"` // websocket url (see NewWebSocketClient)
+
+	// options
+	Timeout        time.Duration `json:"timeout,omitempty"`        // see WithTimeout (zero: default)
+	WriteTimeout   time.Duration `json:"writeTimeout,omitempty"`   // see WithWriteTimeout (zero: default)
+	MaxTimeouts    int           `json:"maxTimeouts,omitempty"`    // see WithMaxTimeouts
+	Cache          bool          `json:"cache,omitempty"`          // see WithCache
+	StateCache     bool          `json:"stateCache,omitempty"`     // see WithStateCache
+	AutoReconnect  bool          `json:"autoReconnect,omitempty"`  // see WithAutoReconnect
+	MaxInFlight    int           `json:"maxInFlight,omitempty"`    // see WithMaxInFlight
+	BusyErr        bool          `json:"busyErr,omitempty"`        // see WithMaxInFlight
+	RetryAttempts  int           `json:"retryAttempts,omitempty"`  // see WithRetry
+	RetryDelay     time.Duration `json:"retryDelay,omitempty"`     // see WithRetry
+	Coalescing     bool          `json:"coalescing,omitempty"`     // see WithCoalescing
+	PushBufferSize int           `json:"pushBufferSize,omitempty"` // see WithPushBuffer (zero: default, negative: unbuffered)
+	PushPolicy     PushPolicy    `json:"pushPolicy,omitempty"`     // see WithPushBuffer
+	LeakWarning    bool          `json:"leakWarning,omitempty"`    // see WithLeakWarning
+	AccAutoRelease time.Duration `json:"accAutoRelease,omitempty"` // see WithAccAutoRelease
+	MaxLineSize    int           `json:"maxLineSize,omitempty"`    // see WithMaxLineSize (zero: default)
+	EchoSkip       bool          `json:"echoSkip,omitempty"`       // see WithEchoSkip
+}
+
+// Options returns the client options of the configuration.
+func (cfg *Config) Options() []Option {
+	var opts []Option
+	if cfg.Timeout != 0 {
+		opts = append(opts, WithTimeout(cfg.Timeout))
+	}
+	if cfg.WriteTimeout != 0 {
+		opts = append(opts, WithWriteTimeout(cfg.WriteTimeout))
+	}
+	if cfg.MaxTimeouts != 0 {
+		opts = append(opts, WithMaxTimeouts(cfg.MaxTimeouts))
+	}
+	if cfg.Cache {
+		opts = append(opts, WithCache())
+	}
+	if cfg.StateCache {
+		opts = append(opts, WithStateCache())
+	}
+	if cfg.AutoReconnect {
+		opts = append(opts, WithAutoReconnect())
+	}
+	if cfg.MaxInFlight != 0 || cfg.BusyErr {
+		opts = append(opts, WithMaxInFlight(cfg.MaxInFlight, cfg.BusyErr))
+	}
+	if cfg.RetryAttempts != 0 || cfg.RetryDelay != 0 {
+		opts = append(opts, WithRetry(cfg.RetryAttempts, cfg.RetryDelay))
+	}
+	if cfg.Coalescing {
+		opts = append(opts, WithCoalescing())
+	}
+	if cfg.PushBufferSize != 0 || cfg.PushPolicy != PushBlock {
+		size := cfg.PushBufferSize
+		if size == 0 {
+			size = defaultPushBufSize
+		}
+		opts = append(opts, WithPushBuffer(size, cfg.PushPolicy))
+	}
+	if cfg.LeakWarning {
+		opts = append(opts, WithLeakWarning())
+	}
+	if cfg.AccAutoRelease != 0 {
+		opts = append(opts, WithAccAutoRelease(cfg.AccAutoRelease))
+	}
+	if cfg.MaxLineSize != 0 {
+		opts = append(opts, WithMaxLineSize(cfg.MaxLineSize))
+	}
+	if cfg.EchoSkip {
+		opts = append(opts, WithEchoSkip())
+	}
+	return opts
+}
+
+// tlsConfig returns the TLS configuration of the TLS transport (nil: default configuration).
+func (cfg *Config) tlsConfig() *tls.Config {
+	if cfg.TLSServerName == "" && !cfg.TLSInsecureSkipVerify {
+		return nil
+	}
+	return &tls.Config{ServerName: cfg.TLSServerName, InsecureSkipVerify: cfg.TLSInsecureSkipVerify} //nolint: gosec
+}
+
+// Conn returns a new connection of the configured transport.
+func (cfg *Config) Conn() (Conn, error) {
+	switch cfg.Transport {
+	case TransportSerial:
+		portName := cfg.PortName
+		if portName == "" {
+			var err error
+			if portName, err = SerialDefaultPortName(); err != nil {
+				return nil, err
+			}
+		}
+		return NewSerial(portName, SerialConfig{
+			BaudRate:    cfg.BaudRate,
+			DataBits:    cfg.DataBits,
+			Parity:      cfg.Parity,
+			StopBits:    cfg.StopBits,
+			ReadTimeout: cfg.SerialReadTimeout,
+		})
+	case TransportTCP:
+		return NewTCPClient(cfg.Host, cfg.Port, cfg.DialTimeout)
+	case TransportTLS:
+		return NewTLSClient(cfg.Host, cfg.Port, cfg.tlsConfig())
+	case TransportUDP:
+		return NewUDPClient(cfg.Host, cfg.Port)
+	case TransportWebSocket:
+		return NewWebSocketClient(cfg.URL)
+	default:
+		return nil, fmt.Errorf("invalid transport %q", cfg.Transport)
+	}
+}
+
+// NewFromConfig returns a new client instance created from a configuration.
+func NewFromConfig(cfg *Config, handler func(msg Msg, err error)) (*Client, error) {
+	conn, err := cfg.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn, append([]Option{WithHandler(handler)}, cfg.Options()...)...), nil
+}
+
+// Config returns the effective client configuration.
+// The transport is empty for connections not created by a transport of the configuration
+// (like MockConn).
+func (c *Client) Config() *Config {
+	cfg := &Config{
+		Timeout:       c.timeout,
+		WriteTimeout:  c.writeTimeout,
+		MaxTimeouts:   c.maxTimeouts,
+		Cache:         c.cache != nil,
+		StateCache:    c.state != nil,
+		AutoReconnect: c.autoConnect,
+		MaxInFlight:   c.maxInFlight,
+		BusyErr:       c.busyErr,
+		Coalescing:    c.coalesce != nil,
+		PushPolicy:    c.pushPolicy,
+		LeakWarning:   c.leakWarning,
+		MaxLineSize:   c.maxLineSize,
+		EchoSkip:      c.echoSkip,
+	}
+	cfg.PushBufferSize = c.pushBufSize
+	if cfg.PushBufferSize == 0 {
+		cfg.PushBufferSize = -1 // unbuffered
+	}
+	if c.accRelease != nil {
+		cfg.AccAutoRelease = c.accRelease.maxOn
+	}
+	c.mu.Lock()
+	cfg.RetryAttempts, cfg.RetryDelay = c.retryAttempts, c.retryDelay // see SetRetry
+	c.mu.Unlock()
+
+	switch conn := c.conn.(type) {
+	case *Serial:
+		cfg.Transport = TransportSerial
+		cfg.PortName = conn.portName
+		cfg.BaudRate = conn.cfg.BaudRate
+		cfg.DataBits = conn.cfg.DataBits
+		cfg.Parity = conn.cfg.Parity
+		cfg.StopBits = conn.cfg.StopBits
+		cfg.SerialReadTimeout = conn.cfg.ReadTimeout
+	case *TCPClient:
+		cfg.Transport = TransportTCP
+		cfg.Host, cfg.Port = conn.host, conn.port
+		cfg.DialTimeout = conn.dialTimeout
+	case *TLSClient:
+		cfg.Transport = TransportTLS
+		cfg.Host, cfg.Port = conn.host, conn.port
+		if conn.cfg != nil {
+			cfg.TLSServerName = conn.cfg.ServerName
+			cfg.TLSInsecureSkipVerify = conn.cfg.InsecureSkipVerify
+		}
+	case *UDPClient:
+		cfg.Transport = TransportUDP
+		cfg.Host, cfg.Port = conn.host, conn.port
+	case *WebSocketClient:
+		cfg.Transport = TransportWebSocket
+		cfg.URL = conn.URL()
+	}
+	return cfg
+}
@@ -0,0 +1,144 @@
+package client_test
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// listenStation serves the station handler on the listener until the listener is closed.
+func listenStation(l net.Listener, handler stationHandler) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go runStation(conn, handler)
+	}
+}
+
+// withOptions sets all options of the configuration to non-default values.
+func withOptions(cfg client.Config) client.Config {
+	cfg.Timeout = 5 * time.Second
+	cfg.WriteTimeout = 2 * time.Second
+	cfg.MaxTimeouts = 3
+	cfg.Cache = true
+	cfg.StateCache = true
+	cfg.AutoReconnect = true
+	cfg.MaxInFlight = 4
+	cfg.BusyErr = true
+	cfg.RetryAttempts = 2
+	cfg.RetryDelay = 10 * time.Millisecond
+	cfg.Coalescing = true
+	cfg.PushBufferSize = 10
+	cfg.PushPolicy = client.PushDropOldest
+	cfg.LeakWarning = true
+	cfg.AccAutoRelease = time.Second
+	cfg.MaxLineSize = 1024
+	cfg.EchoSkip = true
+	return cfg
+}
+
+func TestConfig(t *testing.T) {
+	handler := func(cmd string, args []string) []string { return []string{"?invcmd"} }
+
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcpListener.Close()
+	go listenStation(tcpListener, handler)
+	tcpHost, tcpPort, err := net.SplitHostPort(tcpListener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tlsCert, _ := selfSignedCert(t)
+	tlsListener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{tlsCert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tlsListener.Close()
+	go listenStation(tlsListener, handler)
+	tlsHost, tlsPort, err := net.SplitHostPort(tlsListener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	go runUDPStation(pc, handler)
+	udpHost, udpPort, err := net.SplitHostPort(pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(wsStation(t, handler, make(chan string, 10)))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		cfg  client.Config
+	}{
+		{"TCP", client.Config{Transport: client.TransportTCP, Host: tcpHost, Port: tcpPort, DialTimeout: time.Second}},
+		{"TLS", client.Config{Transport: client.TransportTLS, Host: tlsHost, Port: tlsPort, TLSServerName: "pico-cs", TLSInsecureSkipVerify: true}},
+		{"UDP", client.Config{Transport: client.TransportUDP, Host: udpHost, Port: udpPort}},
+		{"WebSocket", client.Config{Transport: client.TransportWebSocket, URL: "ws" + strings.TrimPrefix(server.URL, "http")}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := withOptions(test.cfg)
+
+			// json round trip
+			b, err := json.Marshal(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var jsonCfg client.Config
+			if err := json.Unmarshal(b, &jsonCfg); err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := client.NewFromConfig(&jsonCfg, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			if effCfg := c.Config(); *effCfg != cfg {
+				t.Fatalf("invalid effective configuration %+v - expected %+v", effCfg, cfg)
+			}
+		})
+	}
+}
+
+func TestConfigUnbufferedPush(t *testing.T) {
+	c := client.NewClient(client.NewMockConn(), client.WithPushBuffer(0, client.PushBlock))
+	defer c.Close()
+
+	cfg := c.Config()
+	if cfg.PushBufferSize != -1 {
+		t.Fatalf("invalid push buffer size %d - expected %d", cfg.PushBufferSize, -1)
+	}
+	c2 := client.NewClient(client.NewMockConn(), cfg.Options()...)
+	defer c2.Close()
+	if size := c2.Config().PushBufferSize; size != -1 {
+		t.Fatalf("invalid push buffer size %d - expected %d", size, -1)
+	}
+}
+
+func TestConfigInvalidTransport(t *testing.T) {
+	if _, err := client.NewFromConfig(&client.Config{Transport: "invalid"}, nil); err == nil {
+		t.Fatal("expected error on invalid transport")
+	}
+}
@@ -8,6 +8,8 @@ import (
 const (
 	reconnectRetry = 10
 	reconnectWait  = 500 * time.Millisecond
+
+	maxReconnectWait = 8 * time.Second // maximum wait time of the automatic reconnect backoff
 )
 
 // Conn is a stream oriented connection to the pico board.
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Consist represents a client side consist (multiple unit) of locos.
+//
+// The commands of a consist are sent to each member address. Members running reversed
+// in the consist receive the inverted direction. In contrast to decoder based consisting
+// (see ProgramConsist) no decoder configuration is needed.
+type Consist struct {
+	c       *Client
+	members []ConsistMember
+}
+
+// NewConsist returns a new consist of the members.
+func NewConsist(c *Client, members ...ConsistMember) *Consist {
+	return &Consist{c: c, members: slices.Clone(members)}
+}
+
+// Members returns the members of the consist.
+func (cs *Consist) Members() []ConsistMember { return slices.Clone(cs.members) }
+
+// each calls fn for all members. Errors of single members do not stop the processing of
+// the remaining members but are returned combined.
+func (cs *Consist) each(name string, fn func(m ConsistMember) error) error {
+	var errs []error
+	for _, m := range cs.members {
+		if err := fn(m); err != nil {
+			errs = append(errs, fmt.Errorf("consist %s loco %d: %w", name, m.Addr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetSpeed sets the speed (see SetLocoSpeed128) of all members.
+func (cs *Consist) SetSpeed(speed uint) error {
+	return cs.each("set speed", func(m ConsistMember) error {
+		_, err := cs.c.SetLocoSpeed128(m.Addr, speed)
+		return err
+	})
+}
+
+// SetDir sets the direction of the consist. Reversed members receive the inverted direction.
+func (cs *Consist) SetDir(dir bool) error {
+	return cs.each("set dir", func(m ConsistMember) error {
+		_, err := cs.c.SetLocoDir(m.Addr, dir != m.Reversed)
+		return err
+	})
+}
+
+// SetFct sets the function value of all members.
+func (cs *Consist) SetFct(no uint, fct bool) error {
+	return cs.each("set fct", func(m ConsistMember) error {
+		_, err := cs.c.SetLocoFct(m.Addr, no, fct)
+		return err
+	})
+}
@@ -0,0 +1,68 @@
+package client_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestConsist(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	c := newTestClient(t, recordStation(&mu, &cmds, func(cmd string, args []string) []string {
+		if args[0] == "99" {
+			return []string{"?invprm"}
+		}
+		switch cmd {
+		case "ld", "ls":
+			return []string{"=" + args[1]}
+		case "lf":
+			return []string{"=" + args[2]}
+		}
+		return []string{"?invcmd"}
+	}), nil)
+
+	cs := client.NewConsist(c, client.ConsistMember{Addr: 3}, client.ConsistMember{Addr: 4, Reversed: true}, client.ConsistMember{Addr: 5})
+
+	tests := []struct {
+		name     string
+		fn       func() error
+		expected []string
+	}{
+		{"SetDirForward", func() error { return cs.SetDir(true) }, []string{"ld 3 t", "ld 4 f", "ld 5 t"}},
+		{"SetDirBackward", func() error { return cs.SetDir(false) }, []string{"ld 3 f", "ld 4 t", "ld 5 f"}},
+		{"SetSpeed", func() error { return cs.SetSpeed(42) }, []string{"ls 3 42", "ls 4 42", "ls 5 42"}},
+		{"SetFct", func() error { return cs.SetFct(0, true) }, []string{"lf 3 0 t", "lf 4 0 t", "lf 5 0 t"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.fn(); err != nil {
+				t.Fatal(err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !slices.Equal(cmds, test.expected) {
+				t.Fatalf("invalid commands %v - expected %v", cmds, test.expected)
+			}
+			cmds = nil
+		})
+	}
+
+	t.Run("MemberError", func(t *testing.T) {
+		cs := client.NewConsist(c, client.ConsistMember{Addr: 99}, client.ConsistMember{Addr: 3})
+		err := cs.SetSpeed(10)
+		if !errors.Is(err, client.ErrInvPrm) || !strings.Contains(err.Error(), "loco 99") {
+			t.Fatalf("invalid error %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if expected := []string{"ls 99 10", "ls 3 10"}; !slices.Equal(cmds, expected) {
+			t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	cv19Idx      = 19
+	cv19Addr     = 0x7f
+	cv19Reversed = 1 << 7
+
+	// MaxConsistAddr is the maximum consist address (CV 19 bits 0-6).
+	MaxConsistAddr = 127
+)
+
+// CV19 represents the decoder consist address configuration CV 19 (advanced consisting).
+//
+// A decoder with a consist address responds to the speed and direction commands sent to the
+// consist address instead of its own address. In contrast to a client side consist, which sends
+// the commands to each member address, the command station only needs to send a single command,
+// so that all members change speed at the same time. On the other hand the consist configuration
+// is stored in the decoders and needs to be cleared (consist address zero) to address the locos
+// individually again.
+type CV19 struct {
+	Addr     uint // bits 0-6: consist address (zero: no consist)
+	Reversed bool // bit 7: reversed direction in the consist
+}
+
+// Encode returns the CV 19 byte value.
+func (cv CV19) Encode() (byte, error) {
+	if cv.Addr > MaxConsistAddr {
+		return 0, fmt.Errorf("invalid consist address %d - expected 0-%d", cv.Addr, MaxConsistAddr)
+	}
+	b := byte(cv.Addr)
+	if cv.Reversed {
+		b |= cv19Reversed
+	}
+	return b, nil
+}
+
+// DecodeCV19 decodes a CV 19 byte value.
+func DecodeCV19(b byte) CV19 {
+	return CV19{Addr: uint(b & cv19Addr), Reversed: b&cv19Reversed != 0}
+}
+
+// SetLocoConsist sets the consist address configuration (CV 19) of a loco via main track programming.
+func (c *Client) SetLocoConsist(addr uint, cv19 CV19) (CV19, error) {
+	b, err := cv19.Encode()
+	if err != nil {
+		return CV19{}, err
+	}
+	v, err := c.SetLocoCVByte(addr, cv19Idx, b)
+	if err != nil {
+		return CV19{}, err
+	}
+	return DecodeCV19(v), nil
+}
+
+// ReadLocoConsist reads the consist address configuration (CV 19) of the loco placed on the programming track
+// (see ReadLocoCVByte).
+func (c *Client) ReadLocoConsist() (CV19, error) {
+	v, err := c.ReadLocoCVByte(cv19Idx)
+	if err != nil {
+		return CV19{}, err
+	}
+	return DecodeCV19(v), nil
+}
+
+// ConsistMember represents a loco of a consist.
+type ConsistMember struct {
+	Addr     uint
+	Reversed bool // loco is running reversed in the consist
+}
+
+// ProgramConsist programs the consist address (CV 19) of all members, so that the members
+// respond to the commands sent to the consist address (decoder based consisting).
+// A consist address of zero clears the consist configuration of the members.
+// Errors of single members do not stop the programming of the remaining members but are returned combined.
+func (c *Client) ProgramConsist(consistAddr uint, members ...ConsistMember) error {
+	if consistAddr > MaxConsistAddr {
+		return fmt.Errorf("invalid consist address %d - expected 0-%d", consistAddr, MaxConsistAddr)
+	}
+	var errs []error
+	for _, m := range members {
+		cv19 := CV19{Addr: consistAddr, Reversed: m.Reversed && consistAddr != 0}
+		if _, err := c.SetLocoConsist(m.Addr, cv19); err != nil {
+			errs = append(errs, fmt.Errorf("program consist loco %d: %w", m.Addr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,107 @@
+package client_test
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestCV19(t *testing.T) {
+	tests := []struct {
+		cv19 client.CV19
+		b    byte
+	}{
+		{client.CV19{}, 0},
+		{client.CV19{Addr: 3}, 0x03},
+		{client.CV19{Addr: 127}, 0x7f},
+		{client.CV19{Addr: 10, Reversed: true}, 0x8a},
+	}
+
+	for _, test := range tests {
+		b, err := test.cv19.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b != test.b {
+			t.Errorf("encode %+v: %08b - expected %08b", test.cv19, b, test.b)
+		}
+		if cv19 := client.DecodeCV19(test.b); cv19 != test.cv19 {
+			t.Errorf("decode %08b: %+v - expected %+v", test.b, cv19, test.cv19)
+		}
+	}
+
+	if _, err := (client.CV19{Addr: 128}).Encode(); err == nil {
+		t.Fatal("expected error on invalid consist address")
+	}
+}
+
+// cvStation is a test station handler accepting main track CV byte programming.
+func cvStation(cmd string, args []string) []string {
+	if cmd == "lcvbyte" && len(args) == 3 {
+		return []string{"=" + args[2]}
+	}
+	return []string{"?invcmd"}
+}
+
+func TestProgramConsist(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	c := newTestClient(t, recordStation(&mu, &cmds, cvStation), nil)
+
+	members := []client.ConsistMember{{Addr: 3}, {Addr: 1024, Reversed: true}}
+
+	if err := c.ProgramConsist(10, members...); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ProgramConsist(0, members...); err != nil { // clear
+		t.Fatal(err)
+	}
+	if err := c.ProgramConsist(client.MaxConsistAddr+1, members...); err == nil {
+		t.Fatal("expected error on invalid consist address")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{"lcvbyte 3 19 10", "lcvbyte 1024 19 138", "lcvbyte 3 19 0", "lcvbyte 1024 19 0"}
+	if !slices.Equal(cmds, expected) {
+		t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+	}
+}
+
+func TestProgramConsistMemberError(t *testing.T) {
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		if args[0] == "4" {
+			return []string{"?invprm"}
+		}
+		return cvStation(cmd, args)
+	}, nil)
+
+	err := c.ProgramConsist(10, client.ConsistMember{Addr: 3}, client.ConsistMember{Addr: 4}, client.ConsistMember{Addr: 5})
+	if !errors.Is(err, client.ErrInvPrm) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrInvPrm)
+	}
+}
+
+func TestReadLocoConsist(t *testing.T) {
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		switch {
+		case cmd == "h":
+			return []string{"-pcvbyte <idx>: programming track cv byte", "."}
+		case cmd == "pcvbyte" && args[0] == "19":
+			return []string{"=138"}
+		}
+		return []string{"?invcmd"}
+	}, nil)
+
+	cv19, err := c.ReadLocoConsist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (client.CV19{Addr: 10, Reversed: true}); cv19 != expected {
+		t.Fatalf("invalid cv19 %+v - expected %+v", cv19, expected)
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+// CV29 bits.
+const (
+	cv29Reversed   = 1 << 0
+	cv29SpeedSteps = 1 << 1
+	cv29Analog     = 1 << 2
+	cv29RailCom    = 1 << 3
+	cv29SpeedTable = 1 << 4
+	cv29LongAddr   = 1 << 5
+	cv29Accessory  = 1 << 7
+)
+
+// CV29 represents the decoder configuration CV 29.
+type CV29 struct {
+	Reversed   bool // bit 0: reversed direction
+	SpeedSteps bool // bit 1: 28/128 speed steps (false: 14 speed steps)
+	Analog     bool // bit 2: analog (power source) conversion enabled
+	RailCom    bool // bit 3: RailCom (BiDi) enabled
+	SpeedTable bool // bit 4: user defined speed table (false: speed table defined by CV 2, 5 and 6)
+	LongAddr   bool // bit 5: long address (CV 17 and 18) - false: short address (CV 1)
+	Accessory  bool // bit 7: accessory decoder (false: multifunction decoder)
+}
+
+// Encode returns the CV 29 byte value. The long address bit 5 corresponds to the value set by SetLocoCV29Bit5.
+func (cv CV29) Encode() byte {
+	var b byte
+	set := func(v bool, bit byte) {
+		if v {
+			b |= bit
+		}
+	}
+	set(cv.Reversed, cv29Reversed)
+	set(cv.SpeedSteps, cv29SpeedSteps)
+	set(cv.Analog, cv29Analog)
+	set(cv.RailCom, cv29RailCom)
+	set(cv.SpeedTable, cv29SpeedTable)
+	set(cv.LongAddr, cv29LongAddr)
+	set(cv.Accessory, cv29Accessory)
+	return b
+}
+
+// DecodeCV29 decodes a CV 29 byte value. The reserved bit 6 is ignored.
+func DecodeCV29(b byte) CV29 {
+	return CV29{
+		Reversed:   b&cv29Reversed != 0,
+		SpeedSteps: b&cv29SpeedSteps != 0,
+		Analog:     b&cv29Analog != 0,
+		RailCom:    b&cv29RailCom != 0,
+		SpeedTable: b&cv29SpeedTable != 0,
+		LongAddr:   b&cv29LongAddr != 0,
+		Accessory:  b&cv29Accessory != 0,
+	}
+}
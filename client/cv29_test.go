@@ -0,0 +1,45 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestCV29(t *testing.T) {
+	tests := []struct {
+		cv29 client.CV29
+		b    byte
+	}{
+		{client.CV29{}, 0},
+		{client.CV29{Reversed: true}, 0x01},
+		{client.CV29{SpeedSteps: true}, 0x02},
+		{client.CV29{Analog: true}, 0x04},
+		{client.CV29{RailCom: true}, 0x08},
+		{client.CV29{SpeedTable: true}, 0x10},
+		{client.CV29{LongAddr: true}, 0x20},
+		{client.CV29{Accessory: true}, 0x80},
+		{client.CV29{SpeedSteps: true, Analog: true}, 0x06}, // common default value
+		{client.CV29{Reversed: true, SpeedSteps: true, Analog: true, RailCom: true, SpeedTable: true, LongAddr: true, Accessory: true}, 0xbf},
+	}
+
+	for _, test := range tests {
+		if b := test.cv29.Encode(); b != test.b {
+			t.Errorf("encode %+v: %08b - expected %08b", test.cv29, b, test.b)
+		}
+		if cv29 := client.DecodeCV29(test.b); cv29 != test.cv29 {
+			t.Errorf("decode %08b: %+v - expected %+v", test.b, cv29, test.cv29)
+		}
+	}
+}
+
+func TestCV29ReservedBit(t *testing.T) {
+	if cv29 := client.DecodeCV29(0x40); cv29 != (client.CV29{}) {
+		t.Fatalf("decode reserved bit: %+v - expected %+v", cv29, client.CV29{})
+	}
+	for b := 0; b <= 0xff; b++ {
+		if v := client.DecodeCV29(byte(b)).Encode(); v != byte(b)&^0x40 {
+			t.Errorf("round trip %08b: %08b", b, v)
+		}
+	}
+}
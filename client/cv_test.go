@@ -0,0 +1,190 @@
+package client_test
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestSetLocoCVBytes(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		if cmd != "lcvbyte" {
+			return []string{"?invcmd"}
+		}
+		cmds = append(cmds, strings.Join(args, " "))
+		if args[1] == "8" { // read-only manufacturer id
+			return []string{"?invprm"}
+		}
+		return []string{"=" + args[2]}
+	}, nil)
+
+	t.Run("Ordering", func(t *testing.T) {
+		cvs := map[uint]byte{29: 34, 3: 10, 4: 12, 1: 5}
+		confirmed, err := c.SetLocoCVBytes(3, cvs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !maps.Equal(confirmed, cvs) {
+			t.Fatalf("invalid confirmed values %v - expected %v", confirmed, cvs)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		expected := []string{"3 1 5", "3 3 10", "3 4 12", "3 29 34"}
+		if !slices.Equal(cmds, expected) {
+			t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+		}
+		cmds = nil
+	})
+
+	t.Run("PartialFailure", func(t *testing.T) {
+		cvs := map[uint]byte{3: 10, 8: 8, 29: 34}
+		confirmed, err := c.SetLocoCVBytes(3, cvs)
+		if !errors.Is(err, client.ErrInvPrm) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrInvPrm)
+		}
+		if !strings.Contains(err.Error(), "cv 8") {
+			t.Fatalf("error %q does not contain failing cv index", err)
+		}
+		expected := map[uint]byte{3: 10}
+		if !maps.Equal(confirmed, expected) {
+			t.Fatalf("invalid confirmed values %v - expected %v", confirmed, expected)
+		}
+	})
+}
+
+func TestReadLocoCVByte(t *testing.T) {
+	cvs := map[string]string{"1": "3", "29": "6"}
+
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		switch cmd {
+		case "h":
+			return []string{"-pcvbyte <idx>: programming track cv byte", "."}
+		case "pcvbyte":
+		default:
+			return []string{"?invcmd"}
+		}
+		v, ok := cvs[args[0]]
+		if !ok { // no decoder acknowledgement
+			return []string{"?nodata"}
+		}
+		return []string{"=" + v}
+	}, nil)
+
+	v, err := c.ReadLocoCVByte(29)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 6 {
+		t.Fatalf("invalid cv value %d - expected %d", v, 6)
+	}
+	if _, err := c.ReadLocoCVByte(8); !errors.Is(err, client.ErrNoData) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrNoData)
+	}
+}
+
+func TestReadLocoCVByteNotImpl(t *testing.T) {
+	var cmds []string
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		cmds = append(cmds, cmd)
+		if cmd == "h" {
+			return []string{"-h: help", "."}
+		}
+		return []string{"?invcmd"}
+	}, nil)
+
+	if _, err := c.ReadLocoCVByte(29); !errors.Is(err, client.ErrNotImpl) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrNotImpl)
+	}
+	if expected := []string{"h"}; !slices.Equal(cmds, expected) {
+		t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+	}
+}
+
+func TestCVIdx(t *testing.T) {
+	tests := []struct {
+		idx  client.CVIdx
+		name string
+	}{
+		{client.CVMT, "MT"},
+		{client.CVNumSyncBit, "NumSyncBit"},
+		{client.CVNumRepeat, "NumRepeat"},
+		{client.CVNumRepeatCV, "NumRepeatCV"},
+		{client.CVNumRepeatAcc, "NumRepeatAcc"},
+		{client.CVBidiTS, "BidiTS"},
+		{client.CVBidiTE, "BidiTE"},
+	}
+	if len(tests) != int(client.CVBidiTE)+1 {
+		t.Fatalf("invalid number of tests %d - expected %d", len(tests), client.CVBidiTE+1)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.idx.String() != test.name {
+				t.Fatalf("invalid name %s - expected %s", test.idx, test.name)
+			}
+			idx, ok := client.ParseCVIdx(test.name)
+			if !ok || idx != test.idx {
+				t.Fatalf("invalid index %d ok %t - expected %d", idx, ok, test.idx)
+			}
+		})
+	}
+
+	if s := (client.CVBidiTE + 1).String(); s != "CVIdx(7)" {
+		t.Fatalf("invalid name %s - expected %s", s, "CVIdx(7)")
+	}
+	if _, ok := client.ParseCVIdx("unknown"); ok {
+		t.Fatal("unexpected index of unknown name")
+	}
+}
+
+func TestSetCVChanged(t *testing.T) {
+	cvs := map[string]string{"0": "10"}
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		if cmd != "cv" || len(args) != 2 {
+			return []string{"?invcmd"}
+		}
+		switch {
+		case args[0] == "99":
+			return []string{"?invprm"}
+		case cvs[args[0]] == args[1]:
+			return []string{"?nochange"}
+		}
+		cvs[args[0]] = args[1]
+		return []string{"=" + args[1]}
+	}, nil)
+
+	tests := []struct {
+		idx     client.CVIdx
+		val     byte
+		changed bool
+		err     error
+	}{
+		{0, 10, false, nil},
+		{0, 20, true, nil},
+		{0, 20, false, nil},
+		{99, 1, false, client.ErrInvPrm},
+	}
+
+	for i, test := range tests {
+		v, changed, err := c.SetCVChanged(test.idx, test.val)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("test %d: invalid error %v - expected %v", i, err, test.err)
+		}
+		if err != nil {
+			continue
+		}
+		if v != test.val || changed != test.changed {
+			t.Fatalf("test %d: invalid value %d changed %t - expected %d %t", i, v, changed, test.val, test.changed)
+		}
+	}
+}
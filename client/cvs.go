@@ -0,0 +1,136 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// AllCVs returns the values of all command station CVs.
+// The CVs known by the client (CVMT-CVBidiTE) are read in a single batch. CVs added by newer
+// firmware versions are read one by one afterwards. Reading stops at the first CV index rejected
+// by the command station (ErrInvPrm), so that older firmware versions providing less CVs are supported.
+// On any other error the CVs read so far are returned together with the error.
+func (c *Client) AllCVs() (map[CVIdx]byte, error) {
+	b := c.Batch()
+	for idx := CVMT; idx <= CVBidiTE; idx++ {
+		b.add(cmdCV, idx)
+	}
+	results, runErr := b.Run()
+
+	cvs := make(map[CVIdx]byte, len(results))
+	for i, result := range results {
+		if errors.Is(result.Err, ErrInvPrm) { // no more cvs
+			return cvs, nil
+		}
+		if result.Err != nil {
+			return cvs, fmt.Errorf("cv %d: %w", i, result.Err)
+		}
+		v, err := parseByte(result.Reply)
+		if err != nil {
+			return cvs, fmt.Errorf("cv %d: %w", i, err)
+		}
+		cvs[CVIdx(i)] = v
+	}
+	if len(results) != int(CVBidiTE)+1 { // connection error or timeout
+		return cvs, runErr
+	}
+
+	for idx := int(CVBidiTE) + 1; idx <= math.MaxUint8; idx++ {
+		v, err := c.CV(CVIdx(idx))
+		if errors.Is(err, ErrInvPrm) { // no more cvs
+			break
+		}
+		if err != nil {
+			return cvs, fmt.Errorf("cv %d: %w", idx, err)
+		}
+		cvs[CVIdx(idx)] = v
+	}
+	return cvs, nil
+}
+
+// SetCVs sets the command station CV values in a single batch in ascending CV index order and returns
+// the confirmed values. Errors of single CVs do not stop the processing of the remaining CVs but
+// are returned combined. On a connection error or timeout the values confirmed so far are returned
+// together with the error.
+func (c *Client) SetCVs(cvs map[CVIdx]byte) (map[CVIdx]byte, error) {
+	idxs := make([]CVIdx, 0, len(cvs))
+	for idx := range cvs {
+		idxs = append(idxs, idx)
+	}
+	slices.Sort(idxs)
+
+	b := c.Batch()
+	for _, idx := range idxs {
+		b.SetCV(idx, cvs[idx])
+	}
+	results, runErr := b.Run()
+
+	confirmed := make(map[CVIdx]byte, len(cvs))
+	var errs []error
+	for i, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("set cv %d: %w", idxs[i], result.Err))
+			continue
+		}
+		v, err := parseByte(result.Reply)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("set cv %d: %w", idxs[i], err))
+			continue
+		}
+		confirmed[idxs[i]] = v
+	}
+	if len(results) != len(idxs) { // connection error or timeout
+		errs = append(errs, runErr)
+	}
+	return confirmed, errors.Join(errs...)
+}
+
+// StoreAndVerify stores the command station CVs on flash (see Store) and verifies the store by reading
+// the CVs again (see AllCVs): the values read after the store need to match the values read before.
+// As the flash layout of the firmware is not documented, the stored values are not read back from flash.
+// The indices of the mismatching CVs are returned in ascending order together with an error listing
+// the mismatches.
+func (c *Client) StoreAndVerify() ([]CVIdx, error) {
+	live, err := c.AllCVs()
+	if err != nil {
+		return nil, fmt.Errorf("store and verify: %w", err)
+	}
+	ok, err := c.Store()
+	if err != nil {
+		return nil, fmt.Errorf("store and verify: %w", err)
+	}
+	if !ok {
+		return nil, errors.New("store and verify: cvs not stored")
+	}
+	stored, err := c.AllCVs()
+	if err != nil {
+		return nil, fmt.Errorf("store and verify: %w", err)
+	}
+
+	idxs := make([]CVIdx, 0, len(live))
+	for idx := range live {
+		idxs = append(idxs, idx)
+	}
+	slices.Sort(idxs)
+
+	var mismatches []CVIdx
+	var errs []error
+	for _, idx := range idxs {
+		v, ok := stored[idx]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("cv %d not read after store - expected %d", idx, live[idx]))
+		case v != live[idx]:
+			errs = append(errs, fmt.Errorf("cv %d read %d after store - expected %d", idx, v, live[idx]))
+		default:
+			continue
+		}
+		mismatches = append(mismatches, idx)
+	}
+	if errs != nil {
+		return mismatches, fmt.Errorf("store and verify: %w", errors.Join(errs...))
+	}
+	return nil, nil
+}
@@ -0,0 +1,208 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// csCVStation returns a station handler keeping n command station CVs (CV i has initial value 10+i).
+// CV 3 is read-only.
+func csCVStation(n int) stationHandler {
+	var mu sync.Mutex
+	cvs := make([]byte, n)
+	for i := range cvs {
+		cvs[i] = byte(10 + i)
+	}
+	return func(cmd string, args []string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		if cmd != "cv" {
+			return []string{"?invcmd"}
+		}
+		idx, err := strconv.Atoi(args[0])
+		if err != nil || idx >= n {
+			return []string{"?invprm"}
+		}
+		if len(args) == 2 {
+			if idx == 3 {
+				return []string{"?invprm"}
+			}
+			v, _ := strconv.Atoi(args[1])
+			cvs[idx] = byte(v)
+		}
+		return []string{fmt.Sprintf("=%d", cvs[idx])}
+	}
+}
+
+func TestAllCVs(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+	}{
+		{"Older", int(client.CVBidiTE) - 2},
+		{"Known", int(client.CVBidiTE) + 1},
+		{"Extended", int(client.CVBidiTE) + 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestClient(t, csCVStation(test.n), nil)
+
+			cvs, err := c.AllCVs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := map[client.CVIdx]byte{}
+			for i := 0; i < test.n; i++ {
+				expected[client.CVIdx(i)] = byte(10 + i)
+			}
+			if !maps.Equal(cvs, expected) {
+				t.Fatalf("invalid cvs %v - expected %v", cvs, expected)
+			}
+		})
+	}
+}
+
+func TestAllCVsPartial(t *testing.T) {
+	station := csCVStation(int(client.CVBidiTE) + 1)
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		if cmd == "cv" && args[0] == "2" {
+			return []string{"?invcmd"}
+		}
+		return station(cmd, args)
+	}, nil)
+
+	cvs, err := c.AllCVs()
+	if !errors.Is(err, client.ErrInvCmd) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrInvCmd)
+	}
+	expected := map[client.CVIdx]byte{0: 10, 1: 11}
+	if !maps.Equal(cvs, expected) {
+		t.Fatalf("invalid cvs %v - expected %v", cvs, expected)
+	}
+}
+
+// breakConn is a mock connection which is lost after writing the command line brk.
+type breakConn struct {
+	*client.MockConn
+	brk string
+}
+
+func (c *breakConn) Write(p []byte) (int, error) {
+	n, err := c.MockConn.Write(p)
+	if strings.Contains(string(p), "+"+c.brk+"\r") {
+		c.Disconnect(io.ErrUnexpectedEOF)
+	}
+	return n, err
+}
+
+func TestCVsConnectionLost(t *testing.T) {
+	// the station does not reply from cv 3 on and the connection is lost after the batch was written
+	station := csCVStation(int(client.CVBidiTE) + 1)
+	handler := func(cmd string, args []string) []string {
+		if idx, _ := strconv.Atoi(args[0]); idx >= 3 {
+			return nil
+		}
+		return station(cmd, args)
+	}
+
+	t.Run("AllCVs", func(t *testing.T) {
+		conn := &breakConn{MockConn: client.NewMockConn(), brk: fmt.Sprintf("cv %d", client.CVBidiTE)}
+		conn.HandleFunc(handler)
+		c := client.New(conn, nil)
+		defer c.Close()
+
+		cvs, err := c.AllCVs()
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("invalid error %v - expected %v", err, io.ErrUnexpectedEOF)
+		}
+		expected := map[client.CVIdx]byte{0: 10, 1: 11, 2: 12}
+		if !maps.Equal(cvs, expected) {
+			t.Fatalf("invalid cvs %v - expected %v", cvs, expected)
+		}
+	})
+
+	t.Run("SetCVs", func(t *testing.T) {
+		conn := &breakConn{MockConn: client.NewMockConn(), brk: "cv 5 25"}
+		conn.HandleFunc(handler)
+		c := client.New(conn, nil)
+		defer c.Close()
+
+		confirmed, err := c.SetCVs(map[client.CVIdx]byte{0: 20, 1: 21, 5: 25})
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("invalid error %v - expected %v", err, io.ErrUnexpectedEOF)
+		}
+		expected := map[client.CVIdx]byte{0: 20, 1: 21}
+		if !maps.Equal(confirmed, expected) {
+			t.Fatalf("invalid confirmed cvs %v - expected %v", confirmed, expected)
+		}
+	})
+}
+
+func TestSetCVs(t *testing.T) {
+	c := newTestClient(t, csCVStation(int(client.CVBidiTE)+1), nil)
+
+	confirmed, err := c.SetCVs(map[client.CVIdx]byte{client.CVNumSyncBit: 20, client.CVNumRepeatCV: 5, client.CVBidiTE: 14})
+	if !errors.Is(err, client.ErrInvPrm) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrInvPrm)
+	}
+	expected := map[client.CVIdx]byte{client.CVNumSyncBit: 20, client.CVBidiTE: 14}
+	if !maps.Equal(confirmed, expected) {
+		t.Fatalf("invalid confirmed cvs %v - expected %v", confirmed, expected)
+	}
+
+	cvs, err := c.AllCVs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cvs[client.CVNumSyncBit] != 20 || cvs[client.CVNumRepeatCV] != 13 || cvs[client.CVBidiTE] != 14 {
+		t.Fatalf("invalid cvs %v", cvs)
+	}
+}
+
+func TestStoreAndVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		corrupt    bool // store changes cv 2
+		mismatches []client.CVIdx
+	}{
+		{"Verified", false, nil},
+		{"Mismatch", true, []client.CVIdx{2}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			station := csCVStation(int(client.CVBidiTE) + 1)
+			c := newTestClient(t, func(cmd string, args []string) []string {
+				if cmd != "s" {
+					return station(cmd, args)
+				}
+				if test.corrupt {
+					station("cv", []string{"2", "99"})
+				}
+				return []string{"=t"}
+			}, nil)
+
+			mismatches, err := c.StoreAndVerify()
+			if !slices.Equal(mismatches, test.mismatches) {
+				t.Fatalf("invalid mismatches %v - expected %v", mismatches, test.mismatches)
+			}
+			if !test.corrupt {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if expected := "cv 2 read 99 after store - expected 12"; err == nil || !strings.Contains(err.Error(), expected) {
+				t.Fatalf("invalid error %v - expected %q", err, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package client_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestEcho(t *testing.T) {
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		switch cmd {
+		case "h":
+			return []string{"+h", "-line 1", "+cv 1 -1", "-line 2", "+ld 3 .", "."}
+		case "t":
+			return []string{"+t", "=27.5"}
+		}
+		return []string{"?invcmd"}
+	}, nil, client.WithEchoSkip())
+
+	lines, err := c.Help()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"line 1", "line 2"}
+	if !slices.Equal(lines, expected) {
+		t.Fatalf("invalid lines %v - expected %v", lines, expected)
+	}
+
+	temp, err := c.Temp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp != 27.5 {
+		t.Fatalf("invalid temperature %f - expected %f", temp, 27.5)
+	}
+}
@@ -0,0 +1,44 @@
+package client_test
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestEmergencyStopAll(t *testing.T) {
+	var mu sync.Mutex
+	var stopped []string
+
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		switch cmd {
+		case "r":
+			return refreshBufferReply(3, 300, 3, 5)
+		case "ls":
+			if args[1] != "1" {
+				return []string{"?invprm"}
+			}
+			if args[0] == "300" {
+				return []string{"?ioerr"}
+			}
+			mu.Lock()
+			stopped = append(stopped, args[0])
+			mu.Unlock()
+			return []string{"=" + args[1]}
+		}
+		return []string{"?invcmd"}
+	}, nil)
+
+	err := c.EmergencyStopAll()
+	if !errors.Is(err, client.ErrIO) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrIO)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(stopped, []string{"3", "5"}) {
+		t.Fatalf("invalid stopped locos %v - expected %v", stopped, []string{"3", "5"})
+	}
+}
@@ -0,0 +1,91 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestWatchRefreshBufferRemoval(t *testing.T) {
+	tests := []struct {
+		name    string
+		fail    bool // removal fails with a command station error
+		remove  func(c *client.Client) error
+		evicted []uint
+	}{
+		{"Delete", false, func(c *client.Client) error { _, err := c.RefreshBufferDelete(3); return err }, nil},
+		{"DeleteFailed", true, func(c *client.Client) error { _, err := c.RefreshBufferDelete(3); return err }, []uint{3}},
+		{"Reset", false, func(c *client.Client) error { _, err := c.RefreshBufferReset(); return err }, nil},
+		{"ResetFailed", true, func(c *client.Client) error { _, err := c.RefreshBufferReset(); return err }, []uint{3, 5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mu sync.Mutex
+			addrs := []uint{3, 5}
+			var evicted []uint
+			polled := make(chan struct{}, 100)
+
+			// the station removes the locos even if the removal fails (like on a concurrent eviction)
+			c := newTestClient(t, func(cmd string, args []string) []string {
+				mu.Lock()
+				defer mu.Unlock()
+				switch cmd {
+				case "r":
+					polled <- struct{}{}
+					return refreshBufferReply(addrs...)
+				case "rd":
+					addrs = slices.DeleteFunc(addrs, func(addr uint) bool { return strconv.FormatUint(uint64(addr), 10) == args[0] })
+					if test.fail {
+						return []string{"?io"}
+					}
+					return []string{"=" + args[0]}
+				case "rr":
+					addrs = nil
+					if test.fail {
+						return []string{"?io"}
+					}
+					return []string{"=t"}
+				}
+				return []string{"?invcmd"}
+			}, func(msg client.Msg, err error) {
+				if msg, ok := msg.(*client.EvictMsg); ok {
+					mu.Lock()
+					evicted = append(evicted, msg.Addr)
+					mu.Unlock()
+				}
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- c.WatchRefreshBuffer(ctx, time.Millisecond) }()
+
+			<-polled // first poll
+			if err := test.remove(c); test.fail != (err != nil) {
+				t.Fatalf("invalid removal error %v", err)
+			}
+			// drain the polls before the removal and wait for the poll after the removal to be evaluated
+			for len(polled) > 0 {
+				<-polled
+			}
+			<-polled
+			<-polled
+			cancel()
+			if err := <-done; !errors.Is(err, context.Canceled) {
+				t.Fatalf("invalid error %v - expected %v", err, context.Canceled)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !slices.Equal(evicted, test.evicted) {
+				t.Fatalf("invalid evicted locos %v - expected %v", evicted, test.evicted)
+			}
+		})
+	}
+}
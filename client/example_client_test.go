@@ -22,14 +22,14 @@ func ExampleClient() {
 		log.Fatal(err)
 	}
 
-	client := client.New(conn, func(msg client.Msg, err error) {
+	client := client.NewClient(conn, client.WithHandler(func(msg client.Msg, err error) {
 		// handle push messages
 		if err != nil {
 			log.Printf("push message: %s", msg)
 		} else {
 			log.Printf("push message error: %s", err)
 		}
-	})
+	}))
 	defer client.Close()
 
 	// read board information.
@@ -48,3 +48,21 @@ func ExampleClient() {
 
 	// output:
 }
+
+// ExampleNewWebSocketClient shows how to connect to a command station via a WebSocket gateway.
+func ExampleNewWebSocketClient() {
+	conn, err := client.NewWebSocketClient("ws://localhost:8080/cs")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client := client.NewClient(conn)
+	defer client.Close()
+
+	// read command station temperature.
+	temp, err := client.Temp()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("temperature %f", temp)
+}
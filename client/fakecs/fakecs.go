@@ -0,0 +1,493 @@
+// Package fakecs provides a fake command station implementing the pico-cs text protocol
+// with in-memory state, so that applications can be developed and tested without hardware.
+package fakecs
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pico-cs/go-client/client"
+	"github.com/pico-cs/go-client/client/flash"
+	"github.com/pico-cs/go-client/client/rbuf"
+)
+
+// Board information reported by the fake command station.
+const (
+	BoardType = "pico"
+	BoardID   = "FAKECS0000000001"
+)
+
+// Limits of the fake command station.
+const (
+	maxAddr    = 10239 // maximum loco address
+	maxSpeed   = 127
+	numCV      = 7 // number of command station CVs (see client.CVIdx)
+	numPage    = 4 // number of flash pages
+	maxRefresh = 64
+)
+
+// default command station CV values.
+var defaultCVs = [numCV]byte{0, 17, 3, 2, 2, 26, 12}
+
+// error texts.
+const (
+	etInvCmd    = "invcmd"
+	etInvPrm    = "invprm"
+	etInvNumPrm = "invnumprm"
+	etInvGPIO   = "invgpio"
+)
+
+var help = []string{
+	"h: help",
+	"b: board info",
+	"s: store cvs",
+	"t: temperature",
+	"cv <idx> [<value>]: command station cv",
+	"mte [t|f|~]: main track enabled",
+	"ld <addr> [t|f|~]: loco direction",
+	"ls <addr> [<speed>]: loco speed",
+	"lf <addr> <no> [t|f|~]: loco function",
+	"lcv1718 <addr>: loco long address cv 17 and 18",
+	"af <addr> <out> t|f: accessory function",
+	"at <addr> <out> <time>: accessory time",
+	"as <addr> <status>: accessory status",
+	"ioadc <input>: adc value",
+	"ioval <cmd> <gpio> [t|f|~]: gpio value",
+	"iodir <cmd> <gpio> [t|f|~]: gpio direction",
+	"ioup <cmd> <gpio> [t|f|~]: gpio pull-up",
+	"iodown <cmd> <gpio> [t|f|~]: gpio pull-down",
+	"r: refresh buffer",
+	"rr: refresh buffer reset",
+	"rd <addr>: refresh buffer delete",
+	"f: flash",
+	"ff: flash format",
+}
+
+type loco struct {
+	dir   bool
+	speed uint
+	fcts  [rbuf.MaxFct + 1]bool
+}
+
+type gpio struct {
+	val, dir, up, down bool
+}
+
+// Station represents a fake command station.
+type Station struct {
+	mu     sync.Mutex
+	temp   float64
+	mte    bool
+	cvs    [numCV]byte
+	pages  [numPage][flash.PageSize]byte
+	pageNo int
+	locos  map[uint]*loco
+	order  []uint // refresh buffer order of the loco addresses
+	gpios  map[uint]*gpio
+	adc    map[uint]float64
+	srv    *client.LoopbackServer
+}
+
+// New returns a new fake command station instance.
+func New() *Station {
+	s := &Station{
+		temp:  25,
+		cvs:   defaultCVs,
+		locos: map[uint]*loco{},
+		gpios: map[uint]*gpio{},
+		adc:   map[uint]float64{},
+	}
+	for _, no := range client.BtPico.GPIOs() {
+		s.gpios[no] = &gpio{}
+	}
+	s.formatFlash()
+	s.srv = client.NewLoopbackServer(s.Handle)
+	return s
+}
+
+// Conn returns a new in-memory connection to the station.
+func (s *Station) Conn() *client.LoopbackConn { return client.NewLoopbackConn(s.Serve) }
+
+// Serve serves the commands of a connection until the connection is closed
+// (like a connection accepted by a net.Listener).
+func (s *Station) Serve(conn net.Conn) { s.srv.Serve(conn) }
+
+// Push sends a push message (like "ioie: 5 t") to the connected client.
+func (s *Station) Push(msg string) error { return s.srv.Push(msg) }
+
+// SetTemp sets the temperature reported by the station.
+func (s *Station) SetTemp(temp float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.temp = temp
+}
+
+// SetADC sets the value of an ADC input.
+func (s *Station) SetADC(input uint, v float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.adc[input] = v
+}
+
+// SetInput sets the value of an input GPIO and sends an input event push message to the connected client.
+func (s *Station) SetInput(no uint, val bool) error {
+	s.mu.Lock()
+	g, ok := s.gpios[no]
+	if ok {
+		g.val = val
+	}
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("fakecs: invalid gpio %d", no)
+	}
+	return s.Push(fmt.Sprintf("ioie: %d %c", no, formatBool(val)))
+}
+
+func formatBool(b bool) byte {
+	if b {
+		return 't'
+	}
+	return 'f'
+}
+
+func reply(v any) []string { return []string{fmt.Sprintf("=%v", v)} }
+
+func replyBool(b bool) []string { return []string{fmt.Sprintf("=%c", formatBool(b))} }
+
+func replyErr(et string) []string { return []string{"?" + et} }
+
+func parseUint(s string, maxValue uint) (uint, bool) {
+	u64, err := strconv.ParseUint(s, 10, 0)
+	if err != nil || u64 > uint64(maxValue) {
+		return 0, false
+	}
+	return uint(u64), true
+}
+
+// setBool sets b to the value of s ("t", "f" or "~" toggle).
+func setBool(b *bool, s string) bool {
+	switch s {
+	case "t":
+		*b = true
+	case "f":
+		*b = false
+	case "~":
+		*b = !*b
+	default:
+		return false
+	}
+	return true
+}
+
+// Handle returns the reply lines (including the reply tags) of a command (see client.MockHandler).
+func (s *Station) Handle(cmd string, args []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch cmd {
+	case "h":
+		lines := make([]string, 0, len(help)+1)
+		for _, line := range help {
+			lines = append(lines, "-"+line)
+		}
+		return append(lines, ".")
+	case "b":
+		return reply(BoardType + " " + BoardID)
+	case "t":
+		return reply(strconv.FormatFloat(s.temp, 'f', -1, 64))
+	case "s":
+		s.store()
+		return replyBool(true)
+	case "cv":
+		return s.handleCV(args)
+	case "mte":
+		if len(args) > 1 {
+			return replyErr(etInvNumPrm)
+		}
+		if len(args) == 1 && !setBool(&s.mte, args[0]) {
+			return replyErr(etInvPrm)
+		}
+		return replyBool(s.mte)
+	case "ld", "ls", "lf":
+		return s.handleLoco(cmd, args)
+	case "lcv1718":
+		if len(args) != 1 {
+			return replyErr(etInvNumPrm)
+		}
+		addr, ok := parseUint(args[0], client.MaxLongAddr)
+		if !ok {
+			return replyErr(etInvPrm)
+		}
+		return reply(fmt.Sprintf("%d %d", 0xc0|addr>>8, addr&0xff))
+	case "af", "at", "as":
+		return s.handleAcc(cmd, args)
+	case "ioadc":
+		if len(args) != 1 {
+			return replyErr(etInvNumPrm)
+		}
+		input, ok := parseUint(args[0], 4)
+		if !ok {
+			return replyErr(etInvPrm)
+		}
+		return reply(strconv.FormatFloat(s.adc[input], 'f', -1, 64))
+	case "ioval", "iodir", "ioup", "iodown":
+		return s.handleIO(cmd, args)
+	case "r":
+		return s.refreshBuffer()
+	case "rr":
+		clear(s.locos)
+		s.order = nil
+		return replyBool(true)
+	case "rd":
+		if len(args) != 1 {
+			return replyErr(etInvNumPrm)
+		}
+		addr, ok := parseUint(args[0], maxAddr)
+		if !ok {
+			return replyErr(etInvPrm)
+		}
+		delete(s.locos, addr)
+		s.order = slices.DeleteFunc(s.order, func(a uint) bool { return a == addr })
+		return reply(addr)
+	case "f":
+		return s.flash()
+	case "ff":
+		s.formatFlash()
+		return replyBool(true)
+	default:
+		return replyErr(etInvCmd)
+	}
+}
+
+func (s *Station) handleCV(args []string) []string {
+	if len(args) < 1 || len(args) > 2 {
+		return replyErr(etInvNumPrm)
+	}
+	idx, ok := parseUint(args[0], numCV-1)
+	if !ok {
+		return replyErr(etInvPrm)
+	}
+	if len(args) == 2 {
+		v, ok := parseUint(args[1], 255)
+		if !ok {
+			return replyErr(etInvPrm)
+		}
+		s.cvs[idx] = byte(v)
+	}
+	return reply(s.cvs[idx])
+}
+
+// loco returns the loco with address addr. A loco is added to the refresh buffer if set is true.
+func (s *Station) loco(addr uint, set bool) *loco {
+	if l, ok := s.locos[addr]; ok {
+		return l
+	}
+	l := &loco{dir: true}
+	if set {
+		if len(s.order) == maxRefresh { // evict oldest loco
+			delete(s.locos, s.order[0])
+			s.order = s.order[1:]
+		}
+		s.locos[addr] = l
+		s.order = append(s.order, addr)
+	}
+	return l
+}
+
+func (s *Station) handleLoco(cmd string, args []string) []string {
+	numArgs := 1
+	if cmd == "lf" {
+		numArgs = 2
+	}
+	if len(args) != numArgs && len(args) != numArgs+1 {
+		return replyErr(etInvNumPrm)
+	}
+	addr, ok := parseUint(args[0], maxAddr)
+	if !ok || addr == 0 {
+		return replyErr(etInvPrm)
+	}
+	set := len(args) == numArgs+1
+	l := s.loco(addr, set)
+
+	switch cmd {
+	case "ld":
+		if set && !setBool(&l.dir, args[1]) {
+			return replyErr(etInvPrm)
+		}
+		return replyBool(l.dir)
+	case "ls":
+		if set {
+			speed, ok := parseUint(args[1], maxSpeed)
+			if !ok {
+				return replyErr(etInvPrm)
+			}
+			l.speed = speed
+		}
+		return reply(l.speed)
+	default: // lf
+		no, ok := parseUint(args[1], rbuf.MaxFct)
+		if !ok {
+			return replyErr(etInvPrm)
+		}
+		if set && !setBool(&l.fcts[no], args[2]) {
+			return replyErr(etInvPrm)
+		}
+		return replyBool(l.fcts[no])
+	}
+}
+
+func (s *Station) handleAcc(cmd string, args []string) []string {
+	numArgs := 3
+	if cmd == "as" {
+		numArgs = 2
+	}
+	if len(args) != numArgs {
+		return replyErr(etInvNumPrm)
+	}
+	if _, ok := parseUint(args[0], 2047); !ok {
+		return replyErr(etInvPrm)
+	}
+	switch cmd {
+	case "af":
+		if _, ok := parseUint(args[1], 1); !ok {
+			return replyErr(etInvPrm)
+		}
+		var fct bool
+		if !setBool(&fct, args[2]) || args[2] == "~" {
+			return replyErr(etInvPrm)
+		}
+	case "at":
+		_, ok1 := parseUint(args[1], 1)
+		_, ok2 := parseUint(args[2], 255)
+		if !ok1 || !ok2 {
+			return replyErr(etInvPrm)
+		}
+	default: // as
+		if _, ok := parseUint(args[1], 255); !ok {
+			return replyErr(etInvPrm)
+		}
+	}
+	return replyBool(true)
+}
+
+func (s *Station) handleIO(cmd string, args []string) []string {
+	if len(args) != 2 && len(args) != 3 {
+		return replyErr(etInvNumPrm)
+	}
+	if args[0] != strconv.Itoa(client.IOCmdLocal) {
+		return replyErr(etInvPrm)
+	}
+	no, ok := parseUint(args[1], 255)
+	if !ok {
+		return replyErr(etInvPrm)
+	}
+	g, ok := s.gpios[no]
+	if !ok {
+		return replyErr(etInvGPIO)
+	}
+	var b *bool
+	switch cmd {
+	case "ioval":
+		b = &g.val
+	case "iodir":
+		b = &g.dir
+	case "ioup":
+		b = &g.up
+	default: // iodown
+		b = &g.down
+	}
+	if len(args) == 3 && !setBool(b, args[2]) {
+		return replyErr(etInvPrm)
+	}
+	return replyBool(*b)
+}
+
+// entry returns the refresh buffer entry of a loco.
+func (l *loco) entry(idx, prev, next int, addr uint) rbuf.Entry {
+	var e rbuf.Entry
+	e[rbuf.Idx] = byte(idx)
+	e[rbuf.MSB] = byte(addr >> 8)
+	e[rbuf.LSB] = byte(addr)
+	e[rbuf.DirSpeed] = byte(l.speed)
+	if l.dir {
+		e[rbuf.DirSpeed] |= 0x80
+	}
+	for no, fct := range l.fcts {
+		if !fct {
+			continue
+		}
+		switch {
+		case no == 0:
+			e[rbuf.F0_4] |= 0x10
+		case no <= 4:
+			e[rbuf.F0_4] |= 1 << (no - 1)
+		case no <= 8:
+			e[rbuf.F5_8] |= 1 << (no - 5)
+		case no <= 12:
+			e[rbuf.F9_12] |= 1 << (no - 9)
+		default:
+			i := no - 13
+			e[rbuf.F13_20+i/8] |= 1 << (i % 8)
+		}
+	}
+	e[rbuf.Prev] = byte(prev)
+	e[rbuf.Next] = byte(next)
+	return e
+}
+
+func (s *Station) refreshBuffer() []string {
+	n := len(s.order)
+	lines := []string{fmt.Sprintf("-%d %d", 0, 0)}
+	for i, addr := range s.order {
+		e := s.locos[addr].entry(i, (i+n-1)%n, (i+1)%n, addr)
+		values := make([]string, len(e))
+		for j, v := range e {
+			values[j] = strconv.Itoa(int(v))
+		}
+		lines = append(lines, "-"+strings.Join(values, " "))
+	}
+	return append(lines, ".")
+}
+
+// formatFlash erases the flash and stores the default CVs.
+func (s *Station) formatFlash() {
+	for i := range s.pages {
+		for j := range s.pages[i] {
+			s.pages[i][j] = 0xff
+		}
+	}
+	s.pageNo = len(s.pages) - 1
+	cvs := s.cvs
+	s.cvs = defaultCVs
+	s.store()
+	s.cvs = cvs
+}
+
+// store writes the CVs to the next flash page. The page layout is specific to the fake
+// command station and not the flash format of the firmware.
+func (s *Station) store() {
+	s.pageNo = (s.pageNo + 1) % len(s.pages)
+	page := &s.pages[s.pageNo]
+	for i := range page {
+		page[i] = 0xff
+	}
+	copy(page[:], s.cvs[:])
+}
+
+func (s *Station) flash() []string {
+	lines := []string{fmt.Sprintf("-0 0 %d", s.pageNo)}
+	for _, page := range s.pages {
+		for i := 0; i < len(page); i += 32 {
+			values := make([]string, 32)
+			for j, v := range page[i : i+32] {
+				values[j] = fmt.Sprintf("%02x", v)
+			}
+			lines = append(lines, "-"+strings.Join(values, " "))
+		}
+	}
+	return append(lines, ".")
+}
@@ -0,0 +1,211 @@
+package fakecs_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+	"github.com/pico-cs/go-client/client/fakecs"
+)
+
+func newClient(t *testing.T, s *fakecs.Station, handler func(msg client.Msg, err error)) *client.Client {
+	c := client.New(s.Conn(), handler)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestStation(t *testing.T) {
+	s := fakecs.New()
+	c := newClient(t, s, nil)
+
+	t.Run("Board", func(t *testing.T) {
+		board, err := c.Board()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if board.Type != client.BtPico || board.ID != fakecs.BoardID {
+			t.Fatalf("invalid board %+v", board)
+		}
+		ok, err := c.HasCommand("lf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatal("command lf not supported")
+		}
+	})
+
+	t.Run("Temp", func(t *testing.T) {
+		s.SetTemp(31.5)
+		temp, err := c.Temp()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if temp != 31.5 {
+			t.Fatalf("invalid temperature %g - expected %g", temp, 31.5)
+		}
+	})
+
+	t.Run("CV", func(t *testing.T) {
+		if _, err := c.SetCV(client.CVNumSyncBit, 20); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Store(); err != nil {
+			t.Fatal(err)
+		}
+		v, err := c.CV(client.CVNumSyncBit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != 20 {
+			t.Fatalf("invalid cv %d - expected %d", v, 20)
+		}
+		if _, err := c.SetCV(client.CVBidiTE+1, 1); !errors.Is(err, client.ErrInvPrm) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrInvPrm)
+		}
+	})
+
+	t.Run("MTE", func(t *testing.T) {
+		enabled, err := c.SetMTE(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !enabled {
+			t.Fatal("main track not enabled")
+		}
+	})
+
+	t.Run("Loco", func(t *testing.T) {
+		if _, err := c.SetLocoSpeed128(3, 42); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.SetLocoDir(3, false); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.SetLocoFct(3, 0, true); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.SetLocoFct(3, 20, true); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.SetLocoSpeed128(5, 10); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.SetLocoSpeed128(0, 10); !errors.Is(err, client.ErrInvPrm) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrInvPrm)
+		}
+
+		buf, err := c.RefreshBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, err := buf.Walk()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("invalid number of entries %d - expected %d", len(entries), 2)
+		}
+		e, ok := buf.EntryByAddr(3)
+		if !ok {
+			t.Fatal("loco 3 not in refresh buffer")
+		}
+		if speed, forward := e.Speed(); speed != 42 || forward {
+			t.Fatalf("invalid speed %d forward %t - expected %d %t", speed, forward, 42, false)
+		}
+		for _, no := range []uint{0, 20} {
+			if !e.Function(no) {
+				t.Fatalf("function %d not set", no)
+			}
+		}
+
+		if _, err := c.RefreshBufferDelete(3); err != nil {
+			t.Fatal(err)
+		}
+		if buf, err = c.RefreshBuffer(); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := buf.EntryByAddr(3); ok || len(buf.Entries) != 1 {
+			t.Fatalf("invalid refresh buffer %s", buf)
+		}
+	})
+
+	t.Run("IO", func(t *testing.T) {
+		if _, err := c.SetIODir(client.IOCmdLocal, 25, true); err != nil {
+			t.Fatal(err)
+		}
+		v, err := c.ToggleIOVal(client.IOCmdLocal, 25)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !v {
+			t.Fatal("gpio 25 not set")
+		}
+		if _, err := c.IOVal(client.IOCmdLocal, 23); !errors.Is(err, client.ErrInvGPIO) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrInvGPIO)
+		}
+		s.SetADC(0, 1.25)
+		if v, err := c.IOADC(0); err != nil || v != 1.25 {
+			t.Fatalf("invalid adc value %g error %v - expected %g", v, err, 1.25)
+		}
+	})
+
+	t.Run("InvalidCmd", func(t *testing.T) {
+		if _, err := c.Raw("x"); !errors.Is(err, client.ErrInvCmd) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrInvCmd)
+		}
+		if _, err := c.ClientCount(); !errors.Is(err, client.ErrNotImpl) { // not advertised
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrNotImpl)
+		}
+	})
+}
+
+func TestStationPush(t *testing.T) {
+	s := fakecs.New()
+	pushCh := make(chan client.Msg, 1)
+	c := newClient(t, s, func(msg client.Msg, err error) {
+		if err == nil {
+			pushCh <- msg
+		}
+	})
+	if _, err := c.Temp(); err != nil { // wait for connection
+		t.Fatal(err)
+	}
+
+	if err := s.SetInput(5, true); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-pushCh:
+		if msg, ok := msg.(*client.IOIEMsg); !ok || msg.GPIO != 5 || !msg.State {
+			t.Fatalf("invalid push message %v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("push message timeout")
+	}
+	if v, err := c.IOVal(client.IOCmdLocal, 5); err != nil || !v {
+		t.Fatalf("invalid gpio value %t error %v", v, err)
+	}
+}
+
+// Example shows how to develop a client application against the fake command station.
+func Example() {
+	cs := fakecs.New()
+
+	client := client.New(cs.Conn(), nil)
+	defer client.Close()
+
+	if _, err := client.SetLocoSpeed128(3, 42); err != nil {
+		log.Fatal(err)
+	}
+	speed, err := client.LocoSpeed128(3)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("loco 3 speed %d", speed)
+
+	// output: loco 3 speed 42
+}
@@ -0,0 +1,61 @@
+package client_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+	"github.com/pico-cs/go-client/client/fakecs"
+)
+
+func TestLocoFctGroup(t *testing.T) {
+	cs := fakecs.New()
+	var cmds []string
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		if cmd == "lf" && len(args) == 3 {
+			cmds = append(cmds, strings.Join(append([]string{cmd}, args...), " "))
+		}
+		return cs.Handle(cmd, args)
+	}, nil, client.WithCache())
+
+	tests := []struct {
+		name     string
+		mask     uint32
+		values   uint32
+		expected []string
+		group    uint32
+	}{
+		{"Masked", 0b1011, 0b1110, []string{"lf 3 0 f", "lf 3 1 t", "lf 3 3 t"}, 0b1010},
+		{"ValuesOutsideMask", 0b0100, 0xffffffff, []string{"lf 3 2 t"}, 0b1110},
+		{"HighFunctions", 1<<31 | 1<<16, 1 << 31, []string{"lf 3 16 f", "lf 3 31 t"}, 1<<31 | 0b1110},
+		{"EmptyMask", 0, 0xffffffff, nil, 1<<31 | 0b1110},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmds = nil
+			if err := c.SetLocoFctGroup(3, test.mask, test.values); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(cmds, test.expected) {
+				t.Fatalf("invalid commands %v - expected %v", cmds, test.expected)
+			}
+			group, err := c.LocoFctGroup(3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if group != test.group {
+				t.Fatalf("invalid function group %032b - expected %032b", group, test.group)
+			}
+		})
+	}
+
+	if fct, ok := c.CachedLocoFct(3, 31); !ok || !fct {
+		t.Fatalf("invalid cached function %t ok %t", fct, ok)
+	}
+
+	if err := c.SetLocoFctGroup(0, 0b11, 0b11); !errors.Is(err, client.ErrInvPrm) { // invalid address
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrInvPrm)
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FunctionMap maps function names (like "headlight" or "horn") to loco function numbers.
+type FunctionMap map[string]uint
+
+// Map maps the function name to the function number.
+func (m FunctionMap) Map(name string, no uint) { m[name] = no }
+
+// No returns the function number of name and true, or false if the name is not mapped.
+func (m FunctionMap) No(name string) (uint, bool) {
+	no, ok := m[name]
+	return no, ok
+}
+
+// ParseFunctionMap parses a function map from a configuration with one name=number entry per line.
+// Empty lines and lines starting with '#' are ignored.
+func ParseFunctionMap(r io.Reader) (FunctionMap, error) {
+	m := FunctionMap{}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("parse function map error - line %d: missing '='", lineNo)
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("parse function map error - line %d: missing name", lineNo)
+		}
+		no, err := parseUint(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("parse function map error - line %d: %w", lineNo, err)
+		}
+		m[name] = no
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// String returns the function map in the configuration format read by ParseFunctionMap.
+func (m FunctionMap) String() string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.FormatUint(uint64(m[name]), 10))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package client_test
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+const fctMapConfig = `
+# BR 218 sound decoder
+headlight = 0
+bell=1
+horn = 2
+`
+
+func TestParseFunctionMap(t *testing.T) {
+	m, err := client.ParseFunctionMap(strings.NewReader(fctMapConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := client.FunctionMap{"headlight": 0, "bell": 1, "horn": 2}
+	if !maps.Equal(m, expected) {
+		t.Fatalf("invalid function map %v - expected %v", m, expected)
+	}
+
+	// round trip
+	m2, err := client.ParseFunctionMap(strings.NewReader(m.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(m2, expected) {
+		t.Fatalf("invalid function map %v - expected %v", m2, expected)
+	}
+
+	for _, config := range []string{"horn", "=2", "horn=x", "horn=-1"} {
+		if _, err := client.ParseFunctionMap(strings.NewReader(config)); err == nil {
+			t.Errorf("config %q: expected error", config)
+		}
+	}
+}
+
+func TestLocoNamedFct(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	c := newTestClient(t, recordStation(&mu, &cmds, locoStation), nil)
+
+	loco := client.NewLoco(c, 3, false)
+	loco.MapFunction("horn", 2)
+
+	if fct, err := loco.SetNamedFct("horn", true); err != nil || !fct {
+		t.Fatalf("invalid function value %t error %v", fct, err)
+	}
+	if _, err := loco.SetNamedFct("bell", true); err == nil {
+		t.Fatal("expected error on unmapped function")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{"lf 3 2 t"}
+	if !slices.Equal(cmds, expected) {
+		t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+	}
+}
@@ -0,0 +1,134 @@
+package flash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Flash file format.
+//
+// The flash file is a diffable text format:
+//
+//	line 1   : file header
+//	line 2   : read index, write index, page number and content size (decimal, space separated)
+//	line 3.. : content (hex, up to 32 space separated bytes per line)
+const (
+	fileHeader  = "pico-cs flash v1"
+	bytesPerRow = 32
+)
+
+// maxFileContentSize is the maximum content size accepted by ReadFrom (the flash size of the Raspberry Pi Pico).
+const maxFileContentSize = 2 << 20
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// WriteTo writes the flash header and content to w in the flash file format.
+// WriteTo implements the io.WriterTo interface.
+func (f *Flash) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	fmt.Fprintln(bw, fileHeader)
+	fmt.Fprintf(bw, "%d %d %d %d\n", f.ReadIdx, f.WriteIdx, f.PageNo, len(f.Content))
+	for i := 0; i < len(f.Content); i += bytesPerRow {
+		for j, v := range f.Content[i:min(i+bytesPerRow, len(f.Content))] {
+			if j != 0 {
+				bw.WriteByte(' ')
+			}
+			fmt.Fprintf(bw, "%02x", v)
+		}
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		return cw.n, fmt.Errorf("flash write error - %w", err)
+	}
+	return cw.n, nil
+}
+
+// ReadFrom reads a flash written by WriteTo from r.
+// An error wrapping io.ErrUnexpectedEOF is returned if the input is truncated.
+func ReadFrom(r io.Reader) (*Flash, error) {
+	scanner := bufio.NewScanner(r)
+
+	next := func(what string) (string, error) {
+		if scanner.Scan() {
+			return scanner.Text(), nil
+		}
+		if err := scanner.Err(); err != nil {
+			return "", fmt.Errorf("flash read error - %s: %w", what, err)
+		}
+		return "", fmt.Errorf("flash read error - %s: %w", what, io.ErrUnexpectedEOF)
+	}
+
+	line, err := next("file header")
+	if err != nil {
+		return nil, err
+	}
+	if line != fileHeader {
+		return nil, fmt.Errorf("flash read error - invalid file header %q - expected %q", line, fileHeader)
+	}
+
+	if line, err = next("flash header"); err != nil {
+		return nil, err
+	}
+	values := strings.Split(line, " ")
+	if len(values) != 4 {
+		return nil, fmt.Errorf("flash read error - invalid number of header values %d - expected %d", len(values), 4)
+	}
+	var header [4]uint64
+	for i, value := range values {
+		if header[i], err = strconv.ParseUint(value, 10, 0); err != nil {
+			return nil, fmt.Errorf("flash read error - header value %d: %w", i, err)
+		}
+	}
+	if header[3] > maxFileContentSize {
+		return nil, fmt.Errorf("flash read error - content size %d exceeds maximum %d", header[3], maxFileContentSize)
+	}
+	size := int(header[3])
+
+	// the content is not preallocated, as the size might not match the content
+	f := &Flash{
+		ReadIdx:  uint(header[0]),
+		WriteIdx: uint(header[1]),
+		PageNo:   uint(header[2]),
+		Content:  []byte{},
+	}
+	for row := 0; len(f.Content) < size; row++ {
+		if line, err = next(fmt.Sprintf("content row %d", row)); err != nil {
+			return nil, err
+		}
+		for col, value := range strings.Split(line, " ") {
+			if len(value) != 2 { // a truncated last byte would be parsed as valid single digit value
+				return nil, fmt.Errorf("flash read error - content row %d column %d: invalid value %q", row, col, value)
+			}
+			u64, err := strconv.ParseUint(value, 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("flash read error - content row %d column %d: %w", row, col, err)
+			}
+			f.Content = append(f.Content, byte(u64))
+		}
+		if len(f.Content) > size {
+			return nil, fmt.Errorf("flash read error - content exceeds size %d", size)
+		}
+	}
+	if scanner.Scan() {
+		return nil, errors.New("flash read error - unexpected data after content")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("flash read error - %w", err)
+	}
+	return f, nil
+}
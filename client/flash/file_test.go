@@ -0,0 +1,84 @@
+package flash_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pico-cs/go-client/client/flash"
+)
+
+func TestFileRoundTrip(t *testing.T) {
+	var content []byte
+	content = append(content, page(0, 17, 2, 2, 2, 26, 12)...)
+	content = append(content, page(1, 20, 3, 2, 2, 26, 12)...)
+	content = append(content, 1, 2, 3) // partial last row
+
+	f := &flash.Flash{ReadIdx: 1, WriteIdx: 2, PageNo: 1, Content: content}
+
+	var buf bytes.Buffer
+	n, err := f.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("invalid number of bytes written %d - expected %d", n, buf.Len())
+	}
+
+	rf, err := flash.ReadFrom(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rf.ReadIdx != f.ReadIdx || rf.WriteIdx != f.WriteIdx || rf.PageNo != f.PageNo || !bytes.Equal(rf.Content, f.Content) {
+		t.Fatalf("invalid flash %s - expected %s", rf, f)
+	}
+}
+
+func TestReadFromError(t *testing.T) {
+	f := &flash.Flash{PageNo: 0, Content: page(0, 17, 2, 2, 2, 26, 12)}
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+
+	t.Run("Truncated", func(t *testing.T) {
+		// truncated at line ends
+		for i, b := range []byte(data[:len(data)-1]) {
+			if b != '\n' {
+				continue
+			}
+			if _, err := flash.ReadFrom(strings.NewReader(data[:i+1])); !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("%d bytes: invalid error %v - expected %v", i+1, err, io.ErrUnexpectedEOF)
+			}
+		}
+		// truncated within a line
+		for _, n := range []int{5, len(data) / 2, len(data) - 2} {
+			if _, err := flash.ReadFrom(strings.NewReader(data[:n])); err == nil {
+				t.Fatalf("%d bytes: expected error", n)
+			}
+		}
+	})
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"InvalidHeader", strings.Replace(data, "v1", "v0", 1)},
+		{"InvalidHeaderValues", strings.Replace(data, "0 0 0 256", "0 0 256", 1)},
+		{"InvalidSize", strings.Replace(data, "0 0 0 256", "0 0 0 18446744073709551615", 1)},
+		{"NegativeSize", strings.Replace(data, "0 0 0 256", "0 0 0 -1", 1)},
+		{"ExceedingSize", strings.Replace(data, "0 0 0 256", "0 0 0 4194304", 1)},
+		{"InvalidValue", strings.Replace(data, "ff", "fg", 1)},
+		{"TrailingData", data + "ff\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := flash.ReadFrom(strings.NewReader(test.data)); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
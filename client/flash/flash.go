@@ -8,6 +8,10 @@ import (
 )
 
 // Flash represents a command station flash memory.
+//
+// Content is the raw flash content. It is neither split into records nor decoded into command station
+// CVs, as the flash layout of the firmware (including the record structure implied by ReadIdx, WriteIdx
+// and PageNo) is not documented. To verify the stored CVs use Client.StoreAndVerify.
 type Flash struct {
 	ReadIdx, WriteIdx, PageNo uint
 	Content                   []byte
@@ -31,7 +35,24 @@ func Parse(lines []string) (*Flash, error) {
 		return nil, fmt.Errorf("flash parse error - invalid number of lines %d", len(lines))
 	}
 
-	values := strings.Split(lines[0], " ")
+	flash, err := ParseHeader(lines[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// content
+	for i := 1; i < len(lines); i++ {
+		if flash.Content, err = AppendContent(flash.Content, lines[i]); err != nil {
+			return nil, fmt.Errorf("flash parse error - content line %d: %w", i, err)
+		}
+	}
+	return flash, nil
+}
+
+// ParseHeader parses the header line (read index, write index and page number) of the flash memory
+// send by a command station. The content of the returned flash is empty.
+func ParseHeader(line string) (*Flash, error) {
+	values := strings.Split(line, " ")
 	if len(values) != 3 {
 		return nil, fmt.Errorf("flash parse error - invalid number of values %d - expected %d", len(values), 3)
 	}
@@ -48,23 +69,27 @@ func Parse(lines []string) (*Flash, error) {
 	if err != nil {
 		return nil, fmt.Errorf("flash parse error - page number: %w", err)
 	}
-	flash := &Flash{
+	return &Flash{
 		ReadIdx:  uint(readIdx),
 		WriteIdx: uint(writeIdx),
 		PageNo:   uint(pageNo),
 		Content:  []byte{},
-	}
+	}, nil
+}
 
-	// content
-	for i := 1; i < len(lines); i++ {
-		values := strings.Split(strings.TrimSpace(lines[i]), " ")
-		for j, value := range values {
-			u64, err := strconv.ParseUint(value, 16, 8)
-			if err != nil {
-				return nil, fmt.Errorf("flash parse error - content line %d column %d: %w", i, j, err)
-			}
-			flash.Content = append(flash.Content, byte(u64))
+// AppendContent appends the bytes of a content line (hexadecimal values separated by blanks)
+// of the flash memory send by a command station to content and returns the extended content.
+func AppendContent(content []byte, line string) ([]byte, error) {
+	values := strings.Split(strings.TrimSpace(line), " ")
+	for j, value := range values {
+		u64, err := strconv.ParseUint(value, 16, 8)
+		if err != nil {
+			return content, fmt.Errorf("column %d: %w", j, err)
 		}
+		content = append(content, byte(u64))
 	}
-	return flash, nil
+	return content, nil
 }
+
+// PageSize is the size of a flash page.
+const PageSize = 256
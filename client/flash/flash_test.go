@@ -0,0 +1,49 @@
+package flash_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pico-cs/go-client/client/flash"
+)
+
+// dump returns the flash multi-reply lines of content (32 bytes per line).
+func dump(readIdx, writeIdx, pageNo uint, content []byte) []string {
+	lines := []string{fmt.Sprintf("%d %d %d", readIdx, writeIdx, pageNo)}
+	for i := 0; i < len(content); i += 32 {
+		var b strings.Builder
+		for j, v := range content[i:min(i+32, len(content))] {
+			if j != 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(&b, "%02x", v)
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// page returns a flash page starting with values (erased bytes 0xff otherwise).
+func page(values ...byte) []byte {
+	p := bytes.Repeat([]byte{0xff}, flash.PageSize)
+	copy(p, values)
+	return p
+}
+
+func TestParse(t *testing.T) {
+	content := append(page(1, 20, 3), page(1, 24, 3)...)
+
+	f, err := flash.Parse(dump(1, 2, 1, content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.ReadIdx != 1 || f.WriteIdx != 2 || f.PageNo != 1 || !bytes.Equal(f.Content, content) {
+		t.Fatalf("invalid flash %s", f)
+	}
+
+	if _, err := flash.Parse(nil); err == nil {
+		t.Fatal("expected error on missing header")
+	}
+}
@@ -0,0 +1,17 @@
+package flash
+
+import (
+	"encoding/hex"
+	"encoding/json"
+)
+
+// MarshalJSON implements the json.Marshaler interface.
+// The content is encoded as hex string.
+func (f Flash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ReadIdx  uint   `json:"readIdx"`
+		WriteIdx uint   `json:"writeIdx"`
+		PageNo   uint   `json:"pageNo"`
+		Content  string `json:"content"`
+	}{f.ReadIdx, f.WriteIdx, f.PageNo, hex.EncodeToString(f.Content)})
+}
@@ -0,0 +1,28 @@
+package flash_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pico-cs/go-client/client/flash"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	f := &flash.Flash{ReadIdx: 1, WriteIdx: 2, PageNo: 3, Content: []byte{0x07, 0x00, 0x11, 0xff}}
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"readIdx":1,"writeIdx":2,"pageNo":3,"content":"070011ff"}`; string(b) != expected {
+		t.Fatalf("invalid json %s - expected %s", b, expected)
+	}
+
+	// value
+	b, err = json.Marshal([]flash.Flash{*f})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `[{"readIdx":1,"writeIdx":2,"pageNo":3,"content":"070011ff"}]`; string(b) != expected {
+		t.Fatalf("invalid json %s - expected %s", b, expected)
+	}
+}
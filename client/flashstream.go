@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pico-cs/go-client/client/flash"
+)
+
+// errSyncStream is returned by the streaming commands in synchronous mode.
+var errSyncStream = errors.New("streaming not supported in synchronous mode")
+
+// errMissingFlashHeader is returned if the flash multi-reply does not contain a header line.
+var errMissingFlashHeader = errors.New("missing flash header")
+
+// flashStream assembles the flash pages of a streamed flash multi-reply.
+type flashStream struct {
+	ctx       context.Context
+	out       chan []byte
+	header    chan struct{} // closed after the header line was received
+	headerErr error         // header parse error (valid after header is closed)
+
+	mu      sync.Mutex
+	started bool   // header line received
+	page    []byte // content of the current page
+	err     error  // parse error
+	closed  bool
+}
+
+func newFlashStream(ctx context.Context) *flashStream {
+	return &flashStream{ctx: ctx, out: make(chan []byte), header: make(chan struct{})}
+}
+
+// line handles a line of the flash multi-reply (called by the connection reader).
+// A page is delivered as soon as it is complete. If the receiver is slower than the command station,
+// the connection reader waits for the receiver.
+func (s *flashStream) line(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.closed || s.err != nil: // discard
+	case !s.started:
+		s.started = true
+		_, s.err = flash.ParseHeader(line)
+		s.headerErr = s.err
+		close(s.header)
+	default:
+		if s.page, s.err = flash.AppendContent(s.page, line); s.err != nil {
+			return
+		}
+		for len(s.page) >= flash.PageSize {
+			page := slices.Clone(s.page[:flash.PageSize])
+			s.page = append(s.page[:0], s.page[flash.PageSize:]...)
+			if !s.send(page) {
+				return
+			}
+		}
+	}
+}
+
+// send delivers a page. The stream needs to be locked.
+func (s *flashStream) send(page []byte) bool {
+	select {
+	case s.out <- page:
+		return true
+	case <-s.ctx.Done():
+		s.closed = true // discard the remaining lines
+		return false
+	}
+}
+
+// finish delivers the last incomplete page (if any) and returns the parse error.
+func (s *flashStream) finish() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.page) > 0 && !s.closed {
+		s.send(slices.Clone(s.page))
+	}
+	return nil
+}
+
+// close closes the page channel, the remaining lines are discarded.
+func (s *flashStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.out)
+}
+
+// StreamFlash is the streaming variant of Flash intended for large flash dumps (like a backup written to disk
+// incrementally): instead of collecting the whole flash content, the content is delivered page by page
+// (see flash.PageSize) as it is received. The last page might be incomplete if the content size is not
+// a multiple of the page size. The header values (like the page number) are not delivered.
+//
+// Errors of the command itself (like a command station error reply, a read timeout or a malformed header)
+// are returned directly. After the header was received the channel is closed at the end of the flash content
+// or if the context is done. Errors while streaming the content (like a connection loss or a malformed
+// content line) close the channel early and are reported to the async error handler (see WithAsyncErrorHandler).
+//
+// Please note that the receiver needs to keep up with the command station, as the connection reader waits
+// for the receiver. Commands called while the content is streamed are answered after the end of the flash
+// content. StreamFlash is not supported in synchronous mode (see NewSync).
+func (c *Client) StreamFlash(ctx context.Context) (<-chan []byte, error) {
+	if c.syncMode {
+		return nil, &CallError{Cmd: cmdFlash, Err: errSyncStream}
+	}
+	if err := c.acquire(); err != nil {
+		return nil, err
+	}
+
+	s := newFlashStream(ctx)
+	p := newSyncReply(cmdFlash, nil)
+	p.stream = s.line
+
+	c.mu.Lock()
+	err := c.startStream(ctx, p, s)
+	c.mu.Unlock()
+	if err != nil {
+		s.close()
+		c.release()
+		return nil, &CallError{Cmd: cmdFlash, Err: err}
+	}
+
+	go func() {
+		defer c.release()
+		defer s.close()
+
+		var err error
+		select {
+		case reply, ok := <-p.ch:
+			if err = c.streamEnd(reply, ok); err == nil {
+				err = s.finish()
+			}
+		case <-ctx.Done():
+		}
+		if err != nil {
+			if c.logger != nil {
+				c.logger.Warn("stream flash", "error", err)
+			}
+			c.asyncError(&CallError{Cmd: cmdFlash, Err: err})
+		}
+	}()
+	return s.out, nil
+}
+
+// startStream sends the flash command and waits for the header line. The client needs to be locked.
+func (c *Client) startStream(ctx context.Context, p *pendingReply, s *flashStream) error {
+	if err := c.sendPending(p); err != nil {
+		return err
+	}
+
+	select {
+	case <-s.header:
+		// do not lock the stream: the connection reader might wait for the receiver of the first page
+		return s.headerErr
+	case reply, ok := <-p.ch:
+		select {
+		case <-s.header:
+			if ok { // flash without content: hand the end of reply over to the stream goroutine
+				p.ch <- reply // buffered: never blocks
+				return nil
+			}
+		default:
+		}
+		if _, err := c.received(reply, ok); err != nil {
+			return err
+		}
+		return errMissingFlashHeader
+	case <-time.After(c.timeout):
+		_, err := c.timedOut(p, c.timeout)
+		return err
+	case <-ctx.Done():
+		c.replies.abandon(c.timeout, p)
+		return ctx.Err()
+	}
+}
+
+// streamEnd returns the error of the end of reply of a streaming command (like received, but without
+// resetting the consecutive timeouts, as the client is not locked).
+func (c *Client) streamEnd(reply any, ok bool) error {
+	if !ok {
+		return c.lastReadErr
+	}
+	c.stats.replies.Add(1)
+	if err, ok := reply.(error); ok {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package client_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+	"github.com/pico-cs/go-client/client/flash"
+)
+
+// flashPageLines returns the flash content lines of a page filled with v.
+func flashPageLines(v byte) []string {
+	var lines []string
+	for i := 0; i < flash.PageSize; i += 32 {
+		lines = append(lines, "-"+strings.TrimSpace(strings.Repeat(fmt.Sprintf("%02x ", v), 32)))
+	}
+	return lines
+}
+
+// newFlashStreamClient returns a client connected to a station streaming a flash content of two pages.
+// The second page is sent after next is closed.
+func newFlashStreamClient(t *testing.T, next <-chan struct{}) *client.Client {
+	clientConn, stationConn := net.Pipe()
+	t.Cleanup(func() { stationConn.Close() })
+
+	writeLines := func(lines ...string) {
+		for _, line := range lines {
+			stationConn.Write([]byte(line + "\r\n")) //nolint: errcheck
+		}
+	}
+
+	go func() {
+		r := bufio.NewReader(stationConn)
+		for {
+			line, err := r.ReadString('\r')
+			if err != nil {
+				return
+			}
+			switch strings.TrimSuffix(strings.TrimPrefix(line, "+"), "\r") {
+			case "f":
+				writeLines("-0 0 1")
+				writeLines(flashPageLines(0)...)
+				<-next
+				writeLines(flashPageLines(1)...)
+				writeLines(".")
+			case "t":
+				writeLines("=27.5")
+			default:
+				writeLines("?invcmd")
+			}
+		}
+	}()
+
+	c := client.New(&pipeConn{Conn: clientConn}, nil)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestStreamFlash(t *testing.T) {
+
+	receive := func(t *testing.T, ch <-chan []byte) ([]byte, bool) {
+		select {
+		case page, ok := <-ch:
+			return page, ok
+		case <-time.After(5 * time.Second):
+			t.Fatal("page not received")
+			return nil, false
+		}
+	}
+
+	checkTemp := func(t *testing.T, c *client.Client) {
+		temp, err := c.Temp()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if temp != 27.5 {
+			t.Fatalf("invalid temperature %f - expected %f", temp, 27.5)
+		}
+	}
+
+	t.Run("Pages", func(t *testing.T) {
+		next := make(chan struct{})
+		c := newFlashStreamClient(t, next)
+
+		ch, err := c.StreamFlash(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// first page is delivered before the second page is sent
+		for i := range 2 {
+			page, ok := receive(t, ch)
+			if !ok {
+				t.Fatalf("missing page %d", i)
+			}
+			if expected := bytes.Repeat([]byte{byte(i)}, flash.PageSize); !bytes.Equal(page, expected) {
+				t.Fatalf("invalid page %d content %v - expected %v", i, page, expected)
+			}
+			if i == 0 {
+				close(next)
+			}
+		}
+		if _, ok := receive(t, ch); ok {
+			t.Fatal("channel not closed at end of flash content")
+		}
+		checkTemp(t, c)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		next := make(chan struct{})
+		c := newFlashStreamClient(t, next)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := c.StreamFlash(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := receive(t, ch); !ok {
+			t.Fatal("missing first page")
+		}
+
+		cancel()
+		if _, ok := receive(t, ch); ok {
+			t.Fatal("channel not closed after cancellation")
+		}
+
+		// the remaining content is discarded and does not affect subsequent commands
+		close(next)
+		checkTemp(t, c)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		conn := client.NewMockConn()
+		conn.Reply("f", "?notimpl")
+		c := client.New(conn, nil)
+		defer c.Close()
+
+		if _, err := c.StreamFlash(context.Background()); !errors.Is(err, client.ErrNotImpl) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrNotImpl)
+		}
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		c := client.NewSync(client.NewMockConn())
+		defer c.Close()
+
+		if _, err := c.StreamFlash(context.Background()); err == nil {
+			t.Fatal("missing error in synchronous mode")
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package client
+
+import "reflect"
+
+// formatters maps argument types to their formatters (see WithFormatter).
+type formatters map[reflect.Type]func(any) string
+
+// WithFormatter registers a command argument formatter for type T, so that values
+// of domain specific types (like a loco address type) can be used as command arguments
+// without converting them first (see Raw).
+// A registered formatter takes precedence over the built-in formatting of bool and integer kinds.
+// Registering a formatter for a type again replaces the previous formatter.
+// The formatter is applied to the commands of the client only.
+func WithFormatter[T any](fn func(v T) string) Option {
+	return func(c *Client) {
+		if c.formatters == nil {
+			c.formatters = formatters{}
+		}
+		c.formatters[reflect.TypeFor[T]()] = func(v any) string { return fn(v.(T)) }
+	}
+}
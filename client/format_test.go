@@ -0,0 +1,65 @@
+package client
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testLocoAddr uint16
+
+func TestWithFormatter(t *testing.T) {
+	conn := NewMockConn()
+	conn.Reply("ls 3 40", "=40")
+	conn.Reply("at 10 1 5", "=t")
+	c := NewClient(conn,
+		WithFormatter(func(addr testLocoAddr) string { return strconv.Itoa(int(addr)) }),
+		WithFormatter(func(d time.Duration) string { return strconv.Itoa(int(d / AccTimeUnit)) }),
+	)
+	defer c.Close()
+
+	if _, err := c.singleReply(cmdLocoSpeed128, testLocoAddr(3), uint(40)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.singleReply(cmdAccTime, uint(10), byte(1), 500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if written, expected := conn.Written(), []string{"ls 3 40", "at 10 1 5"}; !slices.Equal(written, expected) {
+		t.Fatalf("invalid written commands %v - expected %v", written, expected)
+	}
+
+	// the formatters are not applied to other clients
+	other := NewClient(NewMockConn())
+	defer other.Close()
+	if _, err := other.Raw(cmdLocoSpeed128, testLocoAddr(3), uint(40)); err == nil {
+		t.Fatal("expected invalid argument error")
+	}
+}
+
+func TestFormatToggle(t *testing.T) {
+	tests := []struct {
+		args     []any
+		expected string
+	}{
+		{[]any{uint(3), Toggle{}}, "+ld 3 ~"},
+		{[]any{uint(3), true}, "+ld 3 t"},
+		{[]any{uint(3), false}, "+ld 3 f"},
+	}
+	for _, test := range tests {
+		var b strings.Builder
+		formatCmd(&b, nil, cmdLocoDir, test.args)
+		if b.String() != test.expected {
+			t.Errorf("invalid command %q - expected %q", b.String(), test.expected)
+		}
+	}
+
+	// string arguments are not supported (no accidental toggle by passing "~")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on string argument")
+		}
+	}()
+	formatCmd(&strings.Builder{}, nil, cmdLocoDir, []any{uint(3), "~"})
+}
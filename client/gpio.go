@@ -0,0 +1,56 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GPIOConfig represents the configuration of a GPIO (see ConfigureGPIO).
+type GPIOConfig struct {
+	GPIO  uint
+	Out   bool  // direction (false: in, true: out)
+	Up    bool  // pull-up
+	Down  bool  // pull-down
+	Value *bool // initial value of an output GPIO (nil: value is not set)
+}
+
+// ConfigureGPIO configures the GPIOs in a single batch (see Batch).
+// For each GPIO the pull-up and pull-down status, the initial value (output GPIOs only) and finally
+// the direction is set, so that an output GPIO is driven with the initial value right away.
+// The configurations are validated before any command is sent: an initial value of an input GPIO
+// is an error. The returned error combines the errors of all GPIOs.
+func (c *Client) ConfigureGPIO(cmd uint, pins []GPIOConfig) error {
+	var errs []error
+	for _, pin := range pins {
+		if !pin.Out && pin.Value != nil {
+			errs = append(errs, fmt.Errorf("gpio %d: initial value of input gpio", pin.GPIO))
+		}
+	}
+	if errs != nil {
+		return errors.Join(errs...)
+	}
+
+	b := c.Batch()
+	var gpios []uint // gpio of batch command
+	for _, pin := range pins {
+		b.SetIOUp(cmd, pin.GPIO, pin.Up).SetIODown(cmd, pin.GPIO, pin.Down)
+		gpios = append(gpios, pin.GPIO, pin.GPIO)
+		if pin.Value != nil {
+			b.SetIOVal(cmd, pin.GPIO, *pin.Value)
+			gpios = append(gpios, pin.GPIO)
+		}
+		b.SetIODir(cmd, pin.GPIO, pin.Out)
+		gpios = append(gpios, pin.GPIO)
+	}
+
+	results, err := b.Run()
+	if len(results) != len(gpios) { // connection error or timeout
+		return err
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("gpio %d %s: %w", gpios[i], result.Cmd, result.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
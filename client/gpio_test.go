@@ -0,0 +1,82 @@
+package client_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestConfigureGPIO(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	c := newTestClient(t, recordStation(&mu, &cmds, func(cmd string, args []string) []string {
+		if args[1] == "23" { // internal GPIO
+			return []string{"?invgpio"}
+		}
+		return ioStation(cmd, args)
+	}), nil)
+
+	on := true
+
+	t.Run("Sequence", func(t *testing.T) {
+		pins := []client.GPIOConfig{
+			{GPIO: 2, Up: true},
+			{GPIO: 25, Out: true, Value: &on},
+			{GPIO: 3, Out: true},
+		}
+		if err := c.ConfigureGPIO(client.IOCmdLocal, pins); err != nil {
+			t.Fatal(err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		expected := []string{
+			"ioup 0 2 t", "iodown 0 2 f", "iodir 0 2 f",
+			"ioup 0 25 f", "iodown 0 25 f", "ioval 0 25 t", "iodir 0 25 t",
+			"ioup 0 3 f", "iodown 0 3 f", "iodir 0 3 t",
+		}
+		if !slices.Equal(cmds, expected) {
+			t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+		}
+		cmds = nil
+	})
+
+	t.Run("InputValue", func(t *testing.T) {
+		err := c.ConfigureGPIO(client.IOCmdLocal, []client.GPIOConfig{{GPIO: 2, Value: &on}})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(cmds) != 0 {
+			t.Fatalf("unexpected commands %v", cmds)
+		}
+	})
+
+	t.Run("PinError", func(t *testing.T) {
+		err := c.ConfigureGPIO(client.IOCmdLocal, []client.GPIOConfig{{GPIO: 2}, {GPIO: 23}})
+		if !errors.Is(err, client.ErrInvGPIO) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrInvGPIO)
+		}
+		if !strings.Contains(err.Error(), "gpio 23") || strings.Contains(err.Error(), "gpio 2 ") {
+			t.Fatalf("error %q does not report failing gpio only", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(cmds) != 6 {
+			t.Fatalf("invalid number of commands %d - expected %d", len(cmds), 6)
+		}
+		cmds = nil
+	})
+
+	t.Run("InvalidCmd", func(t *testing.T) {
+		if err := c.ConfigureGPIO(1, []client.GPIOConfig{{GPIO: 2}}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
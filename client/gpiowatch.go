@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// gpioWatcher receives the input event states of a GPIO.
+type gpioWatcher struct {
+	gpio uint
+	in   chan bool // latest unprocessed state
+}
+
+// gpioWatchers dispatches the IOIE push messages to the GPIO watchers.
+type gpioWatchers struct {
+	mu       sync.Mutex
+	closed   bool
+	watchers map[*gpioWatcher]bool
+}
+
+func (ws *gpioWatchers) add(w *gpioWatcher) bool {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.closed {
+		return false
+	}
+	if ws.watchers == nil {
+		ws.watchers = map[*gpioWatcher]bool{}
+	}
+	ws.watchers[w] = true
+	return true
+}
+
+func (ws *gpioWatchers) remove(w *gpioWatcher) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	delete(ws.watchers, w)
+}
+
+// dispatch sends the state to the watchers of the GPIO. A state not yet processed by a watcher
+// is replaced, so that dispatch does not block the push message handling.
+func (ws *gpioWatchers) dispatch(msg *IOIEMsg) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for w := range ws.watchers {
+		if w.gpio != msg.GPIO {
+			continue
+		}
+		for sent := false; !sent; {
+			select {
+			case w.in <- msg.State:
+				sent = true
+			default:
+				select {
+				case <-w.in:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// close ends all watchers.
+func (ws *gpioWatchers) close() {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.closed = true
+	for w := range ws.watchers {
+		close(w.in)
+	}
+	clear(ws.watchers)
+}
+
+// WatchGPIO returns a channel delivering the debounced state transitions of the input GPIO
+// reported by the command station as IOIE push messages (see IOIEMsg).
+// A state is delivered after it was stable for the debounce interval, so that state changes
+// faster than the interval (like switch bounce) are suppressed. Only changes of the settled state
+// are delivered, the first settled state is always delivered.
+// The channel is closed when the context is done or the client is closed.
+// The IOIE push messages are still reported to the push message handler.
+func (c *Client) WatchGPIO(ctx context.Context, gpio uint, debounce time.Duration) <-chan bool {
+	out := make(chan bool, 1)
+	w := &gpioWatcher{gpio: gpio, in: make(chan bool, 1)}
+	if !c.gpioWatchers.add(w) {
+		close(out)
+		return out
+	}
+
+	go func() {
+		defer close(out)
+		defer c.gpioWatchers.remove(w)
+
+		var settle <-chan time.Time
+		var pending, last, settled bool
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case state, ok := <-w.in:
+				if !ok {
+					return
+				}
+				pending = state
+				settle = time.After(debounce)
+			case <-settle:
+				settle = nil
+				if settled && pending == last {
+					continue
+				}
+				select {
+				case out <- pending:
+				case <-ctx.Done():
+					return
+				}
+				last, settled = pending, true
+			}
+		}
+	}()
+	return out
+}
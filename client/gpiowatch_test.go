@@ -0,0 +1,77 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestWatchGPIO(t *testing.T) {
+	const debounce = 50 * time.Millisecond
+
+	conn := client.NewMockConn()
+	c := client.New(conn, nil)
+
+	ctx := context.Background()
+	ch5 := c.WatchGPIO(ctx, 5, debounce)
+	ch6 := c.WatchGPIO(ctx, 6, debounce)
+
+	bounce := func(gpio uint, states ...bool) {
+		for _, state := range states {
+			conn.Push(fmt.Sprintf("ioie: %d %c", gpio, map[bool]byte{true: 't', false: 'f'}[state]))
+		}
+	}
+	expect := func(ch <-chan bool, state bool) {
+		t.Helper()
+		select {
+		case v := <-ch:
+			if v != state {
+				t.Fatalf("invalid state %t - expected %t", v, state)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	expectNone := func(ch <-chan bool) {
+		t.Helper()
+		select {
+		case v := <-ch:
+			t.Fatalf("unexpected state %t", v)
+		case <-time.After(3 * debounce):
+		}
+	}
+
+	bounce(5, true, false, true, false, true)
+	bounce(6, false)
+	expect(ch5, true)
+	expect(ch6, false)
+
+	bounce(5, false, true) // settles in unchanged state
+	expectNone(ch5)
+
+	bounce(5, false, true, false)
+	expect(ch5, false)
+	expectNone(ch6)
+
+	// cancelled watcher
+	cctx, cancel := context.WithCancel(ctx)
+	ch := c.WatchGPIO(cctx, 5, debounce)
+	cancel()
+	for range ch {
+	}
+
+	c.Close()
+	for _, ch := range []<-chan bool{ch5, ch6, c.WatchGPIO(ctx, 5, debounce)} {
+		select {
+		case _, ok := <-ch:
+			if ok {
+				t.Fatal("channel not closed")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package client_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestHasCommand(t *testing.T) {
+	var numHelp atomic.Int32
+
+	conn := client.NewMockConn()
+	conn.HandleFunc(func(cmd string, args []string) []string {
+		if cmd != "h" {
+			return []string{"?invcmd"}
+		}
+		numHelp.Add(1)
+		return []string{
+			"-h: help",
+			"-b: board info",
+			"-cv <idx> [<value>]: command station cv",
+			"-ff: format flash",
+			"-", // empty line
+			"-no command syntax",
+			".",
+		}
+	})
+	c := client.New(conn, nil)
+	defer c.Close()
+
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"h", true},
+		{"b", true},
+		{"cv", true},
+		{"ff", true},
+		{"pcvbyte", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		ok, err := c.HasCommand(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != test.ok {
+			t.Errorf("command %q: invalid result %t - expected %t", test.name, ok, test.ok)
+		}
+	}
+	if n := numHelp.Load(); n != 1 {
+		t.Fatalf("invalid number of help calls %d - expected %d", n, 1)
+	}
+
+	// reconnect invalidates the command list
+	if err := c.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.HasCommand("b"); err != nil {
+		t.Fatal(err)
+	}
+	if n := numHelp.Load(); n != 2 {
+		t.Fatalf("invalid number of help calls %d - expected %d", n, 2)
+	}
+}
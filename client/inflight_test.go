@@ -0,0 +1,100 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// blockingStation returns a test station handler blocking the temperature command until release is closed.
+func blockingStation(entered chan<- struct{}, release <-chan struct{}) stationHandler {
+	station := mockStation()
+	return func(cmd string, args []string) []string {
+		if cmd == "t" {
+			entered <- struct{}{}
+			<-release
+		}
+		return station(cmd, args)
+	}
+}
+
+func TestMaxInFlightBusy(t *testing.T) {
+	entered, release := make(chan struct{}, 1), make(chan struct{})
+	c := newTestClient(t, blockingStation(entered, release), nil, client.WithMaxInFlight(1, true))
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Temp()
+		done <- err
+	}()
+	<-entered
+
+	if _, err := c.Help(); !errors.Is(err, client.ErrBusy) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrBusy)
+	}
+	if _, err := c.Batch().SetCV(client.CVNumRepeat, 5).Run(); !errors.Is(err, client.ErrBusy) {
+		t.Fatalf("invalid batch error %v - expected %v", err, client.ErrBusy)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Help(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxInFlightWait(t *testing.T) {
+	entered, release := make(chan struct{}, 1), make(chan struct{})
+	c := newTestClient(t, blockingStation(entered, release), nil, client.WithMaxInFlight(1, false))
+
+	done := make(chan error, 2)
+	go func() {
+		_, err := c.Temp()
+		done <- err
+	}()
+	<-entered
+
+	go func() {
+		_, err := c.Help()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("command not blocked by in-flight limit (error %v)", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMaxInFlightBatch(t *testing.T) {
+	c := newTestClient(t, mockStation(), nil, client.WithMaxInFlight(2, false))
+
+	b := c.Batch()
+	for i := uint(1); i <= 5; i++ {
+		b.SetLocoSpeed128(i, 10*i)
+	}
+	results, err := b.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("invalid number of results %d - expected %d", len(results), 5)
+	}
+	for i, result := range results {
+		if result.Reply != fmt.Sprint(10*(i+1)) {
+			t.Fatalf("invalid result %d %v", i, result)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package client_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+	"github.com/pico-cs/go-client/client/fakecs"
+)
+
+// ioStation is a test station handler accepting IO commands of the local GPIOs.
+func ioStation(cmd string, args []string) []string {
+	switch cmd {
+	case "ioval", "iodir", "ioup", "iodown":
+		if args[0] != "0" { // io command value not supported
+			return []string{"?invprm"}
+		}
+		if len(args) == 3 {
+			return []string{"=" + args[2]}
+		}
+		return []string{"=f"}
+	}
+	return []string{"?invcmd"}
+}
+
+func TestIOCmd(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	c := newTestClient(t, recordStation(&mu, &cmds, ioStation), nil)
+
+	if _, err := c.SetIOVal(client.IOCmdLocal, 25, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// io command values not supported by the command station are sent and rejected by the command station
+	const unsupportedCmd = 1
+	tests := []struct {
+		name string
+		fct  func() (bool, error)
+		cmd  string
+	}{
+		{"IOVal", func() (bool, error) { return c.IOVal(unsupportedCmd, 25) }, "ioval 1 25"},
+		{"SetIOVal", func() (bool, error) { return c.SetIOVal(unsupportedCmd, 25, true) }, "ioval 1 25 t"},
+		{"ToggleIOVal", func() (bool, error) { return c.ToggleIOVal(unsupportedCmd, 25) }, "ioval 1 25 ~"},
+		{"IODir", func() (bool, error) { return c.IODir(unsupportedCmd, 25) }, "iodir 1 25"},
+		{"SetIODir", func() (bool, error) { return c.SetIODir(unsupportedCmd, 25, true) }, "iodir 1 25 t"},
+		{"ToggleIODir", func() (bool, error) { return c.ToggleIODir(unsupportedCmd, 25) }, "iodir 1 25 ~"},
+		{"IOUp", func() (bool, error) { return c.IOUp(unsupportedCmd, 25) }, "ioup 1 25"},
+		{"SetIOUp", func() (bool, error) { return c.SetIOUp(unsupportedCmd, 25, true) }, "ioup 1 25 t"},
+		{"ToggleIOUp", func() (bool, error) { return c.ToggleIOUp(unsupportedCmd, 25) }, "ioup 1 25 ~"},
+		{"IODown", func() (bool, error) { return c.IODown(unsupportedCmd, 25) }, "iodown 1 25"},
+		{"SetIODown", func() (bool, error) { return c.SetIODown(unsupportedCmd, 25, true) }, "iodown 1 25 t"},
+		{"ToggleIODown", func() (bool, error) { return c.ToggleIODown(unsupportedCmd, 25) }, "iodown 1 25 ~"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.fct(); !errors.Is(err, client.ErrInvPrm) {
+				t.Fatalf("invalid error %v - expected %v", err, client.ErrInvPrm)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if cmd := cmds[len(cmds)-1]; cmd != test.cmd {
+				t.Fatalf("invalid command %q - expected %q", cmd, test.cmd)
+			}
+		})
+	}
+
+	results, err := c.Batch().SetIOVal(client.IOCmdLocal, 25, true).SetIODir(unsupportedCmd, 25, true).Run()
+	if !errors.Is(err, client.ErrInvPrm) {
+		t.Fatalf("invalid batch error %v - expected %v", err, client.ErrInvPrm)
+	}
+	if len(results) != 2 || results[0].Err != nil {
+		t.Fatalf("invalid batch results %v", results)
+	}
+}
+
+func TestIOValMask(t *testing.T) {
+	cs := fakecs.New()
+	c := newTestClient(t, cs.Handle, nil)
+
+	const (
+		out1, out2 = 2, 5 // output GPIOs
+		in         = 9    // input GPIO
+		led        = 25   // on-board LED (pico)
+		internal   = 23   // not available
+		outMask    = 1<<out1 | 1<<out2
+	)
+
+	for _, gpio := range []uint{out1, out2, led} {
+		if _, err := c.SetIODir(client.IOCmdLocal, gpio, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.SetIOVal(client.IOCmdLocal, in, true); err != nil { // like a pressed button
+		t.Fatal(err)
+	}
+
+	// invalid GPIOs: no value is set
+	if err := c.SetIOValMask(client.IOCmdLocal, outMask|1<<in, outMask); err == nil {
+		t.Fatal("missing input gpio error")
+	}
+	if err := c.SetIOValMask(client.IOCmdLocal, outMask|1<<internal, outMask); err == nil {
+		t.Fatal("missing not available gpio error")
+	}
+
+	if err := c.SetIOValMask(client.IOCmdLocal, outMask|1<<led, 1<<out2|1<<led); err != nil {
+		t.Fatal(err)
+	}
+
+	mask, err := c.IOValMask(client.IOCmdLocal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := uint32(1<<out2 | 1<<in | 1<<led); mask != expected {
+		t.Fatalf("invalid mask %032b - expected %032b", mask, expected)
+	}
+
+	for gpio, expected := range map[uint]bool{out1: false, out2: true, in: true, led: true} {
+		value, err := c.IOVal(client.IOCmdLocal, gpio)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != expected {
+			t.Fatalf("invalid value %t of gpio %d - expected %t", value, gpio, expected)
+		}
+	}
+}
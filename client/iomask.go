@@ -0,0 +1,131 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// boardGPIOs returns the GPIOs of the command station board (see BoardType.GPIOs).
+// The GPIOs are queried via Board on first call and cached until the client is reconnected.
+func (c *Client) boardGPIOs() ([]uint, error) {
+	c.cmdMu.Lock()
+	gpios := c.gpios
+	c.cmdMu.Unlock()
+
+	if gpios == nil {
+		board, err := c.Board()
+		if err != nil {
+			return nil, err
+		}
+		if gpios = board.Type.GPIOs(); len(gpios) == 0 {
+			return nil, fmt.Errorf("no gpios of board type %s", board.Type)
+		}
+		c.cmdMu.Lock()
+		c.gpios = gpios
+		c.cmdMu.Unlock()
+	}
+	return gpios, nil
+}
+
+// maskGPIOs returns the GPIOs of the mask bits (bit n: GPIO n). An error is returned
+// for GPIOs not available on the command station board.
+func (c *Client) maskGPIOs(mask uint32) ([]uint, error) {
+	gpios, err := c.boardGPIOs()
+	if err != nil {
+		return nil, err
+	}
+	var maskGPIOs []uint
+	var errs []error
+	for gpio := uint(0); gpio < 32; gpio++ {
+		if mask&(1<<gpio) == 0 {
+			continue
+		}
+		if !slices.Contains(gpios, gpio) {
+			errs = append(errs, fmt.Errorf("gpio %d: not available", gpio))
+			continue
+		}
+		maskGPIOs = append(maskGPIOs, gpio)
+	}
+	return maskGPIOs, errors.Join(errs...)
+}
+
+// ioBools pipelines the io command name for the GPIOs and returns the boolean replies.
+func (c *Client) ioBools(name string, cmd uint, gpios []uint, args ...any) ([]bool, error) {
+	b := c.Batch()
+	for _, gpio := range gpios {
+		b.addIO(name, cmd, append([]any{gpio}, args...)...)
+	}
+	results, err := b.Run()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]bool, len(results))
+	for i, result := range results {
+		if values[i], err = parseBool(result.Reply); err != nil {
+			return nil, fmt.Errorf("batch command %d %s: %w", i, result.Cmd, err)
+		}
+	}
+	return values, nil
+}
+
+// IOValMask returns the values of all GPIOs of the command station board as bit mask (bit n: GPIO n),
+// so that a bank of GPIOs (like the buttons of a control panel) is read at once. Bits of GPIOs not available
+// on the board (see BoardType.GPIOs) are zero.
+// As the command station does not provide a bulk GPIO read, the reads of the single GPIOs are pipelined
+// (see Batch), so that the values are read within a single round trip.
+func (c *Client) IOValMask(cmd uint) (uint32, error) {
+	gpios, err := c.boardGPIOs()
+	if err != nil {
+		return 0, err
+	}
+	values, err := c.ioBools(cmdIOVal, cmd, gpios)
+	if err != nil {
+		return 0, err
+	}
+	var mask uint32
+	for i, value := range values {
+		if value {
+			mask |= 1 << gpios[i]
+		}
+	}
+	return mask, nil
+}
+
+// SetIOValMask sets the values of the GPIOs selected by mask (bit n: GPIO n) to the corresponding bits of values,
+// so that a bank of GPIOs (like the LEDs of a control panel) is set with a single call.
+// The GPIOs are validated before any value is set: all selected GPIOs need to be available on the command
+// station board and configured as output (see SetIODir), otherwise an error combining the invalid GPIOs is returned.
+// The commands setting the values are pipelined (see Batch), one command per GPIO.
+// The update is not atomic: the command station sets the GPIOs one after the other, and if a command
+// fails (or the connection is lost) the GPIOs set by the preceding commands keep their new values.
+func (c *Client) SetIOValMask(cmd uint, mask, values uint32) error {
+	gpios, err := c.maskGPIOs(mask)
+	if err != nil {
+		return err
+	}
+	if len(gpios) == 0 {
+		return nil
+	}
+
+	dirs, err := c.ioBools(cmdIODir, cmd, gpios)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, out := range dirs {
+		if !out {
+			errs = append(errs, fmt.Errorf("gpio %d: input gpio", gpios[i]))
+		}
+	}
+	if errs != nil {
+		return errors.Join(errs...)
+	}
+
+	b := c.Batch()
+	for _, gpio := range gpios {
+		b.SetIOVal(cmd, gpio, values&(1<<gpio) != 0)
+	}
+	_, err = b.Run()
+	return err
+}
@@ -0,0 +1,53 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatency(t *testing.T) {
+	const delay = 20 * time.Millisecond
+
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		if cmd == "t" {
+			time.Sleep(5 * delay)
+			return []string{"=25.5"}
+		}
+		time.Sleep(delay)
+		return []string{"=pico_w E66038B713849D31 28:cd:c1:00:00:00"}
+	}, nil)
+
+	latency, err := c.Latency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latency < delay {
+		t.Fatalf("invalid latency %s - expected >= %s", latency, delay)
+	}
+
+	t.Run("Stats", func(t *testing.T) {
+		if latency := c.Stats().Latency; latency < delay || latency >= 5*delay {
+			t.Fatalf("invalid latency estimate %s - expected >= %s and < %s", latency, delay, 5*delay)
+		}
+	})
+
+	t.Run("LockWait", func(t *testing.T) {
+		done := make(chan error, 1)
+		go func() {
+			_, err := c.Temp()
+			done <- err
+		}()
+		time.Sleep(delay) // temperature call is waiting for its reply
+
+		latency, err := c.Latency()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+		if latency >= 4*delay {
+			t.Fatalf("invalid latency %s - expected < %s (waiting for the temperature call)", latency, 4*delay)
+		}
+	})
+}
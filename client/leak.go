@@ -0,0 +1,31 @@
+package client
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+)
+
+// watchLeak sets a finalizer warning about the client being garbage collected without being closed.
+// The background goroutines only reference the client state, so that the client itself becomes
+// unreachable if it is dropped by the user.
+func (c *Client) watchLeak() {
+	if c.leakWarning {
+		runtime.SetFinalizer(c, (*Client).warnLeak)
+	}
+}
+
+// unwatchLeak removes the finalizer of a closed client.
+func (c *Client) unwatchLeak() {
+	if c.leakWarning {
+		runtime.SetFinalizer(c, nil)
+	}
+}
+
+func (c *Client) warnLeak() {
+	logger := c.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Warn("client garbage collected without Close", "conn", fmt.Sprintf("%T", c.conn))
+}
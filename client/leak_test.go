@@ -0,0 +1,87 @@
+package client_test
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// syncBuffer is a log output safe for concurrent use (like by the finalizer goroutine).
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLeakWarning(t *testing.T) {
+	const warning = "client garbage collected without Close"
+
+	// gc collects garbage until the log contains the warning or the number of rounds is exhausted.
+	gc := func(out *syncBuffer, rounds int) bool {
+		for range rounds {
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+			if strings.Contains(out.String(), warning) {
+				return true
+			}
+		}
+		return false
+	}
+
+	newClient := func(out *syncBuffer, syncMode bool) (*client.MockConn, *client.Client) {
+		conn := client.NewMockConn()
+		conn.HandleFunc(mockStation())
+		logger := slog.New(slog.NewTextHandler(out, nil))
+		if syncMode {
+			return conn, client.NewSync(conn, client.WithLogger(logger), client.WithLeakWarning())
+		}
+		return conn, client.New(conn, nil, client.WithLogger(logger), client.WithLeakWarning())
+	}
+
+	for _, syncMode := range []bool{false, true} {
+		name := map[bool]string{false: "Unclosed", true: "UnclosedSync"}[syncMode]
+		t.Run(name, func(t *testing.T) {
+			var out syncBuffer
+			conn, c := newClient(&out, syncMode)
+			defer conn.Close() // stop the leaked reader
+			if _, err := c.Temp(); err != nil {
+				t.Fatal(err)
+			}
+			c = nil // drop client without Close
+
+			if !gc(&out, 100) {
+				t.Fatalf("missing leak warning in log %q", out.String())
+			}
+		})
+	}
+
+	t.Run("Closed", func(t *testing.T) {
+		var out syncBuffer
+		_, c := newClient(&out, false)
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+		c = nil
+
+		if gc(&out, 10) {
+			t.Fatalf("unexpected leak warning in log %q", out.String())
+		}
+	})
+}
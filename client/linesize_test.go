@@ -0,0 +1,74 @@
+package client_test
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestMaxLineSize(t *testing.T) {
+	const maxLineSize = 1024
+
+	// help multi-reply with a line of size n
+	helpStation := func(n int) client.MockHandler {
+		return func(cmd string, args []string) []string {
+			return []string{"-" + strings.Repeat("x", n), "-t: temperature", "."}
+		}
+	}
+
+	newClient := func(t *testing.T, syncMode bool, handler client.MockHandler) *client.Client {
+		conn := client.NewMockConn()
+		conn.HandleFunc(handler)
+		var c *client.Client
+		if syncMode {
+			c = client.NewSync(conn, client.WithMaxLineSize(maxLineSize))
+		} else {
+			c = client.New(conn, nil, client.WithMaxLineSize(maxLineSize))
+		}
+		t.Cleanup(func() { c.Close() })
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		syncMode bool
+		n        int
+		err      error
+	}{
+		{"Fits", false, maxLineSize - 100, nil},
+		{"TooLong", false, 2 * maxLineSize, bufio.ErrTooLong},
+		{"SyncFits", true, maxLineSize - 100, nil},
+		{"SyncTooLong", true, 2 * maxLineSize, bufio.ErrTooLong},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newClient(t, test.syncMode, helpStation(test.n))
+			if c.MaxLineSize() != maxLineSize {
+				t.Fatalf("invalid max line size %d - expected %d", c.MaxLineSize(), maxLineSize)
+			}
+
+			lines, err := c.Help()
+			if !errors.Is(err, test.err) {
+				t.Fatalf("invalid error %v - expected %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+			if len(lines) != 2 || len(lines[0]) != test.n {
+				t.Fatalf("invalid help reply %d lines - expected %d lines", len(lines), 2)
+			}
+		})
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		c := client.New(client.NewMockConn(), nil)
+		defer c.Close()
+		if c.MaxLineSize() != client.DefaultMaxLineSize {
+			t.Fatalf("invalid max line size %d - expected %d", c.MaxLineSize(), client.DefaultMaxLineSize)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"fmt"
+)
+
+// Loco represents a loco handle.
+type Loco struct {
+	c        *Client
+	addr     uint
+	reversed bool
+	fcts     FunctionMap
+}
+
+// NewLoco returns a new loco handle of the loco with address addr.
+// For a reversed loco (a loco installed facing backwards on the layout) the direction
+// is inverted, so that forward direction of the handle is always forward on the layout.
+func NewLoco(c *Client, addr uint, reversed bool) *Loco {
+	return &Loco{c: c, addr: addr, reversed: reversed, fcts: FunctionMap{}}
+}
+
+// Addr returns the loco address.
+func (l *Loco) Addr() uint { return l.addr }
+
+// Reversed returns true if the loco is reversed, false otherwise.
+func (l *Loco) Reversed() bool { return l.reversed }
+
+// layoutDir converts between decoder and layout direction.
+func (l *Loco) layoutDir(dir bool, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	return dir != l.reversed, nil
+}
+
+// Dir returns the layout direction of the loco (see LocoDir).
+func (l *Loco) Dir() (bool, error) { return l.layoutDir(l.c.LocoDir(l.addr)) }
+
+// SetDir sets the layout direction of the loco (see SetLocoDir).
+func (l *Loco) SetDir(dir bool) (bool, error) {
+	return l.layoutDir(l.c.SetLocoDir(l.addr, dir != l.reversed))
+}
+
+// ToggleDir toggles the direction of the loco and returns the layout direction (see ToggleLocoDir).
+func (l *Loco) ToggleDir() (bool, error) { return l.layoutDir(l.c.ToggleLocoDir(l.addr)) }
+
+// Speed128 returns the speed of the loco (see LocoSpeed128).
+func (l *Loco) Speed128() (uint, error) { return l.c.LocoSpeed128(l.addr) }
+
+// SetSpeed128 sets the speed of the loco (see SetLocoSpeed128).
+func (l *Loco) SetSpeed128(speed uint) (uint, error) { return l.c.SetLocoSpeed128(l.addr, speed) }
+
+// Fct returns a function value of the loco (see LocoFct).
+func (l *Loco) Fct(no uint) (bool, error) { return l.c.LocoFct(l.addr, no) }
+
+// SetFct sets a function value of the loco (see SetLocoFct).
+func (l *Loco) SetFct(no uint, fct bool) (bool, error) { return l.c.SetLocoFct(l.addr, no, fct) }
+
+// ToggleFct toggles a function value of the loco (see ToggleLocoFct).
+func (l *Loco) ToggleFct(no uint) (bool, error) { return l.c.ToggleLocoFct(l.addr, no) }
+
+// FunctionMap returns the function map of the loco.
+func (l *Loco) FunctionMap() FunctionMap { return l.fcts }
+
+// SetFunctionMap sets the function map of the loco (like a per loco profile read by ParseFunctionMap).
+func (l *Loco) SetFunctionMap(m FunctionMap) { l.fcts = m }
+
+// MapFunction maps the function name to the function number.
+func (l *Loco) MapFunction(name string, no uint) { l.fcts.Map(name, no) }
+
+func (l *Loco) fctNo(name string) (uint, error) {
+	no, ok := l.fcts.No(name)
+	if !ok {
+		return 0, fmt.Errorf("loco %d: function %q not mapped", l.addr, name)
+	}
+	return no, nil
+}
+
+// NamedFct returns the value of a named function of the loco.
+func (l *Loco) NamedFct(name string) (bool, error) {
+	no, err := l.fctNo(name)
+	if err != nil {
+		return false, err
+	}
+	return l.Fct(no)
+}
+
+// SetNamedFct sets the value of a named function of the loco.
+func (l *Loco) SetNamedFct(name string, fct bool) (bool, error) {
+	no, err := l.fctNo(name)
+	if err != nil {
+		return false, err
+	}
+	return l.SetFct(no, fct)
+}
+
+// ToggleNamedFct toggles the value of a named function of the loco.
+func (l *Loco) ToggleNamedFct(name string) (bool, error) {
+	no, err := l.fctNo(name)
+	if err != nil {
+		return false, err
+	}
+	return l.ToggleFct(no)
+}
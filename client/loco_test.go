@@ -0,0 +1,71 @@
+package client_test
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// dirStation returns a test station handler keeping the decoder direction of locos.
+func dirStation(dirs map[string]bool) stationHandler {
+	return func(cmd string, args []string) []string {
+		if cmd != "ld" {
+			return []string{"?invcmd"}
+		}
+		switch {
+		case len(args) == 1:
+		case args[1] == "~":
+			dirs[args[0]] = !dirs[args[0]]
+		default:
+			dirs[args[0]] = args[1] == "t"
+		}
+		if dirs[args[0]] {
+			return []string{"=t"}
+		}
+		return []string{"=f"}
+	}
+}
+
+func TestLocoOrientation(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+	dirs := map[string]bool{}
+
+	c := newTestClient(t, recordStation(&mu, &cmds, dirStation(dirs)), nil)
+
+	tests := []struct {
+		reversed bool
+		cmds     []string
+	}{
+		{false, []string{"ld 3 t", "ld 3", "ld 3 ~"}},
+		{true, []string{"ld 3 f", "ld 3", "ld 3 ~"}},
+	}
+
+	for _, test := range tests {
+		loco := client.NewLoco(c, 3, test.reversed)
+
+		mu.Lock()
+		cmds = nil
+		mu.Unlock()
+
+		if dir, err := loco.SetDir(true); err != nil || !dir {
+			t.Fatalf("reversed %t: invalid direction %t error %v", test.reversed, dir, err)
+		}
+		if dir, err := loco.Dir(); err != nil || !dir {
+			t.Fatalf("reversed %t: invalid direction %t error %v", test.reversed, dir, err)
+		}
+		if dir, err := loco.ToggleDir(); err != nil || dir {
+			t.Fatalf("reversed %t: invalid toggled direction %t error %v", test.reversed, dir, err)
+		}
+		mu.Lock()
+		if decoderDir := dirs["3"]; decoderDir != test.reversed {
+			t.Fatalf("reversed %t: invalid decoder direction %t", test.reversed, decoderDir)
+		}
+		if !slices.Equal(cmds, test.cmds) {
+			t.Fatalf("reversed %t: invalid commands %v - expected %v", test.reversed, cmds, test.cmds)
+		}
+		mu.Unlock()
+	}
+}
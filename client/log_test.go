@@ -0,0 +1,85 @@
+package client_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestReconnectLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	conn := client.NewMockConn()
+	conn.HandleFunc(mockStation())
+	c := client.New(conn, nil, client.WithLogger(logger))
+	defer c.Close()
+
+	conn.Disconnect(io.ErrUnexpectedEOF)
+	if err := c.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+
+	var entry struct {
+		Level        string `json:"level"`
+		Msg          string `json:"msg"`
+		Disconnected int64  `json:"disconnected"` // nanoseconds
+		Attempts     int    `json:"attempts"`
+		OK           bool   `json:"ok"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid log entry %s - %s", buf.Bytes(), err)
+	}
+	if entry.Level != "INFO" || entry.Msg != "reconnect" || entry.Attempts != 1 || !entry.OK || entry.Disconnected <= 0 {
+		t.Fatalf("invalid log entry %s", buf.Bytes())
+	}
+}
+
+func TestProtocolLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	conn := client.NewMockConn()
+	conn.HandleFunc(mockStation())
+	pushCh := make(chan error, 1)
+	c := client.New(conn, func(msg client.Msg, err error) { pushCh <- err }, client.WithLogger(logger))
+
+	if _, err := c.Temp(); err != nil {
+		t.Fatal(err)
+	}
+	conn.Push("ioie: x t") // invalid push message
+	if err := <-pushCh; err == nil {
+		t.Fatal("expected push message parse error")
+	}
+	c.Close()
+
+	type entry struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+		Line  string `json:"line"`
+	}
+	var entries []entry
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e entry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+
+	expected := []entry{
+		{"DEBUG", "write", "+t"},
+		{"DEBUG", "read", "=27.5"},
+		{"DEBUG", "read", "!ioie: x t"},
+		{"WARN", "parse push message", "ioie: x t"},
+	}
+	if !slices.Equal(entries, expected) {
+		t.Fatalf("invalid log entries %v - expected %v", entries, expected)
+	}
+}
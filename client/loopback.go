@@ -0,0 +1,126 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoopbackConn is an in-memory connection to a command station server (like LoopbackServer)
+// running in the same process. In contrast to MockConn the commands and replies are transferred
+// as byte stream, so that the complete protocol including the line framing is exercised.
+type LoopbackConn struct {
+	serve func(conn net.Conn)
+	mu    sync.Mutex
+	conn  net.Conn
+}
+
+// NewLoopbackConn returns a new connected loopback connection instance.
+// On each connect serve is called in a separate goroutine with the server side of the connection.
+func NewLoopbackConn(serve func(conn net.Conn)) *LoopbackConn {
+	c := &LoopbackConn{serve: serve}
+	c.Connect() //nolint: errcheck // in-memory connect does not fail
+	return c
+}
+
+func (c *LoopbackConn) getConn() net.Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn
+}
+
+// Connect implements the Conn interface.
+func (c *LoopbackConn) Connect() error {
+	client, server := net.Pipe()
+	c.mu.Lock()
+	c.conn = client
+	c.mu.Unlock()
+	go c.serve(server)
+	return nil
+}
+
+// Read implements the Conn interface.
+func (c *LoopbackConn) Read(p []byte) (n int, err error) { return c.getConn().Read(p) }
+
+// Write implements the Conn interface.
+func (c *LoopbackConn) Write(p []byte) (n int, err error) { return c.getConn().Write(p) }
+
+// SetWriteDeadline sets the write deadline of the connection.
+func (c *LoopbackConn) SetWriteDeadline(t time.Time) error { return c.getConn().SetWriteDeadline(t) }
+
+// Close implements the Conn interface.
+func (c *LoopbackConn) Close() error { return c.getConn().Close() }
+
+// errNoLoopbackConn is returned by Push if no client is connected.
+var errNoLoopbackConn = errors.New("loopback server: no connection")
+
+// LoopbackServer is a command station server replying to the commands via a handler (see MockHandler).
+// Push messages can be sent to the connected client via Push.
+type LoopbackServer struct {
+	handler MockHandler
+	mu      sync.Mutex // serializes replies and push messages
+	conn    net.Conn   // current connection
+}
+
+// NewLoopbackServer returns a new loopback server instance.
+func NewLoopbackServer(handler MockHandler) *LoopbackServer {
+	return &LoopbackServer{handler: handler}
+}
+
+func (s *LoopbackServer) writeLines(conn net.Conn, lines ...string) error {
+	var b bytes.Buffer
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+	_, err := conn.Write(b.Bytes())
+	return err
+}
+
+// scanCR splits the command lines terminated by a carriage return.
+func scanCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexByte(data, '\r'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Serve serves the commands of a connection until the connection is closed
+// (see NewLoopbackConn).
+func (s *LoopbackServer) Serve(conn net.Conn) {
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Split(scanCR)
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimPrefix(scanner.Text(), string(tagStart)), " ")
+		lines := s.handler(fields[0], fields[1:])
+		s.mu.Lock()
+		err := s.writeLines(conn, lines...)
+		s.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Push sends a push message (like "ioie: 5 t") to the connected client.
+func (s *LoopbackServer) Push(msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return errNoLoopbackConn
+	}
+	return s.writeLines(s.conn, string(tagPush)+msg)
+}
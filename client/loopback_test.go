@@ -0,0 +1,83 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestLoopback(t *testing.T) {
+	srv := client.NewLoopbackServer(mockStation())
+	pushCh := make(chan client.Msg, 1)
+	c := client.New(client.NewLoopbackConn(srv.Serve), func(msg client.Msg, err error) {
+		if err == nil {
+			pushCh <- msg
+		}
+	})
+	defer c.Close()
+
+	board, err := c.Board()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.Type != client.BtPicoW {
+		t.Fatalf("invalid board type %s - expected %s", board.Type, client.BtPicoW)
+	}
+	lines, err := c.Help()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("invalid number of help lines %d - expected %d", len(lines), 2)
+	}
+	if _, err := c.Raw("x"); !errors.Is(err, client.ErrInvCmd) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrInvCmd)
+	}
+
+	if err := srv.Push("ioie: 5 t"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-pushCh:
+		if msg, ok := msg.(*client.IOIEMsg); !ok || msg.GPIO != 5 || !msg.State {
+			t.Fatalf("invalid push message %v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("push message timeout")
+	}
+
+	// reconnect serves a new connection
+	if err := c.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Temp(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ExampleNewLoopbackConn shows how to test a client against an in-memory command station.
+func ExampleNewLoopbackConn() {
+	srv := client.NewLoopbackServer(func(cmd string, args []string) []string {
+		switch cmd {
+		case "t":
+			return []string{"=27.5"}
+		default:
+			return []string{"?invcmd"}
+		}
+	})
+
+	client := client.New(client.NewLoopbackConn(srv.Serve), nil)
+	defer client.Close()
+
+	temp, err := client.Temp()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("temperature %.1f", temp)
+
+	// output: temperature 27.5
+}
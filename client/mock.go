@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ErrMockClosed is returned by a closed mock connection.
+var ErrMockClosed = errors.New("mock connection closed")
+
+// MockHandler returns the reply lines (including the reply tags like "=t") of a command.
+type MockHandler func(cmd string, args []string) []string
+
+// MockConn is an in-memory connection for testing clients without command station hardware.
+// Command replies are provided by scripted replies (see Reply) or a handler (see HandleFunc).
+type MockConn struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	buf     bytes.Buffer // pending read data
+	line    []byte       // partially written command line
+	written []string
+	replies map[string][][]string
+	handler MockHandler
+	err     error // read error after pending data is consumed
+	connErr error
+}
+
+// NewMockConn returns a new mock connection instance.
+func NewMockConn() *MockConn {
+	m := &MockConn{replies: map[string][][]string{}}
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+// Reply scripts the reply lines of a command line (like "ls 3 40").
+// Replies of the same command line are returned in the order they were scripted,
+// the last reply is kept for all further commands.
+func (m *MockConn) Reply(cmdLine string, lines ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replies[cmdLine] = append(m.replies[cmdLine], lines)
+}
+
+// HandleFunc sets the handler providing the replies of commands without scripted reply.
+// The handler is called while the connection is locked and must not call the mock connection.
+func (m *MockConn) HandleFunc(handler MockHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handler = handler
+}
+
+// Written returns the command lines written to the connection (without start tag).
+func (m *MockConn) Written() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.written...)
+}
+
+func (m *MockConn) appendLine(line string) {
+	m.buf.WriteString(line)
+	m.buf.WriteString("\r\n")
+	m.cond.Broadcast()
+}
+
+// Push injects a push message (like "ioie: 5 t").
+func (m *MockConn) Push(msg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.appendLine(string(tagPush) + msg)
+}
+
+// Inject injects raw reply lines.
+func (m *MockConn) Inject(lines ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, line := range lines {
+		m.appendLine(line)
+	}
+}
+
+// Disconnect simulates a connection loss: reads return err after the pending data is consumed
+// and writes fail. A nil error is reported as io.EOF.
+func (m *MockConn) Disconnect(err error) {
+	if err == nil {
+		err = io.EOF
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+	m.cond.Broadcast()
+}
+
+// SetConnectError sets the error returned by Connect (nil: Connect succeeds).
+func (m *MockConn) SetConnectError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connErr = err
+}
+
+// Connect implements the Conn interface.
+func (m *MockConn) Connect() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.connErr != nil {
+		return m.connErr
+	}
+	m.buf.Reset()
+	m.line = nil
+	m.err = nil
+	return nil
+}
+
+// Read implements the Conn interface.
+func (m *MockConn) Read(p []byte) (n int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for m.buf.Len() == 0 && m.err == nil {
+		m.cond.Wait()
+	}
+	if m.buf.Len() == 0 {
+		return 0, m.err
+	}
+	return m.buf.Read(p)
+}
+
+func (m *MockConn) reply(cmdLine string) []string {
+	if replies, ok := m.replies[cmdLine]; ok {
+		lines := replies[0]
+		if len(replies) > 1 {
+			m.replies[cmdLine] = replies[1:]
+		}
+		return lines
+	}
+	if m.handler != nil {
+		fields := strings.Split(cmdLine, " ")
+		return m.handler(fields[0], fields[1:])
+	}
+	return []string{string(tagNoSuccess) + etInvCmd}
+}
+
+// Write implements the Conn interface.
+func (m *MockConn) Write(p []byte) (n int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return 0, m.err
+	}
+	for _, b := range p {
+		if b != '\r' {
+			m.line = append(m.line, b)
+			continue
+		}
+		cmdLine := string(bytes.TrimPrefix(m.line, []byte{tagStart}))
+		m.line = m.line[:0]
+		m.written = append(m.written, cmdLine)
+		for _, line := range m.reply(cmdLine) {
+			m.appendLine(line)
+		}
+	}
+	return len(p), nil
+}
+
+// Close implements the Conn interface.
+func (m *MockConn) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err == nil {
+		m.err = ErrMockClosed
+	}
+	m.buf.Reset()
+	m.cond.Broadcast()
+	return nil
+}
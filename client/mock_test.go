@@ -0,0 +1,68 @@
+package client_test
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestMockConn(t *testing.T) {
+	conn := client.NewMockConn()
+	conn.Reply("t", "=26.1")
+	conn.Reply("t", "=26.3")
+	conn.Reply("ld 3 ~", "=f")
+
+	pushCh := make(chan client.Msg, 1)
+	c := client.New(conn, func(msg client.Msg, err error) {
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		pushCh <- msg
+	})
+	defer c.Close()
+
+	for _, expected := range []float64{26.1, 26.3, 26.3} {
+		temp, err := c.Temp()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if temp != expected {
+			t.Fatalf("invalid temperature %f - expected %f", temp, expected)
+		}
+	}
+	if dir, err := c.ToggleLocoDir(3); err != nil || dir {
+		t.Fatalf("invalid direction %t error %v", dir, err)
+	}
+	if _, err := c.Board(); !errors.Is(err, client.ErrInvCmd) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrInvCmd)
+	}
+
+	expected := []string{"t", "t", "t", "ld 3 ~", "b"}
+	if written := conn.Written(); !slices.Equal(written, expected) {
+		t.Fatalf("invalid written commands %v - expected %v", written, expected)
+	}
+
+	// push message
+	conn.Push("ioie: 5 t")
+	msg := <-pushCh
+	ioieMsg, ok := msg.(*client.IOIEMsg)
+	if !ok || ioieMsg.GPIO != 5 || !ioieMsg.State {
+		t.Fatalf("invalid push message %v", msg)
+	}
+
+	// disconnect
+	conn.Disconnect(io.ErrUnexpectedEOF)
+	if _, err := c.Temp(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("invalid error %v - expected %v", err, io.ErrUnexpectedEOF)
+	}
+	if err := c.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Temp(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,7 +3,6 @@ package client
 import (
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 )
 
@@ -13,21 +12,33 @@ const (
 	MkWifi
 	MkTCP
 	MkIOIE
+	MkEvict
+	MkReconnect
+	MkRailCom
+	MkTrackPower
+	MkShortCircuit
+	mkNum // number of message kinds
 )
 
 // Message class.
 const (
-	mcUnknown = ""
-	mcWifi    = "wifi:"
-	mcTCP     = "tcp:"
-	mcIOIE    = "ioie:"
+	mcUnknown      = ""
+	mcWifi         = "wifi:"
+	mcTCP          = "tcp:"
+	mcIOIE         = "ioie:"
+	mcRailCom      = "railcom:"
+	mcTrackPower   = "power:"
+	mcShortCircuit = "short:"
 )
 
 var msgKindMap = map[string]byte{
-	mcUnknown: MkUnknown,
-	mcWifi:    MkWifi,
-	mcTCP:     MkTCP,
-	mcIOIE:    MkIOIE,
+	mcUnknown:      MkUnknown,
+	mcWifi:         MkWifi,
+	mcTCP:          MkTCP,
+	mcIOIE:         MkIOIE,
+	mcRailCom:      MkRailCom,
+	mcTrackPower:   MkTrackPower,
+	mcShortCircuit: MkShortCircuit,
 }
 
 // A Msg represents a push message.
@@ -49,6 +60,63 @@ func (m *WifiMsg) String() string { return fmt.Sprintf("%s %s", mcWifi, m.Text)
 func (m *TCPMsg) String() string  { return fmt.Sprintf("%s %s", mcTCP, m.Text) }
 func (m *IOIEMsg) String() string { return fmt.Sprintf("%s gpio %d state %t", mcIOIE, m.GPIO, m.State) }
 
+// Kind implements the push message interface.
+func (m *RawMsg) Kind() int { return MkUnknown }
+
+func (m *RawMsg) String() string { return fmt.Sprintf("%s %s", m.Class, m.Text) }
+
+// Kind implements the push message interface.
+func (m *RailComMsg) Kind() int { return MkRailCom }
+
+func (m *RailComMsg) String() string {
+	if !m.HasCV {
+		return fmt.Sprintf("%s addr %d", mcRailCom, m.Addr)
+	}
+	return fmt.Sprintf("%s addr %d cv %d value %d", mcRailCom, m.Addr, m.CV, m.Value)
+}
+
+// Kind implements the push message interface.
+func (m *TrackPowerMsg) Kind() int { return MkTrackPower }
+
+func (m *TrackPowerMsg) String() string { return fmt.Sprintf("%s enabled %t", mcTrackPower, m.Enabled) }
+
+// Kind implements the push message interface.
+func (m *ShortCircuitMsg) Kind() int { return MkShortCircuit }
+
+func (m *ShortCircuitMsg) String() string {
+	if !m.HasSection {
+		return mcShortCircuit
+	}
+	return fmt.Sprintf("%s section %d", mcShortCircuit, m.Section)
+}
+
+// Kind implements the push message interface.
+func (m *EvictMsg) Kind() int { return MkEvict }
+
+func (m *EvictMsg) String() string { return fmt.Sprintf("evict: loco %d", m.Addr) }
+
+// Kind implements the push message interface.
+func (m *ReconnectMsg) Kind() int { return MkReconnect }
+
+func (m *ReconnectMsg) String() string {
+	switch {
+	case !m.Done:
+		return fmt.Sprintf("reconnect: attempt %d", m.Attempt)
+	case m.Err != nil:
+		return fmt.Sprintf("reconnect: failed after %d attempts - %s", m.Attempt, m.Err)
+	default:
+		return fmt.Sprintf("reconnect: connected after %d attempts", m.Attempt)
+	}
+}
+
+// RawMsg represents a push message of a class unknown to the client (like a push message
+// of a newer firmware version). Class is the first token of the message (like "railcom:")
+// and Text the remaining message text.
+type RawMsg struct {
+	Class string
+	Text  string
+}
+
 // WifiMsg represents a Wifi info message.
 type WifiMsg struct {
 	Text string
@@ -87,13 +155,100 @@ func parseIOIEMsg(parts []string) (*IOIEMsg, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid %s message %v - %w", mcIOIE, parts, err)
 	}
-	state, err := strconv.ParseBool(parts[1])
+	state, err := parseBool(parts[1])
 	if err != nil {
 		return nil, fmt.Errorf("invalid %s message %v - %w", mcIOIE, parts, err)
 	}
 	return &IOIEMsg{GPIO: gpio, State: state}, nil
 }
 
+// RailComMsg represents a RailCom (BiDi) feedback message of a detected loco decoder.
+// The message either contains the decoder address only or the address followed by a
+// CV index and value read via RailCom.
+type RailComMsg struct {
+	Addr  uint
+	HasCV bool // CV and Value are set
+	CV    uint
+	Value byte
+}
+
+func parseRailComMsg(parts []string) (*RailComMsg, error) {
+	if len(parts) != 1 && len(parts) != 3 {
+		return nil, fmt.Errorf("invalid %s message %v", mcRailCom, parts)
+	}
+	addr, err := parseUint(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s message %v - %w", mcRailCom, parts, err)
+	}
+	msg := &RailComMsg{Addr: addr}
+	if len(parts) == 1 {
+		return msg, nil
+	}
+	if msg.CV, err = parseUint(parts[1]); err != nil {
+		return nil, fmt.Errorf("invalid %s message %v - %w", mcRailCom, parts, err)
+	}
+	if msg.Value, err = parseByte(parts[2]); err != nil {
+		return nil, fmt.Errorf("invalid %s message %v - %w", mcRailCom, parts, err)
+	}
+	msg.HasCV = true
+	return msg, nil
+}
+
+// TrackPowerMsg represents a track power state change (like the main track being disabled by
+// another client or by the short circuit protection).
+type TrackPowerMsg struct {
+	Enabled bool
+}
+
+func parseTrackPowerMsg(parts []string) (*TrackPowerMsg, error) {
+	if len(parts) != 1 {
+		return nil, fmt.Errorf("invalid %s message %v", mcTrackPower, parts)
+	}
+	enabled, err := parseBool(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s message %v - %w", mcTrackPower, parts, err)
+	}
+	return &TrackPowerMsg{Enabled: enabled}, nil
+}
+
+// ShortCircuitMsg represents a short circuit (overcurrent) protection trip. The message optionally
+// contains the track section (output) the short circuit was detected on. After a trip the track power
+// is latched off until the short circuit is cleared (see ClearShort).
+type ShortCircuitMsg struct {
+	HasSection bool // Section is set
+	Section    uint
+}
+
+func parseShortCircuitMsg(parts []string) (*ShortCircuitMsg, error) {
+	switch len(parts) {
+	case 0:
+		return &ShortCircuitMsg{}, nil
+	case 1:
+		section, err := parseUint(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s message %v - %w", mcShortCircuit, parts, err)
+		}
+		return &ShortCircuitMsg{HasSection: true, Section: section}, nil
+	default:
+		return nil, fmt.Errorf("invalid %s message %v", mcShortCircuit, parts)
+	}
+}
+
+// EvictMsg represents a loco dropped from the refresh buffer by the command station.
+// It is not pushed by the command station but detected by WatchRefreshBuffer.
+type EvictMsg struct {
+	Addr uint
+}
+
+// ReconnectMsg represents an automatic reconnect attempt (see WithAutoReconnect).
+// It is not pushed by the command station but reported by the client before each
+// connect attempt and, with Done set, after the reconnect succeeded or finally failed.
+type ReconnectMsg struct {
+	Attempt int
+	Done    bool
+	Err     error // error of the final attempt
+}
+
 func parseMsg(s string) (Msg, error) {
 	if len(s) == 0 {
 		return nil, errors.New("empty message")
@@ -106,7 +261,13 @@ func parseMsg(s string) (Msg, error) {
 		return parseTCPMsg(parts[1:])
 	case MkIOIE:
 		return parseIOIEMsg(parts[1:])
+	case MkRailCom:
+		return parseRailComMsg(parts[1:])
+	case MkTrackPower:
+		return parseTrackPowerMsg(parts[1:])
+	case MkShortCircuit:
+		return parseShortCircuitMsg(parts[1:])
 	default:
-		return nil, fmt.Errorf("invalid message %s", s)
+		return &RawMsg{Class: parts[0], Text: strings.Join(parts[1:], " ")}, nil
 	}
 }
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMsg(t *testing.T) {
+	tests := []struct {
+		s   string
+		msg Msg
+	}{
+		{"wifi: connected", &WifiMsg{Text: "connected"}},
+		{"tcp: listening", &TCPMsg{Text: "listening"}},
+		{"ioie: 5 t", &IOIEMsg{GPIO: 5, State: true}},
+		{"railcom: 3", &RailComMsg{Addr: 3}},
+		{"railcom: 1234 29 34", &RailComMsg{Addr: 1234, HasCV: true, CV: 29, Value: 34}},
+		{"power: t", &TrackPowerMsg{Enabled: true}},
+		{"power: f", &TrackPowerMsg{Enabled: false}},
+		{"short:", &ShortCircuitMsg{}},
+		{"short: 2", &ShortCircuitMsg{HasSection: true, Section: 2}},
+		{"booster: overload 2 A", &RawMsg{Class: "booster:", Text: "overload 2 A"}},
+		{"booster:", &RawMsg{Class: "booster:"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			msg, err := parseMsg(test.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(msg, test.msg) {
+				t.Fatalf("invalid message %v - expected %v", msg, test.msg)
+			}
+		})
+	}
+}
+
+func TestParseMsgError(t *testing.T) {
+	tests := []string{
+		"",
+		"ioie: 5",
+		"railcom:",
+		"railcom: x",
+		"railcom: 3 29",
+		"railcom: 3 29 34 1",
+		"railcom: 3 x 34",
+		"railcom: 3 29 256",
+		"power:",
+		"power: x",
+		"power: t f",
+		"short: x",
+		"short: 1 2",
+	}
+
+	for _, s := range tests {
+		t.Run(s, func(t *testing.T) {
+			if msg, err := parseMsg(s); err == nil {
+				t.Fatalf("expected error - got message %v", msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package client_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestMultiReplyError(t *testing.T) {
+	replies := [][]string{
+		{"-h: help", "-b: board info", "?ioerr"}, // error in the middle of a multi-reply
+		{"-r: refresh buffer", "."},
+		{"."}, // multi-reply without lines
+	}
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		reply := replies[0]
+		replies = replies[1:]
+		return reply
+	}, nil)
+
+	if _, err := c.Help(); !errors.Is(err, client.ErrIO) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrIO)
+	}
+
+	lines, err := c.Help()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"r: refresh buffer"}; !slices.Equal(lines, expected) {
+		t.Fatalf("invalid lines %v - expected %v", lines, expected)
+	}
+
+	lines, err = c.Help()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 0 {
+		t.Fatalf("invalid lines %v - expected none", lines)
+	}
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Option represents a client option.
+type Option func(c *Client)
+
+// WithHandler sets the handler of the push messages received from the command station and of the client
+// generated messages (like ReconnectMsg). The push messages are handled by a background goroutine in receive
+// order, a message which could not be parsed is reported with the parse error.
+func WithHandler(handler func(msg Msg, err error)) Option {
+	return func(c *Client) { c.handler = handler }
+}
+
+// WithCache enables the client side cache of the last commanded loco values (see CachedLocoSpeed128).
+//
+// The cached values are the values of the last successful set or toggle command of this client
+// (direct loco methods only). They might be stale, as the values could have been changed
+// by other clients or the command station itself (like a refresh buffer eviction).
+// The cache is cleared on Reconnect and RefreshBufferReset, the values of a loco are removed
+// on RefreshBufferDelete.
+func WithCache() Option {
+	return func(c *Client) { c.cache = newCache() }
+}
+
+// WithStateCache enables the state cache recording the last commanded loco values (speed, direction and
+// functions) and GPIO configuration (like the direction and the values of output GPIOs) of this client.
+// In contrast to the cache (see WithCache) the recorded state is kept over reconnects, so that it can be
+// replayed to the command station via Resync. The values set via Batch (like by MultiRamp) are recorded as well.
+// An emergency stop is recorded as stop.
+// The state of a loco is removed on RefreshBufferDelete and the state of all locos on RefreshBufferReset.
+// The state cache is disabled by default, as the memory use grows with the number of locos and GPIOs.
+func WithStateCache() Option {
+	return func(c *Client) { c.state = newStateCache() }
+}
+
+// WithTimeout sets the timeout waiting for a command station reply (default 30 seconds).
+// The reply of a timed out command arriving within a further timeout period is discarded
+// (see Stats.Stale), so that it is not returned to a subsequent command.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) { c.timeout = timeout }
+}
+
+// WithWriteTimeout sets the timeout writing a command to the connection (default 5 seconds).
+// The write timeout is applied to connections supporting write deadlines (TCPClient and TLSClient).
+// Zero disables the write timeout. A timed out command fails, the subsequent commands are sent
+// on the same connection.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(c *Client) { c.writeTimeout = timeout }
+}
+
+// WithMaxTimeouts sets the number of consecutive read timeouts after which the connection is considered dead.
+// A dead connection is closed and ErrConnDead is returned, so that a hanging command station can be
+// distinguished from a slow one. The connection can be re-established via Reconnect.
+// Zero (default) disables the detection.
+func WithMaxTimeouts(n int) Option {
+	return func(c *Client) { c.maxTimeouts = n }
+}
+
+// WithLogger sets the logger of the client (default: no logging).
+// Log entries are structured, so that a machine-parseable handler (like slog.JSONHandler)
+// can be used to evaluate the connection stability.
+// The command lines written and the lines read are logged at debug level,
+// reconnects at info and parse errors at warning level.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) { c.logger = logger }
+}
+
+// WithAutoReconnect enables the automatic reconnect on connection errors.
+// If a command fails with a connection error (like a dropped Wifi connection), the client
+// reconnects with exponential backoff and re-executes the failed command once.
+// The reconnect attempts are reported as ReconnectMsg to the handler, which is called
+// synchronously and must not call client methods.
+//
+// Please note that a command might be executed twice by the command station if the connection
+// was lost after the command was sent but before the reply was received.
+func WithAutoReconnect() Option {
+	return func(c *Client) { c.autoConnect = true }
+}
+
+// WithMaxInFlight limits the number of in-flight commands to n, protecting the command station
+// and bounding the latency under load. Commands waiting for their execution and batches count as
+// in-flight commands, the commands of a batch are sent in chunks of at most n commands.
+// If the limit is reached, a command waits for a free slot or, if busyErr is set,
+// ErrBusy is returned. Zero (default) disables the limit.
+func WithMaxInFlight(n int, busyErr bool) Option {
+	return func(c *Client) { c.maxInFlight, c.busyErr = n, busyErr }
+}
+
+// WithRetry sets the number of retry attempts and the delay between the attempts of read commands
+// failing with a transient error (see SetRetry).
+func WithRetry(attempts int, delay time.Duration) Option {
+	return func(c *Client) { c.retryAttempts, c.retryDelay = attempts, delay }
+}
+
+// WithAsyncErrorHandler sets the handler of the errors of the fire-and-forget commands
+// (like SetLocoSpeed128Async). The errors are of type *CallError. The handler is called
+// synchronously by the connection reader and must not call client methods.
+func WithAsyncErrorHandler(handler func(err error)) Option {
+	return func(c *Client) { c.asyncErrHandler = handler }
+}
+
+// WithCoalescing enables the coalescing of the fire-and-forget loco updates (like SetLocoSpeed128Async).
+// The updates are queued and sent by a background goroutine one at a time, waiting for each reply.
+// A queued update not sent yet is superseded by a later update of the same loco property
+// (speed, direction or function), so that only the latest value is sent (like for the intermediate
+// values of a throttle slider). The updates of different properties are sent in the order of their
+// latest update. Errors are reported to the async error handler (see WithAsyncErrorHandler).
+func WithCoalescing() Option {
+	return func(c *Client) { c.coalesce = newCoalesceQueue() }
+}
+
+// WithPushBuffer sets the size of the push message buffer (default 100) and the policy applied
+// if the buffer is full (default PushBlock).
+// A blocked connection reader delays the command replies as well, so that for monitoring use cases
+// with a slow push message handler PushDropOldest is preferable. Dropped messages are counted
+// (see Stats.PushDrops). As PushDropOldest needs a buffered message to drop, the buffer size
+// is at least 1 for PushDropOldest.
+func WithPushBuffer(size int, policy PushPolicy) Option {
+	return func(c *Client) {
+		minSize := 0
+		if policy == PushDropOldest {
+			minSize = 1
+		}
+		c.pushBufSize, c.pushPolicy = max(size, minSize), policy
+	}
+}
+
+// WithLeakWarning enables a warning logged if the client is garbage collected without being closed
+// (see Close), so that leaked clients can be detected during development and testing.
+// An unclosed client leaks its connection and, unless created via NewSync, its background goroutines.
+// The warning is written to the logger of the client (see WithLogger) or, if not set,
+// to the default logger (see slog.Default).
+// The warning is disabled by default, as the finalizer adds garbage collection overhead.
+func WithLeakWarning() Option {
+	return func(c *Client) { c.leakWarning = true }
+}
+
+// WithAccAutoRelease enables the auto-release of accessory decoder outputs (like solenoids which
+// might be damaged if activated permanently): an output activated via SetAccFct (including the
+// Accessory and AccSequence methods and Batch) is deactivated after the maximum on-time maxOn,
+// unless it is released or re-activated (restarting the on-time) before. The auto-release works independently
+// of the activation time of the decoder (see SetAccTime).
+// Errors of the auto-release are reported to the async error handler (see WithAsyncErrorHandler).
+// If the release fails due to a connection error, it is resent after a successful reconnect
+// (see Reconnect), while the on-time of the outputs still activated continues over the reconnect.
+// Zero or negative maxOn disables the auto-release (default).
+func WithAccAutoRelease(maxOn time.Duration) Option {
+	return func(c *Client) {
+		c.accRelease = nil
+		if maxOn > 0 {
+			c.accRelease = newAccRelease(maxOn)
+		}
+	}
+}
+
+// WithEchoSkip enables the skipping of command echo lines (lines starting with the command start tag '+')
+// for command station firmwares echoing the commands before the reply (like with echo enabled for debugging).
+// Without the option an echoed command is not recognized, so that tags within the echoed arguments
+// (like the '-' of a negative value) might be parsed as reply and break the reply assembly.
+// Echo skipping is disabled by default.
+func WithEchoSkip() Option {
+	return func(c *Client) { c.echoSkip = true }
+}
+
+// WithMaxLineSize sets the maximum size of a reply line in bytes (default DefaultMaxLineSize).
+// A reply line exceeding the maximum size (like a large multi-reply line of a flash dump) stops the
+// connection reader: the waiting commands fail with an error wrapping bufio.ErrTooLong and the
+// connection needs to be re-established (see Reconnect).
+// Zero or negative n sets the default.
+func WithMaxLineSize(n int) Option {
+	return func(c *Client) {
+		c.maxLineSize = DefaultMaxLineSize
+		if n > 0 {
+			c.maxLineSize = n
+		}
+	}
+}
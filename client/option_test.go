@@ -0,0 +1,56 @@
+package client_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestNewClient(t *testing.T) {
+
+	t.Run("WithHandler", func(t *testing.T) {
+		conn := client.NewMockConn()
+		msgs := make(chan client.Msg, 1)
+		c := client.NewClient(conn, client.WithHandler(func(msg client.Msg, err error) {
+			if err == nil {
+				msgs <- msg
+			}
+		}))
+		defer c.Close()
+
+		conn.Push("short: 2")
+		select {
+		case msg := <-msgs:
+			if msg.Kind() != client.MkShortCircuit {
+				t.Fatalf("invalid message kind %d - expected %d", msg.Kind(), client.MkShortCircuit)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("push message not handled")
+		}
+	})
+
+	t.Run("WithTimeoutAndLogger", func(t *testing.T) {
+		var out bytes.Buffer
+		conn := client.NewMockConn()
+		conn.Reply("t") // no reply
+		c := client.NewClient(conn,
+			client.WithTimeout(20*time.Millisecond),
+			client.WithLogger(slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		)
+
+		if _, err := c.Temp(); err == nil {
+			t.Fatal("missing read timeout error")
+		}
+		if stats := c.Stats(); stats.Timeouts != 1 {
+			t.Fatalf("invalid number of timeouts %d - expected %d", stats.Timeouts, 1)
+		}
+		c.Close() // stop the reader before the log is checked
+		if !strings.Contains(out.String(), "+t") {
+			t.Fatalf("missing command in log %q", out.String())
+		}
+	})
+}
@@ -6,6 +6,18 @@ import (
 	"strings"
 )
 
+func parseBool(s string) (bool, error) {
+	if len(s) == 1 { // command station boolean values
+		switch s[0] {
+		case charTrue:
+			return true, nil
+		case charFalse:
+			return false, nil
+		}
+	}
+	return strconv.ParseBool(s)
+}
+
 func parseUint(s string) (uint, error) {
 	u64, err := strconv.ParseUint(s, 10, 0)
 	if err != nil {
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"testing"
+)
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		s     string
+		value bool
+		ok    bool
+	}{
+		{"t", true, true},
+		{"f", false, true},
+		{"true", true, true},
+		{"false", false, true},
+		{"~", false, false},
+		{"", false, false},
+		{"x", false, false},
+	}
+
+	for _, test := range tests {
+		value, err := parseBool(test.s)
+		if (err == nil) != test.ok {
+			t.Errorf("parse %q: unexpected error %v", test.s, err)
+			continue
+		}
+		if value != test.value {
+			t.Errorf("parse %q: %t - expected %t", test.s, value, test.value)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package client
+
+// PushPolicy defines the handling of push messages if the push message buffer is full
+// (like caused by a slow push message handler).
+type PushPolicy int
+
+// Push message buffer overflow policies.
+const (
+	PushBlock      PushPolicy = iota // block the connection reader until the handler consumes a message (default)
+	PushDropOldest                   // drop the oldest buffered message
+	PushDropNewest                   // drop the received message
+)
+
+const defaultPushBufSize = 100
+
+// sendPush hands the push message to the pusher according to the push policy.
+func (c *clientState) sendPush(pushCh chan string, msg string) {
+	switch c.pushPolicy {
+	case PushDropNewest:
+		select {
+		case pushCh <- msg:
+		default:
+			c.stats.droppedPush.Add(1)
+		}
+	case PushDropOldest:
+		for {
+			select {
+			case pushCh <- msg:
+				return
+			default:
+			}
+			select {
+			case <-pushCh:
+				c.stats.droppedPush.Add(1)
+			default: // consumed by the pusher meanwhile
+			}
+		}
+	default:
+		pushCh <- msg
+	}
+}
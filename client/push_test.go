@@ -0,0 +1,75 @@
+package client_test
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestPushPolicy(t *testing.T) {
+	const numMsg = 10
+
+	tests := []struct {
+		name     string
+		policy   client.PushPolicy
+		size     int
+		expected []string
+		drops    uint64
+	}{
+		// message 0 is blocking the handler, the buffer of size 2 is full after message 2
+		{"DropOldest", client.PushDropOldest, 2, []string{"0", "8", "9"}, numMsg - 3},
+		{"DropNewest", client.PushDropNewest, 2, []string{"0", "1", "2"}, numMsg - 3},
+		// unbuffered drop oldest is using a buffer of size 1
+		{"DropOldestUnbuffered", client.PushDropOldest, 0, []string{"0", "9"}, numMsg - 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var texts []string
+			received := make(chan struct{}, numMsg)
+			release := make(chan struct{})
+
+			conn := client.NewMockConn()
+			conn.Reply("t", "=25")
+			c := client.New(conn, func(msg client.Msg, err error) {
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				texts = append(texts, msg.(*client.RawMsg).Text)
+				mu.Unlock()
+				received <- struct{}{}
+				<-release
+			}, client.WithPushBuffer(test.size, test.policy))
+			defer c.Close()
+
+			conn.Push("test: 0")
+			<-received // handler is blocked
+			for i := 1; i < numMsg; i++ {
+				conn.Push(fmt.Sprintf("test: %d", i))
+			}
+			// the reply is read after the push messages were handed to the pusher
+			if _, err := c.Temp(); err != nil {
+				t.Fatal(err)
+			}
+			close(release)
+			for range len(test.expected) - 1 {
+				<-received
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !slices.Equal(texts, test.expected) {
+				t.Fatalf("invalid messages %v - expected %v", texts, test.expected)
+			}
+			if drops := c.Stats().PushDrops; drops != test.drops {
+				t.Fatalf("invalid number of dropped messages %d - expected %d", drops, test.drops)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRampInterrupted is returned if the speed of a ramped loco was changed by another party (like an emergency stop).
+var ErrRampInterrupted = errors.New("ramp interrupted")
+
+// rampLevel maps a speed value to a linear ramp level where stop and emergency stop share the lowest level.
+func rampLevel(speed uint) uint { return max(speed, SpeedEStop) }
+
+// rampStep returns the next speed value moving speed by stepSize towards target.
+func rampStep(speed, target, stepSize uint) uint {
+	level, targetLevel := rampLevel(speed), rampLevel(target)
+	switch {
+	case targetLevel > level:
+		level = min(level+stepSize, targetLevel)
+	case targetLevel < level:
+		level = max(level-min(stepSize, level), targetLevel)
+	}
+	if level == targetLevel {
+		return target
+	}
+	return level
+}
+
+// MultiRamp ramps the speed of several locos in lockstep towards their target speeds.
+// targets maps the loco addresses to the target speed values. Every stepInterval the speed of all
+// locos not yet at their target speed is changed by stepSize in a single command batch.
+// Locos reaching their target speed earlier than others keep their speed.
+// MultiRamp blocks until all locos reached their target speed, the context is done or an error occurs.
+// stepInterval and stepSize need to be greater than zero.
+func (c *Client) MultiRamp(ctx context.Context, targets map[uint]uint, stepInterval time.Duration, stepSize uint) error {
+	if stepInterval <= 0 {
+		return fmt.Errorf("invalid ramp step interval %s - expected > 0", stepInterval)
+	}
+	if stepSize == 0 {
+		return fmt.Errorf("invalid ramp step size %d - expected > 0", stepSize)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	speeds := make(map[uint]uint, len(targets))
+	for addr := range targets {
+		speed, err := c.LocoSpeed128(addr)
+		if err != nil {
+			return err
+		}
+		speeds[addr] = speed
+	}
+
+	ticker := time.NewTicker(stepInterval)
+	defer ticker.Stop()
+
+	for {
+		b := c.Batch()
+		for addr, target := range targets {
+			if speed := speeds[addr]; speed != target {
+				speeds[addr] = rampStep(speed, target, stepSize)
+				b.SetLocoSpeed128(addr, speeds[addr])
+			}
+		}
+		if len(b.cmds) == 0 {
+			return nil
+		}
+		if _, err := b.Run(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RampLocoSpeed ramps the speed of a loco from the current speed to the target speed one speed step
+// every stepDelay. Stop and emergency stop are treated as the same (lowest) ramp level, so that a ramp
+// starting at emergency stop continues with the lowest speed step and a ramp towards stop ends
+// with the target value.
+// Before each step the current speed is read: if the speed was changed in the meantime (like by an
+// emergency stop) the ramp is aborted with an error wrapping ErrRampInterrupted.
+// RampLocoSpeed blocks until the loco reached the target speed, the context is done or an error occurs.
+// stepDelay needs to be greater than zero.
+func (c *Client) RampLocoSpeed(ctx context.Context, addr, target uint, stepDelay time.Duration) error {
+	if stepDelay <= 0 {
+		return fmt.Errorf("invalid ramp step delay %s - expected > 0", stepDelay)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	speed, err := c.LocoSpeed128(addr)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(stepDelay)
+	defer ticker.Stop()
+
+	for speed != target {
+		if speed, err = c.SetLocoSpeed128(addr, rampStep(speed, target, 1)); err != nil {
+			return err
+		}
+		if speed == target {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current, err := c.LocoSpeed128(addr)
+		if err != nil {
+			return err
+		}
+		if current != speed {
+			return fmt.Errorf("ramp loco %d: speed changed from %d to %d - %w", addr, speed, current, ErrRampInterrupted)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// speedStation returns a test station handler keeping the speed of locos.
+func speedStation(mu *sync.Mutex, speeds map[string]uint, cmds *[]string) stationHandler {
+	return func(cmd string, args []string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		if cmd != "ls" {
+			return []string{"?invcmd"}
+		}
+		if len(args) == 2 {
+			speed, err := strconv.ParseUint(args[1], 10, 0)
+			if err != nil {
+				return []string{"?invprm"}
+			}
+			speeds[args[0]] = uint(speed)
+			*cmds = append(*cmds, fmt.Sprintf("ls %s %s", args[0], args[1]))
+		}
+		return []string{fmt.Sprintf("=%d", speeds[args[0]])}
+	}
+}
+
+func TestMultiRamp(t *testing.T) {
+	var mu sync.Mutex
+	speeds := map[string]uint{"3": 2, "5": 8}
+	var cmds []string
+
+	c := newTestClient(t, speedStation(&mu, speeds, &cmds), nil)
+
+	if err := c.MultiRamp(context.Background(), map[uint]uint{3: 10, 5: 4}, time.Millisecond, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// commands of one step are sent in one batch - sort each step to be independent of map order
+	steps := [][]string{
+		{"ls 3 5", "ls 5 5"},
+		{"ls 3 8", "ls 5 4"},
+		{"ls 3 10"},
+	}
+	i := 0
+	for _, step := range steps {
+		if i+len(step) > len(cmds) {
+			t.Fatalf("invalid commands %v - expected steps %v", cmds, steps)
+		}
+		got := slices.Clone(cmds[i : i+len(step)])
+		slices.Sort(got)
+		if !slices.Equal(got, step) {
+			t.Fatalf("invalid step commands %v - expected %v", got, step)
+		}
+		i += len(step)
+	}
+	if i != len(cmds) {
+		t.Fatalf("invalid commands %v - expected steps %v", cmds, steps)
+	}
+}
+
+func TestMultiRampCancel(t *testing.T) {
+	var mu sync.Mutex
+	speeds := map[string]uint{"3": 0}
+	var cmds []string
+
+	c := newTestClient(t, speedStation(&mu, speeds, &cmds), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.MultiRamp(ctx, map[uint]uint{3: 127}, time.Millisecond, 1); err != context.Canceled {
+		t.Fatalf("invalid error %v - expected %v", err, context.Canceled)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(cmds) != 0 {
+		t.Fatalf("invalid commands %v - expected none", cmds)
+	}
+}
+
+func TestMultiRampInvalid(t *testing.T) {
+	c := newTestClient(t, func(cmd string, args []string) []string { return []string{"=0"} }, nil)
+
+	tests := []struct {
+		name         string
+		stepInterval time.Duration
+		stepSize     uint
+	}{
+		{"ZeroStepSize", time.Millisecond, 0},
+		{"ZeroStepInterval", 0, 1},
+		{"NegativeStepInterval", -time.Millisecond, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := c.MultiRamp(context.Background(), map[uint]uint{3: 127}, test.stepInterval, test.stepSize); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestRampLocoSpeed(t *testing.T) {
+	tests := []struct {
+		name     string
+		speed    uint
+		target   uint
+		expected []string
+	}{
+		{"Accelerate", 0, 5, []string{"ls 3 2", "ls 3 3", "ls 3 4", "ls 3 5"}},
+		{"FromEStop", 1, 3, []string{"ls 3 2", "ls 3 3"}},
+		{"ToStop", 4, 0, []string{"ls 3 3", "ls 3 2", "ls 3 0"}},
+		{"StopToEStop", 0, 1, []string{"ls 3 1"}},
+		{"NoChange", 7, 7, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mu sync.Mutex
+			speeds := map[string]uint{"3": test.speed}
+			var cmds []string
+
+			c := newTestClient(t, speedStation(&mu, speeds, &cmds), nil)
+
+			if err := c.RampLocoSpeed(context.Background(), 3, test.target, time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !slices.Equal(cmds, test.expected) {
+				t.Fatalf("invalid commands %v - expected %v", cmds, test.expected)
+			}
+		})
+	}
+
+	t.Run("EmergencyStop", func(t *testing.T) {
+		var mu sync.Mutex
+		speeds := map[string]uint{"3": 0}
+		var cmds []string
+
+		station := speedStation(&mu, speeds, &cmds)
+		c := newTestClient(t, func(cmd string, args []string) []string {
+			reply := station(cmd, args)
+			mu.Lock()
+			defer mu.Unlock()
+			if len(cmds) == 2 { // emergency stop after the second step
+				speeds["3"] = client.SpeedEStop
+			}
+			return reply
+		}, nil)
+
+		err := c.RampLocoSpeed(context.Background(), 3, 10, time.Millisecond)
+		if !errors.Is(err, client.ErrRampInterrupted) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrRampInterrupted)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if expected := []string{"ls 3 2", "ls 3 3"}; !slices.Equal(cmds, expected) {
+			t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		var mu sync.Mutex
+		var cmds []string
+		c := newTestClient(t, speedStation(&mu, map[string]uint{"3": 0}, &cmds), nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := c.RampLocoSpeed(ctx, 3, 10, time.Millisecond); !errors.Is(err, context.Canceled) {
+			t.Fatalf("invalid error %v - expected %v", err, context.Canceled)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(cmds) != 0 {
+			t.Fatalf("invalid commands %v - expected none", cmds)
+		}
+	})
+
+	t.Run("InvalidStepDelay", func(t *testing.T) {
+		var mu sync.Mutex
+		c := newTestClient(t, speedStation(&mu, map[string]uint{"3": 0}, new([]string)), nil)
+
+		if err := c.RampLocoSpeed(context.Background(), 3, 10, 0); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rawArg is a string argument of a raw command.
+type rawArg string
+
+// rawArgs converts the string arguments of a raw command and validates all arguments.
+func rawArgs(f formatters, cmd string, args []any) ([]any, error) {
+	if cmd == "" || strings.ContainsAny(cmd, " \r\n") {
+		return nil, fmt.Errorf("invalid raw command %q", cmd)
+	}
+	rargs := make([]any, len(args))
+	for i, arg := range args {
+		if s, ok := arg.(string); ok {
+			if s == "" || strings.ContainsAny(s, " \r\n") {
+				return nil, fmt.Errorf("invalid raw command argument %d %q", i, s)
+			}
+			arg = rawArg(s)
+		}
+		if _, err := formatArg(f, arg); err != nil {
+			return nil, fmt.Errorf("raw command argument %d: %w", i, err)
+		}
+		rargs[i] = arg
+	}
+	return rargs, nil
+}
+
+// Raw sends a command and returns the unparsed single line reply (without reply tag).
+// Raw is an escape hatch for commands not (yet) supported by the client (like commands of a newer
+// firmware version) and for protocol debugging. The arguments are formatted like the arguments
+// of the client commands (see WithFormatter), strings are sent unchanged.
+// Like all commands Raw is serialized with the other commands and the reply timeout is applied.
+func (c *Client) Raw(cmd string, args ...any) (string, error) {
+	args, err := rawArgs(c.formatters, cmd, args)
+	if err != nil {
+		return "", err
+	}
+	return c.singleReply(cmd, args...)
+}
+
+// RawMulti sends a command and returns the unparsed reply lines of a multi line reply (without reply tags).
+// See Raw.
+func (c *Client) RawMulti(cmd string, args ...any) ([]string, error) {
+	args, err := rawArgs(c.formatters, cmd, args)
+	if err != nil {
+		return nil, err
+	}
+	return c.multiReply(cmd, args...)
+}
@@ -0,0 +1,73 @@
+package client_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestRaw(t *testing.T) {
+	var mu sync.Mutex
+	var cmds []string
+
+	c := newTestClient(t, recordStation(&mu, &cmds, func(cmd string, args []string) []string {
+		switch cmd {
+		case "xyz": // command unknown to the client
+			return []string{"=" + strings.Join(args, ",")}
+		case "xyzl":
+			return []string{"-line 1", "-line 2", "."}
+		}
+		return []string{"?invcmd"}
+	}), nil)
+
+	reply, err := c.Raw("xyz", uint(3), "abc", true, client.Toggle{}, -5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != "3,abc,t,~,-5" {
+		t.Fatalf("invalid reply %q - expected %q", reply, "3,abc,t,~,-5")
+	}
+
+	lines, err := c.RawMulti("xyzl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"line 1", "line 2"}; !slices.Equal(lines, expected) {
+		t.Fatalf("invalid lines %v - expected %v", lines, expected)
+	}
+
+	if _, err := c.Raw("unknown"); !errors.Is(err, client.ErrInvCmd) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrInvCmd)
+	}
+	if _, err := c.Raw("xyzl"); err == nil { // multi line reply
+		t.Fatal("expected error on multi line reply")
+	}
+
+	invalid := []struct {
+		cmd  string
+		args []any
+	}{
+		{"", nil},
+		{"x y", nil},
+		{"xyz", []any{"a b"}},
+		{"xyz", []any{"a\r"}},
+		{"xyz", []any{""}},
+		{"xyz", []any{1.5}},
+		{"xyz", []any{nil}},
+	}
+	for _, test := range invalid {
+		if _, err := c.Raw(test.cmd, test.args...); err == nil {
+			t.Errorf("command %q args %v: expected error", test.cmd, test.args)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if expected := []string{"xyz 3 abc t ~ -5", "xyzl", "unknown", "xyzl"}; !slices.Equal(cmds, expected) {
+		t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+	}
+}
@@ -0,0 +1,74 @@
+package rbuf
+
+import (
+	"cmp"
+	"slices"
+)
+
+// EntryChange represents the state changes of a refresh buffer entry.
+type EntryChange struct {
+	Addr     uint
+	Old, New Entry
+	Fields   []int // changed entry state byte indices (DirSpeed, F0_4 ... F61_68)
+}
+
+// BufferDiff represents the differences between two refresh buffer snapshots.
+type BufferDiff struct {
+	Added   []uint // addresses of added locos
+	Removed []uint // addresses of removed locos
+	Changed []EntryChange
+}
+
+// Empty returns true if the snapshots do not differ, false otherwise.
+func (d *BufferDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func entriesByAddr(buf *Buffer) map[uint]Entry {
+	entries := map[uint]Entry{}
+	if buf == nil {
+		return entries
+	}
+	for _, e := range buf.Entries {
+		if _, ok := entries[e.Addr()]; !ok {
+			entries[e.Addr()] = e
+		}
+	}
+	return entries
+}
+
+// Diff returns the differences between the refresh buffer snapshots old and new.
+// The entries are compared by loco address, as the entry indices might be reused by the command station.
+// Only the loco state (direction, speed and functions) is compared, the index, refresh counters
+// and the ring links are ignored. The results are sorted by address. A nil buffer is handled as empty buffer.
+func Diff(old, new *Buffer) BufferDiff {
+	oldEntries, newEntries := entriesByAddr(old), entriesByAddr(new)
+
+	var d BufferDiff
+	for addr, oe := range oldEntries {
+		ne, ok := newEntries[addr]
+		if !ok {
+			d.Removed = append(d.Removed, addr)
+			continue
+		}
+		var fields []int
+		for i := DirSpeed; i <= F61_68; i++ {
+			if oe[i] != ne[i] {
+				fields = append(fields, i)
+			}
+		}
+		if fields != nil {
+			d.Changed = append(d.Changed, EntryChange{Addr: addr, Old: oe, New: ne, Fields: fields})
+		}
+	}
+	for addr := range newEntries {
+		if _, ok := oldEntries[addr]; !ok {
+			d.Added = append(d.Added, addr)
+		}
+	}
+
+	slices.Sort(d.Added)
+	slices.Sort(d.Removed)
+	slices.SortFunc(d.Changed, func(a, b EntryChange) int { return cmp.Compare(a.Addr, b.Addr) })
+	return d
+}
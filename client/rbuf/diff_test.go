@@ -0,0 +1,61 @@
+package rbuf
+
+import (
+	"slices"
+	"testing"
+)
+
+func entry(idx byte, addr uint, dirSpeed byte) Entry {
+	var e Entry
+	e[Idx] = idx
+	e[MSB], e[LSB] = byte(addr>>8), byte(addr)
+	e[DirSpeed] = dirSpeed
+	return e
+}
+
+func TestDiff(t *testing.T) {
+	old := &Buffer{Entries: []Entry{entry(0, 3, 0x80), entry(1, 1024, 0x28), entry(2, 5, 0)}}
+
+	// loco 5 removed, loco 7 added reusing index 2, loco 3 speed and function changed,
+	// loco 1024 only bookkeeping changed
+	e3 := entry(0, 3, 0x90)
+	e3[F0_4] = 0x10
+	e1024 := entry(1, 1024, 0x28)
+	e1024[RefreshCmd], e1024[Prev], e1024[Next] = 5, 2, 2
+	cur := &Buffer{Entries: []Entry{e3, e1024, entry(2, 7, 0)}}
+
+	d := Diff(old, cur)
+	if !slices.Equal(d.Added, []uint{7}) {
+		t.Errorf("invalid added %v", d.Added)
+	}
+	if !slices.Equal(d.Removed, []uint{5}) {
+		t.Errorf("invalid removed %v", d.Removed)
+	}
+	if len(d.Changed) != 1 {
+		t.Fatalf("invalid changed %v", d.Changed)
+	}
+	ch := d.Changed[0]
+	if ch.Addr != 3 || !slices.Equal(ch.Fields, []int{DirSpeed, F0_4}) || ch.Old != old.Entries[0] || ch.New != e3 {
+		t.Fatalf("invalid change %+v", ch)
+	}
+	if d.Empty() {
+		t.Fatal("diff empty")
+	}
+}
+
+func TestDiffEmpty(t *testing.T) {
+	buf := &Buffer{Entries: []Entry{entry(0, 3, 0x80), entry(1, 4, 0x28)}}
+
+	if d := Diff(buf, buf); !d.Empty() {
+		t.Fatalf("invalid diff of equal buffers %+v", d)
+	}
+	if d := Diff(nil, nil); !d.Empty() {
+		t.Fatalf("invalid diff of nil buffers %+v", d)
+	}
+	if d := Diff(nil, buf); !slices.Equal(d.Added, []uint{3, 4}) || len(d.Removed) != 0 {
+		t.Fatalf("invalid diff %+v", d)
+	}
+	if d := Diff(buf, &Buffer{}); !slices.Equal(d.Removed, []uint{3, 4}) || len(d.Added) != 0 {
+		t.Fatalf("invalid diff %+v", d)
+	}
+}
@@ -0,0 +1,52 @@
+package rbuf
+
+import "encoding/json"
+
+// jsonEntry is the JSON representation of a refresh buffer entry.
+type jsonEntry struct {
+	Idx           byte   `json:"idx"`
+	Addr          uint   `json:"addr"`
+	MaxRefreshCmd byte   `json:"maxRefreshCmd"`
+	RefreshCmd    byte   `json:"refreshCmd"`
+	Speed         uint   `json:"speed"`
+	Forward       bool   `json:"forward"`
+	Fcts          []uint `json:"fcts"` // numbers of the active functions
+	Prev          byte   `json:"prev"`
+	Next          byte   `json:"next"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The direction, speed and function bytes are decoded (see Speed and Function).
+func (e Entry) MarshalJSON() ([]byte, error) {
+	speed, forward := e.Speed()
+	je := jsonEntry{
+		Idx:           e[Idx],
+		Addr:          e.Addr(),
+		MaxRefreshCmd: e[MaxRefreshCmd],
+		RefreshCmd:    e[RefreshCmd],
+		Speed:         speed,
+		Forward:       forward,
+		Fcts:          []uint{},
+		Prev:          e[Prev],
+		Next:          e[Next],
+	}
+	for no := uint(0); no <= MaxFct; no++ {
+		if e.Function(no) {
+			je.Fcts = append(je.Fcts, no)
+		}
+	}
+	return json.Marshal(je)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (buf Buffer) MarshalJSON() ([]byte, error) {
+	entries := buf.Entries
+	if entries == nil {
+		entries = []Entry{}
+	}
+	return json.Marshal(struct {
+		First   int     `json:"first"`
+		Next    int     `json:"next"`
+		Entries []Entry `json:"entries"`
+	}{buf.First, buf.Next, entries})
+}
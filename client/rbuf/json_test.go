@@ -0,0 +1,51 @@
+package rbuf_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pico-cs/go-client/client/rbuf"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	var e rbuf.Entry
+	e[rbuf.Idx] = 0
+	e[rbuf.MSB], e[rbuf.LSB] = 0x04, 0x00 // addr 1024
+	e[rbuf.MaxRefreshCmd] = 3
+	e[rbuf.DirSpeed] = 0x80 | 42
+	e[rbuf.F0_4] = 0x11 // F0, F1
+	e[rbuf.F13_20] = 0x01
+	e[rbuf.F61_68] = 0x80 // F68
+
+	buf := &rbuf.Buffer{First: 0, Next: 1, Entries: []rbuf.Entry{e}}
+	b, err := json.Marshal(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"first":0,"next":1,"entries":[{"idx":0,"addr":1024,"maxRefreshCmd":3,"refreshCmd":0,"speed":42,"forward":true,"fcts":[0,1,13,68],"prev":0,"next":0}]}`
+	if string(b) != expected {
+		t.Fatalf("invalid json\n%s\nexpected\n%s", b, expected)
+	}
+
+	// values (like slice elements and struct fields) are encoded the same way
+	b, err = json.Marshal(struct {
+		Buffer rbuf.Buffer  `json:"buffer"`
+		Entry  rbuf.Entry   `json:"entry"`
+		Ring   []rbuf.Entry `json:"ring"`
+	}{*buf, e, []rbuf.Entry{e}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := `{"idx":0,"addr":1024,"maxRefreshCmd":3,"refreshCmd":0,"speed":42,"forward":true,"fcts":[0,1,13,68],"prev":0,"next":0}`
+	if expected := `{"buffer":` + expected + `,"entry":` + entry + `,"ring":[` + entry + `]}`; string(b) != expected {
+		t.Fatalf("invalid json\n%s\nexpected\n%s", b, expected)
+	}
+
+	empty, err := json.Marshal(&rbuf.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"first":0,"next":0,"entries":[]}`; string(empty) != expected {
+		t.Fatalf("invalid json %s - expected %s", empty, expected)
+	}
+}
@@ -36,6 +36,42 @@ const (
 // Entry represents a command station refresh buffer entry.
 type Entry [NumBytes]byte
 
+// Addr returns the loco address of the entry.
+func (e *Entry) Addr() uint { return uint(e[MSB])<<8 | uint(e[LSB]) }
+
+// MaxFct is the highest function number of a refresh buffer entry.
+const MaxFct = 68
+
+// Speed returns the 128 speed step value (0: stop, 1: emergency stop) and the direction
+// (true: forward) of the entry.
+func (e *Entry) Speed() (speed uint, forward bool) {
+	return uint(e[DirSpeed] & 0x7f), e[DirSpeed]&0x80 != 0
+}
+
+// Function returns the state of function no (0-68). Functions out of range are reported as off.
+//
+// The function bits follow the DCC function group layout:
+//   - F0 is stored in bit 4 of F0_4, F1-F4 in bits 0-3
+//   - F5-F8 and F9-F12 are stored in bits 0-3 of F5_8 and F9_12
+//   - F13-F68 are stored in groups of eight functions (F13_20 ... F61_68), the lowest function in bit 0
+func (e *Entry) Function(no uint) bool {
+	switch {
+	case no == 0:
+		return e[F0_4]&0x10 != 0
+	case no <= 4:
+		return e[F0_4]&(1<<(no-1)) != 0
+	case no <= 8:
+		return e[F5_8]&(1<<(no-5)) != 0
+	case no <= 12:
+		return e[F9_12]&(1<<(no-9)) != 0
+	case no <= MaxFct:
+		i := no - 13
+		return e[F13_20+int(i/8)]&(1<<(i%8)) != 0
+	default:
+		return false
+	}
+}
+
 func (e *Entry) String() string {
 	ppDirSpeed := func(fct byte) string { return fmt.Sprintf("%1b-%03d", fct>>7, fct&0x7f) }
 	ppF0_4 := func(fct byte) string { return fmt.Sprintf("%1b-%04b", fct>>4, fct&0x0f) }
@@ -44,7 +80,7 @@ func (e *Entry) String() string {
 	return fmt.Sprintf(
 		"idx %3d addr %5d maxRefreshCmd %3d RefreshCmd %3d dirSpeed %s f0_4 %s f5_8 %04b f9_12 %04b f5_12 %s f13_20 %s f21_28 %s f29_36 %s f37_44 %s f45_52 %s f53_60 %s f61_68 %s prev %3d next %3d",
 		e[Idx],
-		(uint16(e[MSB])<<8)|uint16(e[LSB]),
+		e.Addr(),
 		e[MaxRefreshCmd],
 		e[RefreshCmd],
 		ppDirSpeed(e[DirSpeed]),
@@ -74,6 +110,16 @@ func (buf *Buffer) String() string {
 	return fmt.Sprintf("first %d next %d num entries %d", buf.First, buf.Next, len(buf.Entries))
 }
 
+// EntryByAddr returns the entry of the loco with address addr.
+func (buf *Buffer) EntryByAddr(addr uint) (*Entry, bool) {
+	for i := range buf.Entries {
+		if buf.Entries[i].Addr() == addr {
+			return &buf.Entries[i], true
+		}
+	}
+	return nil, false
+}
+
 // Parse parses the refresh buffer send by a command station.
 func Parse(lines []string) (*Buffer, error) {
 	if len(lines) < 1 {
@@ -117,3 +163,51 @@ func Parse(lines []string) (*Buffer, error) {
 	slices.SortFunc(buf.Entries, func(a, b Entry) int { return cmp.Compare(a[Idx], b[Idx]) })
 	return buf, nil
 }
+
+// Walk returns the entries in refresh order, starting at the first entry and following the next links
+// until the ring is closed. An error is returned if the links are inconsistent (like a missing
+// entry, a prev link not matching the next link, a cycle not including the first entry or entries
+// not part of the ring).
+func (buf *Buffer) Walk() ([]Entry, error) {
+	if len(buf.Entries) == 0 {
+		return nil, nil
+	}
+
+	entries := make(map[int]Entry, len(buf.Entries))
+	for _, e := range buf.Entries {
+		entries[int(e[Idx])] = e
+	}
+
+	e, ok := entries[buf.First]
+	if !ok {
+		return nil, fmt.Errorf("walk refresh buffer error - first entry %d not found", buf.First)
+	}
+
+	ring := make([]Entry, 0, len(buf.Entries))
+	visited := make(map[int]bool, len(buf.Entries))
+	for {
+		idx := int(e[Idx])
+		if visited[idx] {
+			return nil, fmt.Errorf("walk refresh buffer error - cycle at entry %d not including first entry %d", idx, buf.First)
+		}
+		visited[idx] = true
+		ring = append(ring, e)
+
+		next, ok := entries[int(e[Next])]
+		if !ok {
+			return nil, fmt.Errorf("walk refresh buffer error - next entry %d of entry %d not found", e[Next], idx)
+		}
+		if int(next[Prev]) != idx {
+			return nil, fmt.Errorf("walk refresh buffer error - prev link %d of entry %d does not match entry %d", next[Prev], next[Idx], idx)
+		}
+		if int(next[Idx]) == buf.First {
+			break
+		}
+		e = next
+	}
+
+	if len(ring) != len(entries) {
+		return nil, fmt.Errorf("walk refresh buffer error - %d of %d entries not linked", len(entries)-len(ring), len(entries))
+	}
+	return ring, nil
+}
@@ -0,0 +1,224 @@
+package rbuf
+
+import (
+	"slices"
+	"testing"
+)
+
+// ring returns a refresh buffer with the entries linked in order of the idxs.
+func ring(first int, idxs ...byte) *Buffer {
+	buf := &Buffer{First: first}
+	n := len(idxs)
+	for i, idx := range idxs {
+		var e Entry
+		e[Idx] = idx
+		e[LSB] = idx + 10 // address
+		e[Prev] = idxs[(i+n-1)%n]
+		e[Next] = idxs[(i+1)%n]
+		buf.Entries = append(buf.Entries, e)
+	}
+	return buf
+}
+
+func idxs(entries []Entry) []byte {
+	var idxs []byte
+	for _, e := range entries {
+		idxs = append(idxs, e[Idx])
+	}
+	return idxs
+}
+
+func TestWalk(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  *Buffer
+		idxs []byte
+	}{
+		{"Empty", &Buffer{}, nil},
+		{"Single", ring(0, 0), []byte{0}},
+		{"Ordered", ring(0, 0, 1, 2, 3), []byte{0, 1, 2, 3}},
+		{"Unordered", ring(2, 2, 0, 3, 1), []byte{2, 0, 3, 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// entries are sorted by index like after parsing
+			slices.SortFunc(test.buf.Entries, func(a, b Entry) int { return int(a[Idx]) - int(b[Idx]) })
+
+			entries, err := test.buf.Walk()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := idxs(entries); !slices.Equal(got, test.idxs) {
+				t.Fatalf("invalid refresh order %v - expected %v", got, test.idxs)
+			}
+		})
+	}
+}
+
+func TestWalkInconsistent(t *testing.T) {
+	missingFirst := ring(0, 0, 1, 2)
+	missingFirst.First = 5
+
+	missingNext := ring(0, 0, 1, 2)
+	missingNext.Entries[1][Next] = 7
+
+	invalidPrev := ring(0, 0, 1, 2)
+	invalidPrev.Entries[2][Prev] = 0
+
+	// 0 -> 1 -> 2 -> 1 ...
+	cycle := ring(0, 0, 1, 2)
+	cycle.Entries[2][Next] = 1
+	cycle.Entries[1][Prev] = 2
+
+	// 0 <-> 1, 2 <-> 3
+	unlinked := &Buffer{Entries: append(ring(0, 0, 1).Entries, ring(2, 2, 3).Entries...)}
+
+	tests := []struct {
+		name string
+		buf  *Buffer
+	}{
+		{"MissingFirst", missingFirst},
+		{"MissingNext", missingNext},
+		{"InvalidPrev", invalidPrev},
+		{"Cycle", cycle},
+		{"Unlinked", unlinked},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.buf.Walk(); err == nil {
+				t.Fatal("expected error on inconsistent links")
+			}
+		})
+	}
+}
+
+func TestEntryAddr(t *testing.T) {
+	tests := []struct {
+		msb, lsb byte
+		addr     uint
+	}{
+		{0, 3, 3},
+		{0, 255, 255},
+		{1, 0, 256},
+		{0x27, 0x10, 10000},
+		{0x3f, 0xff, 16383},
+	}
+
+	for _, test := range tests {
+		var e Entry
+		e[MSB], e[LSB] = test.msb, test.lsb
+		if addr := e.Addr(); addr != test.addr {
+			t.Errorf("msb %d lsb %d: invalid address %d - expected %d", test.msb, test.lsb, addr, test.addr)
+		}
+	}
+}
+
+func TestEntryByAddr(t *testing.T) {
+	buf := &Buffer{}
+	for i, addr := range []uint{3, 256, 1000, 10000} {
+		var e Entry
+		e[Idx] = byte(i)
+		e[MSB], e[LSB] = byte(addr>>8), byte(addr)
+		buf.Entries = append(buf.Entries, e)
+	}
+
+	for i, addr := range []uint{3, 256, 1000, 10000} {
+		e, ok := buf.EntryByAddr(addr)
+		if !ok {
+			t.Fatalf("entry of address %d not found", addr)
+		}
+		if e.Addr() != addr || int(e[Idx]) != i {
+			t.Fatalf("invalid entry %s for address %d", e, addr)
+		}
+	}
+	// entry is not copied
+	e, _ := buf.EntryByAddr(1000)
+	if e != &buf.Entries[2] {
+		t.Fatal("entry is not part of the buffer")
+	}
+
+	for _, addr := range []uint{0, 4, 1256, 16} { // 1256 and 16 share the lsb of 1000 and 10000
+		if _, ok := buf.EntryByAddr(addr); ok {
+			t.Fatalf("unexpected entry of address %d", addr)
+		}
+	}
+}
+
+func TestEntrySpeed(t *testing.T) {
+	tests := []struct {
+		dirSpeed byte
+		speed    uint
+		forward  bool
+	}{
+		{0x00, 0, false},
+		{0x80, 0, true},
+		{0x01, 1, false},
+		{0x81, 1, true},
+		{0x28, 40, false},
+		{0xff, 127, true},
+	}
+
+	for _, test := range tests {
+		var e Entry
+		e[DirSpeed] = test.dirSpeed
+		if speed, forward := e.Speed(); speed != test.speed || forward != test.forward {
+			t.Errorf("%08b: speed %d forward %t - expected %d %t", test.dirSpeed, speed, forward, test.speed, test.forward)
+		}
+	}
+}
+
+func TestEntryFunction(t *testing.T) {
+	tests := []struct {
+		no  uint
+		idx int
+		bit byte
+	}{
+		{0, F0_4, 4},
+		{1, F0_4, 0},
+		{4, F0_4, 3},
+		{5, F5_8, 0},
+		{8, F5_8, 3},
+		{9, F9_12, 0},
+		{12, F9_12, 3},
+		{13, F13_20, 0},
+		{20, F13_20, 7},
+		{21, F21_28, 0},
+		{28, F21_28, 7},
+		{29, F29_36, 0},
+		{36, F29_36, 7},
+		{37, F37_44, 0},
+		{44, F37_44, 7},
+		{45, F45_52, 0},
+		{52, F45_52, 7},
+		{53, F53_60, 0},
+		{60, F53_60, 7},
+		{61, F61_68, 0},
+		{68, F61_68, 7},
+	}
+
+	for _, test := range tests {
+		var e Entry
+		e[test.idx] = 1 << test.bit
+		for no := uint(0); no <= MaxFct+1; no++ {
+			if on := e.Function(no); on != (no == test.no) {
+				t.Errorf("f%d bit %d of byte %d set: function %d state %t", test.no, test.bit, test.idx, no, on)
+			}
+		}
+	}
+
+	// all bits set
+	var e Entry
+	for i := F0_4; i <= F61_68; i++ {
+		e[i] = 0xff
+	}
+	for no := uint(0); no <= MaxFct; no++ {
+		if !e.Function(no) {
+			t.Errorf("function %d off - expected on", no)
+		}
+	}
+	if e.Function(MaxFct + 1) {
+		t.Errorf("function %d on - expected off", MaxFct+1)
+	}
+}
@@ -0,0 +1,223 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestAutoReconnect(t *testing.T) {
+	conn := client.NewMockConn()
+	conn.Reply("t")          // first command: no reply (connection is lost while waiting)
+	conn.Reply("t", "=27.5") // retried command
+
+	var mu sync.Mutex
+	var msgs []client.ReconnectMsg
+	c := client.New(conn, func(msg client.Msg, err error) {
+		if m, ok := msg.(*client.ReconnectMsg); ok {
+			mu.Lock()
+			msgs = append(msgs, *m)
+			mu.Unlock()
+		}
+	}, client.WithAutoReconnect())
+	defer c.Close()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		conn.Disconnect(io.ErrUnexpectedEOF)
+	}()
+
+	temp, err := c.Temp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp != 27.5 {
+		t.Fatalf("invalid temperature %f - expected %f", temp, 27.5)
+	}
+
+	if written, expected := conn.Written(), []string{"t", "t"}; !slices.Equal(written, expected) {
+		t.Fatalf("invalid written commands %v - expected %v", written, expected)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []client.ReconnectMsg{{Attempt: 1}, {Attempt: 1, Done: true}}
+	if !slices.Equal(msgs, expected) {
+		t.Fatalf("invalid reconnect messages %v - expected %v", msgs, expected)
+	}
+}
+
+func TestNoAutoReconnect(t *testing.T) {
+	conn := client.NewMockConn()
+	conn.HandleFunc(mockStation())
+	c := client.New(conn, nil)
+	defer c.Close()
+
+	conn.Disconnect(io.ErrUnexpectedEOF)
+	if _, err := c.Temp(); err == nil {
+		t.Fatal("expected connection error")
+	}
+	if written := conn.Written(); len(written) != 0 {
+		t.Fatalf("invalid written commands %v", written)
+	}
+}
+
+func TestConcurrentReconnect(t *testing.T) {
+	conn := client.NewMockConn()
+	conn.HandleFunc(mockStation())
+	c := client.New(conn, nil)
+	defer c.Close()
+
+	conn.Disconnect(io.ErrUnexpectedEOF)
+
+	const n = 5
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.Reconnect()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.Temp(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// flakyConn is a mock connection failing the first connect attempts.
+type flakyConn struct {
+	*client.MockConn
+	mu       sync.Mutex
+	fails    int // number of failing connect attempts
+	attempts int
+}
+
+func (c *flakyConn) Connect() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attempts++
+	if c.attempts <= c.fails {
+		return fmt.Errorf("connect attempt %d failed", c.attempts)
+	}
+	return c.MockConn.Connect()
+}
+
+// ctxConn is a mock connection recording the context of ConnectContext.
+type ctxConn struct {
+	*client.MockConn
+	mu  sync.Mutex
+	ctx context.Context
+}
+
+func (c *ctxConn) ConnectContext(ctx context.Context) error {
+	c.mu.Lock()
+	c.ctx = ctx
+	c.mu.Unlock()
+	return c.MockConn.Connect()
+}
+
+func TestReconnectWithBackoff(t *testing.T) {
+	t.Run("ThirdAttempt", func(t *testing.T) {
+		conn := &flakyConn{MockConn: client.NewMockConn(), fails: 2}
+		conn.HandleFunc(mockStation())
+
+		var mu sync.Mutex
+		var msgs []client.ReconnectMsg
+		c := client.New(conn, func(msg client.Msg, err error) {
+			if m, ok := msg.(*client.ReconnectMsg); ok {
+				mu.Lock()
+				msgs = append(msgs, *m)
+				mu.Unlock()
+			}
+		})
+		defer c.Close()
+
+		conn.Disconnect(io.ErrUnexpectedEOF)
+		if err := c.ReconnectWithBackoff(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Temp(); err != nil {
+			t.Fatal(err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		expected := []client.ReconnectMsg{{Attempt: 1}, {Attempt: 2}, {Attempt: 3}, {Attempt: 3, Done: true}}
+		if !slices.Equal(msgs, expected) {
+			t.Fatalf("invalid reconnect messages %v - expected %v", msgs, expected)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		conn := client.NewMockConn()
+		conn.HandleFunc(mockStation())
+		connErr := errors.New("connect failed")
+		conn.SetConnectError(connErr)
+		var mu sync.Mutex
+		var msgs []client.ReconnectMsg
+		c := client.New(conn, func(msg client.Msg, err error) {
+			if m, ok := msg.(*client.ReconnectMsg); ok {
+				mu.Lock()
+				msgs = append(msgs, *m)
+				mu.Unlock()
+			}
+		})
+		defer c.Close()
+
+		conn.Disconnect(io.ErrUnexpectedEOF)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 800*time.Millisecond) // after the first attempt
+		defer cancel()
+		err := c.ReconnectWithBackoff(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, connErr) {
+			t.Fatalf("invalid error %v - expected %v and %v", err, context.DeadlineExceeded, connErr)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(msgs) != 2 {
+			t.Fatalf("invalid number of reconnect messages %d - expected %d", len(msgs), 2)
+		}
+		if msgs[0] != (client.ReconnectMsg{Attempt: 1}) {
+			t.Fatalf("invalid reconnect message %v - expected %v", msgs[0], client.ReconnectMsg{Attempt: 1})
+		}
+		if last := msgs[1]; last.Attempt != 1 || !last.Done || last.Err == nil {
+			t.Fatalf("invalid final reconnect message %v - expected attempt %d done with error", last, 1)
+		}
+	})
+
+	t.Run("ConnectContext", func(t *testing.T) {
+		conn := &ctxConn{MockConn: client.NewMockConn()}
+		conn.HandleFunc(mockStation())
+		c := client.New(conn, nil)
+		defer c.Close()
+
+		conn.Disconnect(io.ErrUnexpectedEOF)
+
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, true)
+		if err := c.ReconnectWithBackoff(ctx); err != nil {
+			t.Fatal(err)
+		}
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		if conn.ctx == nil || conn.ctx.Value(ctxKey{}) == nil {
+			t.Fatal("connection not connected with the reconnect context")
+		}
+	})
+}
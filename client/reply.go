@@ -0,0 +1,152 @@
+package client
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// pendingReply is a command waiting for its reply.
+type pendingReply struct {
+	cmd     string
+	args    []any
+	ch      chan any           // reply channel of a synchronous command (nil: async command)
+	done    func(reply string) // successful reply callback of an async command (might be nil)
+	stream  func(line string)  // multi-reply line callback of a streaming command (nil: lines are collected)
+	expires time.Time          // abandoned command: end of the grace period (zero: not abandoned)
+}
+
+// replyQueue correlates the replies to the commands of a connection.
+//
+// As the command station processes the commands one at a time, the replies are received in
+// command order. Each command is queued before it is written and each reply is handed to the
+// oldest queued command, so that a reply is never returned to another command. The reply of a
+// timed out (abandoned) command is discarded if it arrives within a grace period. After the
+// grace period the reply is considered lost and the command is removed from the queue.
+//
+// As the replies do not identify their command, a lost reply can not be distinguished from a late one:
+// the reply of a command sent within the grace period of a command with lost reply is discarded, so that
+// the command times out as well. WithMaxTimeouts bounds such consecutive timeouts.
+type replyQueue struct {
+	mu      sync.Mutex
+	pending []*pendingReply
+	closed  bool
+}
+
+func newSyncReply(cmd string, args []any) *pendingReply {
+	return &pendingReply{cmd: cmd, args: args, ch: make(chan any, 1)}
+}
+
+// purge removes the abandoned commands with an expired grace period. The queue needs to be locked.
+func (q *replyQueue) purge(now time.Time) {
+	q.pending = slices.DeleteFunc(q.pending, func(p *pendingReply) bool {
+		return !p.expires.IsZero() && now.After(p.expires)
+	})
+}
+
+// push queues the command. false is returned if the connection reader is finished already.
+func (q *replyQueue) push(p *pendingReply) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return false
+	}
+	q.purge(time.Now())
+	q.pending = append(q.pending, p)
+	return true
+}
+
+// pop returns the oldest queued command and if it was abandoned.
+// ok is false if no command is waiting for a reply.
+func (q *replyQueue) pop() (p *pendingReply, abandoned, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.purge(time.Now())
+	if len(q.pending) == 0 {
+		return nil, false, false
+	}
+	p, q.pending = q.pending[0], q.pending[1:]
+	return p, !p.expires.IsZero(), true
+}
+
+// peek returns the oldest queued command without removing it.
+// ok is false if no command is waiting for a reply.
+func (q *replyQueue) peek() (p *pendingReply, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.purge(time.Now())
+	if len(q.pending) == 0 {
+		return nil, false
+	}
+	return q.pending[0], true
+}
+
+// remove removes the commands (like commands which could not be written).
+func (q *replyQueue) remove(ps ...*pendingReply) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = slices.DeleteFunc(q.pending, func(p *pendingReply) bool { return slices.Contains(ps, p) })
+}
+
+// abandon marks the commands as abandoned (like timed out commands): a reply received within the grace period is discarded.
+func (q *replyQueue) abandon(grace time.Duration, ps ...*pendingReply) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	expires := time.Now().Add(grace)
+	for _, p := range ps {
+		p.expires = expires
+	}
+}
+
+// close closes the queue and returns the commands waiting for a reply.
+func (q *replyQueue) close() []*pendingReply {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	ps := q.pending
+	q.pending = nil
+	return ps
+}
+
+// dispatchReply hands a reply to the oldest command waiting for a reply.
+func (c *clientState) dispatchReply(q *replyQueue, reply any) {
+	p, abandoned, ok := q.pop()
+	switch {
+	case !ok:
+		if c.logger != nil {
+			c.logger.Warn("discard unexpected reply", "reply", reply)
+		}
+		return
+	case abandoned:
+		c.stats.staleReplies.Add(1)
+		if c.logger != nil {
+			c.logger.Warn("discard reply of timed out command", "cmd", p.cmd, "reply", reply)
+		}
+		return
+	case p.ch != nil:
+		p.ch <- reply // buffered: never blocks
+		return
+	}
+
+	// async command
+	c.stats.replies.Add(1)
+	switch reply := reply.(type) {
+	case error:
+		c.asyncError(&CallError{Cmd: p.cmd, Args: p.args, Err: reply})
+	case string:
+		if p.done != nil {
+			p.done(reply)
+		}
+	}
+}
+
+// closeReplies fails the commands waiting for a reply after the connection reader is finished.
+func (c *clientState) closeReplies(q *replyQueue) {
+	for _, p := range q.close() {
+		if p.ch != nil {
+			close(p.ch)
+		} else {
+			c.asyncError(&CallError{Cmd: p.cmd, Args: p.args, Err: c.lastReadErr})
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"errors"
+	"time"
+)
+
+// readArgs maps the commands to the number of arguments of their read (idempotent) form.
+// Commands called with a different number of arguments change the command station state
+// and are not retried.
+var readArgs = map[string]int{
+	cmdHelp:              0,
+	cmdBoard:             0,
+	cmdTemp:              0,
+	cmdCV:                1,
+	cmdMTE:               0,
+	cmdShort:             0,
+	cmdDefaultSpeedSteps: 0,
+	cmdClientCount:       0,
+	cmdLocoDir:           1,
+	cmdLocoSpeed128:      1,
+	cmdLocoFct:           2,
+	cmdIOADC:             1,
+	cmdAccFct:            2,
+	cmdAccStatus:         1,
+	cmdIOVal:             2,
+	cmdIODir:             2,
+	cmdIOUp:              2,
+	cmdIODown:            2,
+	cmdRefreshBuffer:     0,
+	cmdFlash:             0,
+}
+
+// isReadCmd returns true if the command is the read form of a command, false otherwise.
+func isReadCmd(cmd string, args []any) bool {
+	n, ok := readArgs[cmd]
+	return ok && n == len(args)
+}
+
+// isTransientError returns true for errors a retry of a command might succeed on.
+func isTransientError(err error) bool {
+	return errors.Is(err, ErrIO) || errors.Is(err, errReadTimeout)
+}
+
+// SetRetry sets the number of retry attempts and the delay between the attempts of read commands
+// (like Board, Temp or LocoSpeed128) failing with a transient error (ErrIO or a read timeout).
+// Commands changing the command station state (like SetLocoSpeed128) are never retried, as they
+// might have been executed by the command station already.
+// Zero attempts (default) disables the retry.
+func (c *Client) SetRetry(attempts int, delay time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retryAttempts, c.retryDelay = attempts, delay
+}
+
+// retryRead re-executes a failed read command fn on transient errors. The client needs to be locked.
+// The lock is released while waiting for the next attempt, so that other commands (and Close)
+// are not blocked by the retry delay.
+func (c *Client) retryRead(err error, cmd string, args []any, fn func() error) error {
+	if !isReadCmd(cmd, args) {
+		return err
+	}
+	for i := 0; i < c.retryAttempts && isTransientError(err); i++ {
+		delay := c.retryDelay
+		c.mu.Unlock()
+		time.Sleep(delay)
+		c.mu.Lock()
+		err = fn()
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package client_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// flakyStation returns a station handler failing each command with an io error n times before
+// replying reply.
+func flakyStation(n int, reply string) (stationHandler, func() int) {
+	var mu sync.Mutex
+	var calls int
+	return func(cmd string, args []string) []string {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			if calls <= n {
+				return []string{"?ioerr"}
+			}
+			return []string{reply}
+		}, func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return calls
+		}
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("Read", func(t *testing.T) {
+		handler, calls := flakyStation(1, "=27.5")
+		c := newTestClient(t, handler, nil, client.WithRetry(2, time.Millisecond))
+
+		temp, err := c.Temp()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if temp != 27.5 || calls() != 2 {
+			t.Fatalf("invalid temperature %g calls %d - expected %g calls %d", temp, calls(), 27.5, 2)
+		}
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		handler, calls := flakyStation(3, "=27.5")
+		c := newTestClient(t, handler, nil)
+		c.SetRetry(2, time.Millisecond)
+
+		if _, err := c.Temp(); !errors.Is(err, client.ErrIO) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrIO)
+		}
+		if calls() != 3 {
+			t.Fatalf("invalid number of calls %d - expected %d", calls(), 3)
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		handler, calls := flakyStation(1, "=42")
+		c := newTestClient(t, handler, nil, client.WithRetry(2, time.Millisecond))
+
+		if _, err := c.SetLocoSpeed128(3, 42); !errors.Is(err, client.ErrIO) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrIO)
+		}
+		if calls() != 1 {
+			t.Fatalf("invalid number of calls %d - expected %d", calls(), 1)
+		}
+		// read form of the same command is retried
+		if _, err := c.LocoSpeed128(3); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Unlocked", func(t *testing.T) {
+		tempHandler, calls := flakyStation(1, "=27.5")
+		conn := client.NewMockConn()
+		conn.HandleFunc(func(cmd string, args []string) []string {
+			if cmd == "t" {
+				return tempHandler(cmd, args)
+			}
+			return mockStation()(cmd, args)
+		})
+		const delay = 500 * time.Millisecond
+		c := client.New(conn, nil, client.WithRetry(1, delay))
+		defer c.Close()
+
+		tempErrCh := startTemp(t, c, conn)
+
+		// the client is not locked while waiting for the retry
+		start := time.Now()
+		if _, err := c.Board(); err != nil {
+			t.Fatal(err)
+		}
+		if d := time.Since(start); d >= delay/2 {
+			t.Fatalf("command blocked by the retry delay for %s", d)
+		}
+
+		if err := <-tempErrCh; err != nil {
+			t.Fatal(err)
+		}
+		if calls() != 2 {
+			t.Fatalf("invalid number of calls %d - expected %d", calls(), 2)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		handler, calls := flakyStation(1, "=27.5")
+		c := newTestClient(t, handler, nil)
+
+		if _, err := c.Temp(); !errors.Is(err, client.ErrIO) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrIO)
+		}
+		if calls() != 1 {
+			t.Fatalf("invalid number of calls %d - expected %d", calls(), 1)
+		}
+	})
+}
@@ -1,14 +1,33 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"runtime"
 	"strings"
+	"time"
 
 	"go.bug.st/serial"
 )
 
-const baudRate = 115200 // default baud rate of the Raspberry Pi pico.
+const (
+	defaultBaudRate    = 115200          // default baud rate of the Raspberry Pi pico.
+	defaultReadTimeout = 1 * time.Second // default serial read timeout
+)
+
+// ErrSerialReadTimeout is returned if the serial read of a line stalls (see SerialConfig).
+var ErrSerialReadTimeout = errors.New("serial read timeout")
+
+// serialOpen opens a serial port (replaceable for testing).
+var serialOpen = serial.Open
+
+// serialPortsList returns the serial port names (replaceable for testing).
+var serialPortsList = serial.GetPortsList
+
+// serialProbeTimeout is the timeout waiting for the board information reply of a probed serial port.
+const serialProbeTimeout = 2 * time.Second
 
 // Serial default port errors.
 var (
@@ -20,7 +39,7 @@ func defaultPortsList() ([]string, error) {
 	if defaultSerialPortPath == "" {
 		return nil, ErrSerialDefaultPortPathMissing
 	}
-	portNames, err := serial.GetPortsList()
+	portNames, err := serialPortsList()
 	if err != nil {
 		return nil, err
 	}
@@ -50,41 +69,205 @@ func SerialDefaultPortName() (string, error) {
 	}
 }
 
+// BoardInfo pairs a serial port name with the information of the board connected to it.
+type BoardInfo struct {
+	PortName string
+	Board
+}
+
+func probeSerialBoard(portName string) (*Board, error) {
+	conn, err := NewSerial(portName)
+	if err != nil {
+		return nil, err
+	}
+	c := NewClient(conn, WithTimeout(serialProbeTimeout))
+	defer c.Close()
+	return c.Board()
+}
+
+// ListSerialBoards returns the boards connected to the default serial ports.
+// In contrast to SerialDefaultPortName multiple boards are supported, so that a board
+// can be addressed by its unique ID instead of the port name.
+// Ports not responding with board information are skipped and logged as warning to logger
+// (nil: not logged).
+func ListSerialBoards(logger *slog.Logger) ([]BoardInfo, error) {
+	portNames, err := defaultPortsList()
+	if err != nil {
+		return nil, err
+	}
+	var boards []BoardInfo
+	for _, portName := range portNames {
+		board, err := probeSerialBoard(portName)
+		if err != nil {
+			if logger != nil {
+				logger.Warn("skip serial port", "port", portName, "error", err)
+			}
+			continue
+		}
+		boards = append(boards, BoardInfo{PortName: portName, Board: *board})
+	}
+	return boards, nil
+}
+
+// SerialConfig represents a serial connection configuration.
+// Zero values are replaced by the defaults (8N1 framing).
+//
+// The read timeout detects stalled reads: if no data is received within the read timeout
+// while a line is partially received, the read fails with ErrSerialReadTimeout.
+// Reads without pending partial line keep waiting, so that an idle command station is not
+// considered as stalled.
+type SerialConfig struct {
+	BaudRate    int             // default 115200
+	DataBits    int             // 5, 6, 7 or 8 (default 8)
+	Parity      serial.Parity   // default serial.NoParity
+	StopBits    serial.StopBits // default serial.OneStopBit
+	ReadTimeout time.Duration   // default 1 second, negative: no timeout
+}
+
+func (cfg *SerialConfig) validate() error {
+	if cfg.BaudRate < 0 {
+		return fmt.Errorf("invalid serial baud rate %d", cfg.BaudRate)
+	}
+	if cfg.DataBits != 0 && (cfg.DataBits < 5 || cfg.DataBits > 8) {
+		return fmt.Errorf("invalid serial data bits %d - expected 5-8", cfg.DataBits)
+	}
+	if cfg.Parity < serial.NoParity || cfg.Parity > serial.SpaceParity {
+		return fmt.Errorf("invalid serial parity %d", cfg.Parity)
+	}
+	switch cfg.StopBits {
+	case serial.OneStopBit:
+	case serial.OnePointFiveStopBits:
+		if cfg.DataBits != 5 {
+			return fmt.Errorf("invalid serial framing - 1.5 stop bits require 5 data bits")
+		}
+	case serial.TwoStopBits:
+		if cfg.DataBits == 5 {
+			return fmt.Errorf("invalid serial framing - 2 stop bits are not supported for 5 data bits")
+		}
+	default:
+		return fmt.Errorf("invalid serial stop bits %d", cfg.StopBits)
+	}
+	return nil
+}
+
 // Serial provides a serial connection to to the Raspberry Pi Pico.
 type Serial struct {
 	portName string
+	cfg      SerialConfig
 	port     serial.Port
 	closed   bool
+	partial  bool // line partially read
 }
 
 // NewSerial returns a new serial connection instance.
-func NewSerial(portName string) (*Serial, error) {
+// An optional configuration overwrites the default configuration.
+func NewSerial(portName string, cfg ...SerialConfig) (*Serial, error) {
+	return NewSerialContext(context.Background(), portName, cfg...)
+}
+
+// NewSerialContext is like NewSerial, but opening the serial port (which might block on a busy port)
+// is aborted if the context is done before, returning the context error.
+func NewSerialContext(ctx context.Context, portName string, cfg ...SerialConfig) (*Serial, error) {
 	s := &Serial{portName: portName}
-	if err := s.Connect(); err != nil {
+	if len(cfg) > 0 {
+		s.cfg = cfg[0]
+	}
+	if err := s.cfg.validate(); err != nil {
+		return nil, err
+	}
+	if s.cfg.BaudRate == 0 {
+		s.cfg.BaudRate = defaultBaudRate
+	}
+	if s.cfg.ReadTimeout == 0 {
+		s.cfg.ReadTimeout = defaultReadTimeout
+	}
+	if err := s.ConnectContext(ctx); err != nil {
 		return nil, err
 	}
 	return s, nil
 }
 
+// PortName returns the serial port name.
+func (s *Serial) PortName() string { return s.portName }
+
+// BaudRate returns the configured baud rate.
+func (s *Serial) BaudRate() int { return s.cfg.BaudRate }
+
+// Config returns the serial connection configuration.
+func (s *Serial) Config() SerialConfig { return s.cfg }
+
 // Connect connect the serial port.s
-func (s *Serial) Connect() error {
+func (s *Serial) Connect() error { return s.ConnectContext(context.Background()) }
+
+// ConnectContext connects the serial port. If the context is done before the port is opened
+// the context error is returned.
+func (s *Serial) ConnectContext(ctx context.Context) error {
 	mode := &serial.Mode{
-		BaudRate: baudRate,
+		BaudRate: s.cfg.BaudRate,
+		DataBits: s.cfg.DataBits,
+		Parity:   s.cfg.Parity,
+		StopBits: s.cfg.StopBits,
 	}
-	var err error
-	s.port, err = serial.Open(s.portName, mode)
-	if err != nil {
-		return fmt.Errorf("error opening serial device: %s - %w", s.portName, err)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	type result struct {
+		port serial.Port
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		port, err := serialOpen(s.portName, mode)
+		ch <- result{port: port, err: err}
+	}()
+
+	var r result
+	select {
+	case r = <-ch:
+	case <-ctx.Done():
+		go func() { // close the port opened after the context was done
+			if r := <-ch; r.err == nil {
+				r.port.Close()
+			}
+		}()
+		return ctx.Err()
+	}
+	if r.err != nil {
+		return fmt.Errorf("error opening serial device: %s - %w", s.portName, r.err)
+	}
+	s.port = r.port
+	readTimeout := s.cfg.ReadTimeout
+	if readTimeout < 0 {
+		readTimeout = serial.NoTimeout
+	}
+	if err := s.port.SetReadTimeout(readTimeout); err != nil {
+		s.port.Close()
+		return fmt.Errorf("error setting serial read timeout: %s - %w", s.portName, err)
 	}
 	s.port.ResetInputBuffer()  //nolint: errcheck
 	s.port.ResetOutputBuffer() //nolint: errcheck
 	s.closed = false
+	s.partial = false
 	return nil
 }
 
 // Read implements the Conn interface.
 func (s *Serial) Read(p []byte) (n int, err error) {
-	return s.port.Read(p)
+	for {
+		n, err = s.port.Read(p)
+		if n > 0 {
+			s.partial = p[n-1] != '\n'
+			return n, err
+		}
+		if err != nil {
+			return 0, err
+		}
+		// read timeout
+		if s.partial {
+			return 0, fmt.Errorf("%w after %s: %s", ErrSerialReadTimeout, s.cfg.ReadTimeout, s.portName)
+		}
+	}
 }
 
 // Write implements the Conn interface.
@@ -0,0 +1,298 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// fakePort is a serial port recording the mode it was opened with.
+// Reads return the pending data and time out afterwards.
+type fakePort struct {
+	serial.Port
+	mode        *serial.Mode
+	readTimeout time.Duration
+	data        []byte
+}
+
+func (p *fakePort) SetReadTimeout(t time.Duration) error { p.readTimeout = t; return nil }
+func (p *fakePort) ResetInputBuffer() error              { return nil }
+func (p *fakePort) ResetOutputBuffer() error             { return nil }
+func (p *fakePort) Read(b []byte) (int, error) {
+	n := copy(b, p.data)
+	p.data = p.data[n:]
+	return n, nil
+}
+func (p *fakePort) Write(b []byte) (int, error) { return len(b), nil }
+func (p *fakePort) Close() error                { return nil }
+
+// fakeSerialOpen replaces the serial open function by a fake for the test duration.
+func fakeSerialOpen(t *testing.T) **fakePort {
+	var port *fakePort
+	serialOpen = func(portName string, mode *serial.Mode) (serial.Port, error) {
+		port = &fakePort{mode: mode}
+		return port, nil
+	}
+	t.Cleanup(func() { serialOpen = serial.Open })
+	return &port
+}
+
+func TestSerialBaudRate(t *testing.T) {
+	port := fakeSerialOpen(t)
+
+	tests := []struct {
+		cfg      []SerialConfig
+		baudRate int
+	}{
+		{nil, defaultBaudRate},
+		{[]SerialConfig{{}}, defaultBaudRate},
+		{[]SerialConfig{{BaudRate: 9600}}, 9600},
+	}
+
+	for _, test := range tests {
+		s, err := NewSerial("/dev/fake", test.cfg...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.BaudRate() != test.baudRate {
+			t.Errorf("invalid baud rate %d - expected %d", s.BaudRate(), test.baudRate)
+		}
+		if (*port).mode.BaudRate != test.baudRate {
+			t.Errorf("invalid port baud rate %d - expected %d", (*port).mode.BaudRate, test.baudRate)
+		}
+	}
+}
+
+func TestSerialFraming(t *testing.T) {
+	port := fakeSerialOpen(t)
+
+	cfg := SerialConfig{DataBits: 7, Parity: serial.EvenParity, StopBits: serial.TwoStopBits}
+	if _, err := NewSerial("/dev/fake", cfg); err != nil {
+		t.Fatal(err)
+	}
+	mode := (*port).mode
+	if mode.DataBits != 7 || mode.Parity != serial.EvenParity || mode.StopBits != serial.TwoStopBits {
+		t.Fatalf("invalid mode %+v - expected %+v", mode, cfg)
+	}
+}
+
+func TestSerialConfigValidation(t *testing.T) {
+	fakeSerialOpen(t)
+
+	tests := []struct {
+		name string
+		cfg  SerialConfig
+		ok   bool
+	}{
+		{"Default", SerialConfig{}, true},
+		{"8N1", SerialConfig{DataBits: 8, Parity: serial.NoParity, StopBits: serial.OneStopBit}, true},
+		{"5N1.5", SerialConfig{DataBits: 5, StopBits: serial.OnePointFiveStopBits}, true},
+		{"InvalidBaudRate", SerialConfig{BaudRate: -1}, false},
+		{"InvalidDataBits", SerialConfig{DataBits: 9}, false},
+		{"InvalidParity", SerialConfig{Parity: serial.SpaceParity + 1}, false},
+		{"InvalidStopBits", SerialConfig{StopBits: serial.TwoStopBits + 1}, false},
+		{"8N1.5", SerialConfig{DataBits: 8, StopBits: serial.OnePointFiveStopBits}, false},
+		{"5N2", SerialConfig{DataBits: 5, StopBits: serial.TwoStopBits}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewSerial("/dev/fake", test.cfg)
+			if (err == nil) != test.ok {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+// mockPort is a serial port backed by a mock connection.
+type mockPort struct {
+	serial.Port
+	conn *MockConn
+}
+
+func (p *mockPort) SetReadTimeout(t time.Duration) error { return nil }
+func (p *mockPort) ResetInputBuffer() error              { return nil }
+func (p *mockPort) ResetOutputBuffer() error             { return nil }
+func (p *mockPort) Read(b []byte) (int, error)           { return p.conn.Read(b) }
+func (p *mockPort) Write(b []byte) (int, error)          { return p.conn.Write(b) }
+func (p *mockPort) Close() error                         { return p.conn.Close() }
+
+func TestListSerialBoards(t *testing.T) {
+	if defaultSerialPortPath == "" {
+		t.Skipf("no default serial port path for %s", runtime.GOOS)
+	}
+
+	boardReplies := map[string]string{
+		defaultSerialPortPath + "0": "=pico E66038B713849D31",
+		defaultSerialPortPath + "1": "?invcmd", // not responding with board information
+		defaultSerialPortPath + "3": "=pico_w E66038B713849D32 28:cd:c1:00:00:00",
+	}
+
+	serialPortsList = func() ([]string, error) {
+		return []string{defaultSerialPortPath + "0", defaultSerialPortPath + "1", defaultSerialPortPath + "2", defaultSerialPortPath + "3", "/dev/other"}, nil
+	}
+	serialOpen = func(portName string, mode *serial.Mode) (serial.Port, error) {
+		reply, ok := boardReplies[portName]
+		if !ok {
+			return nil, errors.New("port busy")
+		}
+		conn := NewMockConn()
+		conn.Reply(cmdBoard, reply)
+		return &mockPort{conn: conn}, nil
+	}
+	t.Cleanup(func() {
+		serialPortsList = serial.GetPortsList
+		serialOpen = serial.Open
+	})
+
+	var buf bytes.Buffer
+	boards, err := ListSerialBoards(slog.New(slog.NewTextHandler(&buf, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []BoardInfo{
+		{PortName: defaultSerialPortPath + "0", Board: Board{Type: BtPico, ID: "E66038B713849D31"}},
+		{PortName: defaultSerialPortPath + "3", Board: Board{Type: BtPicoW, ID: "E66038B713849D32", MAC: "28:cd:c1:00:00:00"}},
+	}
+	if !reflect.DeepEqual(boards, expected) {
+		t.Fatalf("invalid boards %v - expected %v", boards, expected)
+	}
+	if n := strings.Count(buf.String(), "skip serial port"); n != 2 {
+		t.Fatalf("invalid number of skipped port warnings %d - expected %d", n, 2)
+	}
+}
+
+func TestSerialReadTimeout(t *testing.T) {
+	port := fakeSerialOpen(t)
+
+	tests := []struct {
+		cfg         SerialConfig
+		readTimeout time.Duration
+	}{
+		{SerialConfig{}, defaultReadTimeout},
+		{SerialConfig{ReadTimeout: 200 * time.Millisecond}, 200 * time.Millisecond},
+		{SerialConfig{ReadTimeout: -1}, serial.NoTimeout},
+	}
+
+	for _, test := range tests {
+		if _, err := NewSerial("/dev/fake", test.cfg); err != nil {
+			t.Fatal(err)
+		}
+		if (*port).readTimeout != test.readTimeout {
+			t.Errorf("invalid port read timeout %s - expected %s", (*port).readTimeout, test.readTimeout)
+		}
+	}
+}
+
+func TestSerialStalledRead(t *testing.T) {
+	port := fakeSerialOpen(t)
+
+	s, err := NewSerial("/dev/fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	(*port).data = []byte("=27") // partial reply line: the station stops responding mid-line
+
+	c := New(s, nil)
+	defer c.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Temp()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrSerialReadTimeout) {
+			t.Fatalf("invalid error %v - expected %v", err, ErrSerialReadTimeout)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("read blocked")
+	}
+}
+
+// closePort is a serial port recording its close.
+type closePort struct {
+	fakePort
+	closed chan struct{}
+}
+
+func (p *closePort) Close() error { close(p.closed); return nil }
+
+func TestNewSerialContext(t *testing.T) {
+	release := make(chan struct{})
+	port := &closePort{closed: make(chan struct{})}
+	serialOpen = func(portName string, mode *serial.Mode) (serial.Port, error) {
+		<-release // busy port
+		return port, nil
+	}
+	t.Cleanup(func() { serialOpen = serial.Open })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := NewSerialContext(ctx, "/dev/fake"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("invalid error %v - expected %v", err, context.DeadlineExceeded)
+	}
+
+	// the port opened after the cancellation is closed
+	close(release)
+	select {
+	case <-port.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("port opened after cancellation not closed")
+	}
+}
+
+func TestSerialConfig(t *testing.T) {
+	serialOpen = func(portName string, mode *serial.Mode) (serial.Port, error) {
+		return &mockPort{conn: NewMockConn()}, nil
+	}
+	t.Cleanup(func() { serialOpen = serial.Open })
+
+	cfg := Config{
+		Transport:         TransportSerial,
+		PortName:          "/dev/fake",
+		BaudRate:          9600,
+		DataBits:          7,
+		Parity:            serial.EvenParity,
+		StopBits:          serial.TwoStopBits,
+		SerialReadTimeout: 200 * time.Millisecond,
+		Timeout:           5 * time.Second,
+		WriteTimeout:      defaultWriteTimeout,
+		PushBufferSize:    defaultPushBufSize,
+		MaxLineSize:       DefaultMaxLineSize,
+		EchoSkip:          true,
+	}
+
+	// json round trip
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonCfg Config
+	if err := json.Unmarshal(b, &jsonCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewFromConfig(&jsonCfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if effCfg := c.Config(); *effCfg != cfg {
+		t.Fatalf("invalid effective configuration %+v - expected %+v", effCfg, cfg)
+	}
+}
@@ -0,0 +1,92 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestShortLatch(t *testing.T) {
+	latched := true
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		switch cmd {
+		case "h":
+			return []string{"-short [f]: short circuit latch", "."}
+		case "short":
+		default:
+			return []string{"?invcmd"}
+		}
+		if len(args) == 1 && args[0] == "f" {
+			latched = false
+		}
+		if latched {
+			return []string{"=t"}
+		}
+		return []string{"=f"}
+	}, nil)
+
+	if latched, err := c.ShortLatched(); err != nil || !latched {
+		t.Fatalf("invalid latch %t error %v - expected %t", latched, err, true)
+	}
+	if err := c.ClearShort(); err != nil {
+		t.Fatal(err)
+	}
+	if latched, err := c.ShortLatched(); err != nil || latched {
+		t.Fatalf("invalid latch %t error %v - expected %t", latched, err, false)
+	}
+}
+
+func TestShortLatchNotImpl(t *testing.T) {
+	// command station not providing the short circuit latch command
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		if cmd == "h" {
+			return []string{"-h: help", "."}
+		}
+		return []string{"?invcmd"}
+	}, nil)
+
+	if _, err := c.ShortLatched(); !errors.Is(err, client.ErrNotImpl) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrNotImpl)
+	}
+	if err := c.ClearShort(); !errors.Is(err, client.ErrNotImpl) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrNotImpl)
+	}
+}
+
+// ExampleShortCircuitMsg shows how to alert the operator on a short circuit and to re-enable the track power.
+func ExampleShortCircuitMsg() {
+	conn := client.NewMockConn()
+	conn.Reply("h", "-short [f]: short circuit latch", ".")
+	conn.Reply("short f", "=f")
+	conn.Reply("mte t", "=t")
+
+	shorts := make(chan *client.ShortCircuitMsg, 1)
+	c := client.New(conn, func(msg client.Msg, err error) {
+		if msg, ok := msg.(*client.ShortCircuitMsg); ok {
+			shorts <- msg
+		}
+	})
+	defer c.Close()
+
+	conn.Push("short: 1") // simulate a short circuit on section 1
+
+	msg := <-shorts
+	fmt.Printf("short circuit on section %d\n", msg.Section)
+
+	// after the short circuit is removed (operator confirmation)
+	if err := c.ClearShort(); err != nil {
+		log.Fatal(err)
+	}
+	enabled, err := c.SetMTE(true)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("track power enabled %t\n", enabled)
+
+	// Output:
+	// short circuit on section 1
+	// track power enabled true
+}
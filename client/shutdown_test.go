@@ -0,0 +1,79 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// startTemp calls Temp concurrently and waits until the command was written.
+func startTemp(t *testing.T, c *client.Client, conn *client.MockConn) <-chan error {
+	t.Helper()
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := c.Temp()
+		errCh <- err
+	}()
+	for !slices.Contains(conn.Written(), "t") {
+		time.Sleep(time.Millisecond)
+	}
+	return errCh
+}
+
+func TestShutdown(t *testing.T) {
+	t.Run("InFlight", func(t *testing.T) {
+		conn := client.NewMockConn()
+		conn.HandleFunc(func(cmd string, args []string) []string { return nil }) // reply is injected
+		c := client.New(conn, nil)
+
+		tempErrCh := startTemp(t, c, conn)
+
+		shutdownErrCh := make(chan error, 1)
+		go func() { shutdownErrCh <- c.Shutdown(context.Background()) }()
+
+		select {
+		case err := <-shutdownErrCh:
+			t.Fatalf("shutdown did not wait for in-flight command - %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		conn.Inject("=27.5")
+		if err := <-tempErrCh; err != nil {
+			t.Fatal(err)
+		}
+		if err := <-shutdownErrCh; err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Board(); !errors.Is(err, client.ErrClientClosed) {
+			t.Fatalf("invalid error %v - expected %v", err, client.ErrClientClosed)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		conn := client.NewMockConn()
+		conn.HandleFunc(func(cmd string, args []string) []string { return nil }) // no reply
+		c := client.New(conn, nil)
+
+		tempErrCh := startTemp(t, c, conn)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("invalid error %v - expected %v", err, context.DeadlineExceeded)
+		}
+		if err := <-tempErrCh; err == nil {
+			t.Fatal("expected error of in-flight command")
+		}
+	})
+
+	t.Run("Idle", func(t *testing.T) {
+		c := client.New(client.NewMockConn(), nil)
+		if err := c.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package client_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestSpeedConversion(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(uint) uint
+		in   uint
+		out  uint
+	}{
+		{"From14", client.SpeedFrom14, 0, 0},
+		{"From14", client.SpeedFrom14, 1, 1},
+		{"From14", client.SpeedFrom14, 2, 10},
+		{"From14", client.SpeedFrom14, 15, 127},
+		{"From14", client.SpeedFrom14, 16, 127},
+		{"To14", client.SpeedTo14, 0, 0},
+		{"To14", client.SpeedTo14, 1, 1},
+		{"To14", client.SpeedTo14, 2, 2},
+		{"To14", client.SpeedTo14, 10, 2},
+		{"To14", client.SpeedTo14, 127, 15},
+		{"From28", client.SpeedFrom28, 0, 0},
+		{"From28", client.SpeedFrom28, 1, 1},
+		{"From28", client.SpeedFrom28, 2, 6},
+		{"From28", client.SpeedFrom28, 29, 127},
+		{"To28", client.SpeedTo28, 0, 0},
+		{"To28", client.SpeedTo28, 1, 1},
+		{"To28", client.SpeedTo28, 2, 2},
+		{"To28", client.SpeedTo28, 6, 2},
+		{"To28", client.SpeedTo28, 127, 29},
+		{"To28", client.SpeedTo28, 200, 29},
+	}
+
+	for _, test := range tests {
+		if out := test.fct(test.in); out != test.out {
+			t.Errorf("%s(%d) = %d - expected %d", test.name, test.in, out, test.out)
+		}
+	}
+}
+
+func TestSpeedRoundTrip(t *testing.T) {
+	for step := uint(0); step <= client.MaxSpeed28; step++ {
+		if out := client.SpeedTo28(client.SpeedFrom28(step)); out != step {
+			t.Errorf("28 speed step %d converted to %d", step, out)
+		}
+	}
+	for step := uint(0); step <= client.MaxSpeed14; step++ {
+		if out := client.SpeedTo14(client.SpeedFrom14(step)); out != step {
+			t.Errorf("14 speed step %d converted to %d", step, out)
+		}
+	}
+}
+
+func TestDefaultSpeedSteps(t *testing.T) {
+	steps := "128"
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		switch cmd {
+		case "h":
+			return []string{"-dss [<steps>]: default speed steps", "."}
+		case "dss":
+			if len(args) == 1 {
+				steps = args[0]
+			}
+			return []string{"=" + steps}
+		}
+		return []string{"?invcmd"}
+	}, nil)
+
+	for _, mode := range []client.SpeedSteps{client.SpeedSteps14, client.SpeedSteps28, client.SpeedSteps128} {
+		if v, err := c.SetDefaultSpeedSteps(mode); err != nil || v != mode {
+			t.Fatalf("set speed steps %d: got %d error %v", mode, v, err)
+		}
+		if v, err := c.DefaultSpeedSteps(); err != nil || v != mode {
+			t.Fatalf("speed steps %d: got %d error %v", mode, v, err)
+		}
+	}
+
+	if _, err := c.SetDefaultSpeedSteps(27); err == nil {
+		t.Fatal("expected error on invalid speed steps")
+	}
+}
+
+func TestDefaultSpeedStepsNotImpl(t *testing.T) {
+	var cmds []string
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		cmds = append(cmds, cmd)
+		if cmd == "h" {
+			return []string{"-h: help", "."}
+		}
+		return []string{"?invcmd"}
+	}, nil)
+
+	if _, err := c.DefaultSpeedSteps(); !errors.Is(err, client.ErrNotImpl) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrNotImpl)
+	}
+	if _, err := c.SetDefaultSpeedSteps(client.SpeedSteps28); !errors.Is(err, client.ErrNotImpl) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrNotImpl)
+	}
+	if expected := []string{"h"}; !slices.Equal(cmds, expected) {
+		t.Fatalf("invalid commands %v - expected %v", cmds, expected)
+	}
+}
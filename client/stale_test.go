@@ -0,0 +1,58 @@
+package client_test
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// slowStation serves conn replying the n-th command with its number n, the first command
+// is replied after delay.
+func slowStation(conn net.Conn, delay time.Duration) {
+	go func() {
+		r := bufio.NewReader(conn)
+		for n := 1; ; n++ {
+			if _, err := r.ReadString('\r'); err != nil {
+				return
+			}
+			if n == 1 {
+				time.Sleep(delay)
+			}
+			if _, err := fmt.Fprintf(conn, "=%d\n", n); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func TestStaleReply(t *testing.T) {
+	const timeout = 20 * time.Millisecond
+
+	clientConn, stationConn := net.Pipe()
+	slowStation(stationConn, 3*timeout/2)
+	c := client.New(&pipeConn{Conn: clientConn}, nil, client.WithTimeout(timeout))
+	t.Cleanup(func() {
+		c.Close()
+		stationConn.Close()
+	})
+
+	if _, err := c.Temp(); err == nil {
+		t.Fatal("expected timeout error")
+	}
+
+	// the late reply of the first command must not be returned to the second command
+	temp, err := c.Temp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp != 2 {
+		t.Fatalf("invalid reply %g - expected %d (reply of the second command)", temp, 2)
+	}
+	if stale := c.Stats().Stale; stale != 1 {
+		t.Fatalf("invalid number of stale replies %d - expected %d", stale, 1)
+	}
+}
@@ -0,0 +1,206 @@
+package client
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ioKey identifies an io command value of a GPIO (like the direction).
+type ioKey struct {
+	name      string // io command (like "iodir")
+	cmd, gpio uint
+}
+
+// stateCache stores the last commanded loco values and GPIO configuration to be replayed by Resync.
+// In contrast to the cache (see WithCache) the state is kept over reconnects. A nil state cache is disabled.
+type stateCache struct {
+	mu     sync.Mutex
+	speeds map[uint]uint
+	dirs   map[uint]bool
+	fcts   map[fctKey]bool
+	ios    map[ioKey]bool
+}
+
+func newStateCache() *stateCache {
+	return &stateCache{speeds: map[uint]uint{}, dirs: map[uint]bool{}, fcts: map[fctKey]bool{}, ios: map[ioKey]bool{}}
+}
+
+// setSpeed records the speed of a loco. An emergency stop is recorded as stop,
+// so that it is not replayed as normal speed.
+func (s *stateCache) setSpeed(addr, speed uint) {
+	if s == nil {
+		return
+	}
+	if speed == SpeedEStop {
+		speed = SpeedStop
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.speeds[addr] = speed
+}
+
+func (s *stateCache) setDir(addr uint, dir bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dirs[addr] = dir
+}
+
+func (s *stateCache) setFct(addr, no uint, fct bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fcts[fctKey{addr: addr, no: no}] = fct
+}
+
+func (s *stateCache) setIO(name string, cmd, gpio uint, value bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ios[ioKey{name: name, cmd: cmd, gpio: gpio}] = value
+}
+
+// setResults records the loco and io command values of the successful batch results
+// (results[i] is the result of cmds[i]).
+func (s *stateCache) setResults(cmds []batchCmd, results []BatchResult) {
+	if s == nil {
+		return
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		cmd := cmds[i]
+		switch cmd.cmd {
+		case cmdLocoSpeed128:
+			if speed, err := parseUint(result.Reply); err == nil {
+				s.setSpeed(cmd.args[0].(uint), speed)
+			}
+		case cmdLocoDir:
+			if dir, err := parseBool(result.Reply); err == nil {
+				s.setDir(cmd.args[0].(uint), dir)
+			}
+		case cmdLocoFct:
+			if fct, err := parseBool(result.Reply); err == nil {
+				s.setFct(cmd.args[0].(uint), cmd.args[1].(uint), fct)
+			}
+		case cmdIOVal, cmdIODir, cmdIOUp, cmdIODown:
+			if v, err := parseBool(result.Reply); err == nil {
+				s.setIO(cmd.cmd, cmd.args[0].(uint), cmd.args[1].(uint), v)
+			}
+		}
+	}
+}
+
+func (s *stateCache) deleteLoco(addr uint) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.speeds, addr)
+	delete(s.dirs, addr)
+	for key := range s.fcts {
+		if key.addr == addr {
+			delete(s.fcts, key)
+		}
+	}
+}
+
+func (s *stateCache) resetLocos() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.speeds)
+	clear(s.dirs)
+	clear(s.fcts)
+}
+
+// ioOrder is the replay order of the io commands: the direction is set last,
+// so that an output GPIO is driven with the recorded value right away (see ConfigureGPIO).
+var ioOrder = map[string]int{cmdIOUp: 0, cmdIODown: 1, cmdIOVal: 2, cmdIODir: 3}
+
+// replay queues the commands restoring the recorded state: the GPIO configuration first and the locos
+// afterwards, setting the speed of a loco after its direction and functions.
+func (s *stateCache) replay(b *Batch) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ioKeys := make([]ioKey, 0, len(s.ios))
+	for key := range s.ios {
+		ioKeys = append(ioKeys, key)
+	}
+	slices.SortFunc(ioKeys, func(a, b ioKey) int {
+		return cmp.Or(cmp.Compare(a.cmd, b.cmd), cmp.Compare(a.gpio, b.gpio), cmp.Compare(ioOrder[a.name], ioOrder[b.name]))
+	})
+	for _, key := range ioKeys {
+		b.addIO(key.name, key.cmd, key.gpio, s.ios[key])
+	}
+
+	addrSet := map[uint]bool{}
+	for addr := range s.speeds {
+		addrSet[addr] = true
+	}
+	for addr := range s.dirs {
+		addrSet[addr] = true
+	}
+	fctKeys := make([]fctKey, 0, len(s.fcts))
+	for key := range s.fcts {
+		addrSet[key.addr] = true
+		fctKeys = append(fctKeys, key)
+	}
+	slices.SortFunc(fctKeys, func(a, b fctKey) int { return cmp.Or(cmp.Compare(a.addr, b.addr), cmp.Compare(a.no, b.no)) })
+	addrs := make([]uint, 0, len(addrSet))
+	for addr := range addrSet {
+		addrs = append(addrs, addr)
+	}
+	slices.Sort(addrs)
+
+	for _, addr := range addrs {
+		if dir, ok := s.dirs[addr]; ok {
+			b.SetLocoDir(addr, dir)
+		}
+		for _, key := range fctKeys {
+			if key.addr == addr {
+				b.SetLocoFct(addr, key.no, s.fcts[key])
+			}
+		}
+		if speed, ok := s.speeds[addr]; ok {
+			b.SetLocoSpeed128(addr, speed)
+		}
+	}
+}
+
+// errStateCacheDisabled is returned by Resync if the state cache is not enabled.
+var errStateCacheDisabled = errors.New("state cache disabled (see WithStateCache)")
+
+// Resync replays the recorded loco values and GPIO configuration (see WithStateCache) to the command station,
+// so that the intended layout state is restored after a reconnect or a command station reboot (like after a power blip).
+// The GPIO configuration is replayed first, the locos afterwards. The commands are pipelined (see Batch).
+// An error of a single command does not stop the replay of the remaining commands, the returned error
+// combines the errors of the failed commands.
+func (c *Client) Resync() error {
+	if c.state == nil {
+		return errStateCacheDisabled
+	}
+	b := c.Batch()
+	c.state.replay(b)
+	if len(b.cmds) == 0 {
+		return nil
+	}
+	if _, err := b.Run(); err != nil {
+		return fmt.Errorf("resync - %w", err)
+	}
+	return nil
+}
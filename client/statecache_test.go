@@ -0,0 +1,121 @@
+package client_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+	"github.com/pico-cs/go-client/client/fakecs"
+)
+
+func TestResync(t *testing.T) {
+
+	t.Run("Replay", func(t *testing.T) {
+		conn := client.NewMockConn()
+		conn.HandleFunc(fakecs.New().Handle)
+		c := client.NewClient(conn, client.WithStateCache())
+		defer c.Close()
+
+		const (
+			out, in    = 2, 9 // GPIOs
+			loco, stop = 3, 4 // loco addresses
+		)
+		steps := []func() error{
+			func() error { _, err := c.SetIODir(client.IOCmdLocal, out, true); return err },
+			func() error { _, err := c.SetIOVal(client.IOCmdLocal, out, true); return err },
+			func() error { _, err := c.SetIOUp(client.IOCmdLocal, in, true); return err },
+			func() error { _, err := c.SetLocoSpeed128(loco, 50); return err },
+			func() error { _, err := c.ToggleLocoDir(loco); return err },
+			func() error { _, err := c.SetLocoFct(loco, 0, true); return err },
+			func() error { _, err := c.SetLocoSpeed128(stop, 80); return err },
+			func() error { _, err := c.SetLocoSpeed128(stop, client.SpeedEStop); return err },
+		}
+		for _, step := range steps {
+			if err := step(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		dir, err := c.LocoDir(loco)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// command station reboot: state is lost
+		conn.Disconnect(nil)
+		conn.HandleFunc(fakecs.New().Handle)
+		if err := c.Reconnect(); err != nil {
+			t.Fatal(err)
+		}
+		n := len(conn.Written())
+		if err := c.Resync(); err != nil {
+			t.Fatal(err)
+		}
+
+		replayed := conn.Written()[n:]
+		expected := []string{
+			"ioval 0 2 t", "iodir 0 2 t", "ioup 0 9 t",
+			"ld 3 " + map[bool]string{false: "f", true: "t"}[dir], "lf 3 0 t", "ls 3 50",
+			"ls 4 0", // emergency stop is replayed as stop
+		}
+		if !slices.Equal(replayed, expected) {
+			t.Fatalf("invalid replayed commands %v - expected %v", replayed, expected)
+		}
+
+		checks := []struct {
+			name string
+			get  func() (any, error)
+			v    any
+		}{
+			{"out dir", func() (any, error) { return c.IODir(client.IOCmdLocal, out) }, true},
+			{"out val", func() (any, error) { return c.IOVal(client.IOCmdLocal, out) }, true},
+			{"in up", func() (any, error) { return c.IOUp(client.IOCmdLocal, in) }, true},
+			{"loco speed", func() (any, error) { return c.LocoSpeed128(loco) }, uint(50)},
+			{"loco dir", func() (any, error) { return c.LocoDir(loco) }, dir},
+			{"loco fct", func() (any, error) { return c.LocoFct(loco, 0) }, true},
+			{"stop speed", func() (any, error) { return c.LocoSpeed128(stop) }, uint(client.SpeedStop)},
+		}
+		for _, check := range checks {
+			v, err := check.get()
+			if err != nil {
+				t.Fatalf("%s: %s", check.name, err)
+			}
+			if v != check.v {
+				t.Fatalf("%s: invalid value %v - expected %v", check.name, v, check.v)
+			}
+		}
+	})
+
+	t.Run("MultiRamp", func(t *testing.T) {
+		conn := client.NewMockConn()
+		conn.HandleFunc(fakecs.New().Handle)
+		c := client.NewClient(conn, client.WithStateCache())
+		defer c.Close()
+
+		if _, err := c.SetLocoSpeed128(3, 10); err != nil {
+			t.Fatal(err)
+		}
+		// speeds set via batch are recorded
+		if err := c.MultiRamp(context.Background(), map[uint]uint{3: 40, 5: 20}, time.Millisecond, 50); err != nil {
+			t.Fatal(err)
+		}
+
+		n := len(conn.Written())
+		if err := c.Resync(); err != nil {
+			t.Fatal(err)
+		}
+		replayed := conn.Written()[n:]
+		if expected := []string{"ls 3 40", "ls 5 20"}; !slices.Equal(replayed, expected) {
+			t.Fatalf("invalid replayed commands %v - expected %v", replayed, expected)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		c := client.NewClient(client.NewMockConn())
+		defer c.Close()
+		if err := c.Resync(); err == nil {
+			t.Fatal("missing state cache disabled error")
+		}
+	})
+}
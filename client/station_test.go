@@ -0,0 +1,140 @@
+package client_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+	"github.com/pico-cs/go-client/client/rbuf"
+)
+
+// stationHandler returns the reply lines (including tags) of a command.
+type stationHandler = client.MockHandler
+
+func scanCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\r'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// runStation simulates a command station replying to the commands written by the client.
+func runStation(conn net.Conn, handler stationHandler) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Split(scanCR)
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimPrefix(scanner.Text(), "+"), " ")
+		for _, line := range handler(fields[0], fields[1:]) {
+			if _, err := conn.Write([]byte(line + "\r\n")); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// recordStation returns a test station handler recording the commands handled by handler.
+func recordStation(mu *sync.Mutex, cmds *[]string, handler stationHandler) stationHandler {
+	return func(cmd string, args []string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		*cmds = append(*cmds, strings.Join(append([]string{cmd}, args...), " "))
+		return handler(cmd, args)
+	}
+}
+
+// newTestClient returns a client connected to a test station via a mock connection.
+func newTestClient(t *testing.T, handler stationHandler, pushHandler func(msg client.Msg, err error), opts ...client.Option) *client.Client {
+	conn := client.NewMockConn()
+	conn.HandleFunc(handler)
+	c := client.New(conn, pushHandler, opts...)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// refreshBufferReply returns the refresh buffer multi-reply lines of a buffer containing addrs.
+func refreshBufferReply(addrs ...uint) []string {
+	n := len(addrs)
+	lines := []string{fmt.Sprintf("-%d %d", 0, 0)}
+	for i, addr := range addrs {
+		var entry rbuf.Entry
+		entry[rbuf.Idx] = byte(i)
+		entry[rbuf.MSB] = byte(addr >> 8)
+		entry[rbuf.LSB] = byte(addr)
+		entry[rbuf.Prev] = byte((i + n - 1) % n)
+		entry[rbuf.Next] = byte((i + 1) % n)
+		values := make([]string, len(entry))
+		for j, v := range entry {
+			values[j] = strconv.Itoa(int(v))
+		}
+		lines = append(lines, "-"+strings.Join(values, " "))
+	}
+	return append(lines, ".")
+}
+
+// mockStation returns a test station handler implementing the commands used by the client test harness.
+func mockStation() stationHandler {
+	syncBits := 17
+	mte := false
+	locos := map[uint]uint{}
+
+	return func(cmd string, args []string) []string {
+		switch cmd {
+		case "h":
+			return []string{"-b: board info", "-t: temperature", "."}
+		case "b":
+			return []string{"=pico_w E66038B713849D31 28:cd:c1:00:00:00"}
+		case "t":
+			return []string{"=27.5"}
+		case "cv":
+			if len(args) == 2 {
+				v, _ := strconv.Atoi(args[1])
+				syncBits = min(max(v, 17), 32)
+			}
+			return []string{fmt.Sprintf("=%d", syncBits)}
+		case "mte":
+			if len(args) == 1 {
+				mte = args[0] == "t"
+			}
+			return []string{fmt.Sprintf("=%c", map[bool]byte{true: 't', false: 'f'}[mte])}
+		case "ls":
+			addr, _ := strconv.Atoi(args[0])
+			speed, _ := strconv.Atoi(args[1])
+			locos[uint(addr)] = uint(speed)
+			return []string{"=" + args[1]}
+		case "rr":
+			clear(locos)
+			return []string{"=t"}
+		case "rd":
+			addr, _ := strconv.Atoi(args[0])
+			delete(locos, uint(addr))
+			return []string{"=" + args[0]}
+		case "r":
+			addrs := make([]uint, 0, len(locos))
+			for addr := range locos {
+				addrs = append(addrs, addr)
+			}
+			slices.Sort(addrs)
+			return refreshBufferReply(addrs...)
+		case "f":
+			return []string{"-0 0 0", "-ff ff ff ff", "."}
+		case "reboot":
+			return nil
+		}
+		return []string{"?invcmd"}
+	}
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Stats represents a snapshot of the client connection and command statistics.
+type Stats struct {
+	Cmds       uint64         // commands sent
+	Replies    uint64         // replies received (including command station errors)
+	Timeouts   uint64         // read timeouts
+	Stale      uint64         // discarded replies of timed out commands
+	Reconnects uint64         // successful reconnects
+	BytesIn    uint64         // bytes read from the connection
+	BytesOut   uint64         // bytes written to the connection
+	Push       map[int]uint64 // push messages by kind (MkUnknown: unknown or invalid messages)
+	PushDrops  uint64         // push messages dropped on push buffer overflow (see WithPushBuffer) or in synchronous mode (see NewSync)
+	Latency    time.Duration  // rolling round trip time estimate of successful commands with reply (zero: none yet)
+}
+
+// stats holds the client counters.
+type stats struct {
+	cmds, replies, timeouts, reconnects atomic.Uint64
+	staleReplies, droppedPush           atomic.Uint64
+	bytesIn, bytesOut                   atomic.Uint64
+	push                                [mkNum]atomic.Uint64
+	latency                             atomic.Int64 // exponentially weighted moving average in nanoseconds
+}
+
+// latencyWeight is the inverse weight of a new round trip time in the latency estimate.
+const latencyWeight = 8
+
+// observeLatency updates the latency estimate by the round trip time d.
+func (s *stats) observeLatency(d time.Duration) {
+	for {
+		old := s.latency.Load()
+		avg := int64(d)
+		if old != 0 {
+			avg = old + (int64(d)-old)/latencyWeight
+		}
+		if s.latency.CompareAndSwap(old, avg) {
+			return
+		}
+	}
+}
+
+func (s *stats) incPush(kind int) {
+	if kind < 0 || kind >= mkNum {
+		kind = MkUnknown
+	}
+	s.push[kind].Add(1)
+}
+
+// countReader counts the bytes read from r.
+type countReader struct {
+	r io.Reader
+	n *atomic.Uint64
+}
+
+func (r countReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n.Add(uint64(n))
+	return n, err
+}
+
+// countWriter counts the bytes written to w.
+type countWriter struct {
+	w io.Writer
+	n *atomic.Uint64
+}
+
+func (w countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n.Add(uint64(n))
+	return n, err
+}
+
+// Stats returns a snapshot of the client statistics. The counters are not reset on Reconnect.
+func (c *Client) Stats() Stats {
+	s := Stats{
+		Cmds:       c.stats.cmds.Load(),
+		Replies:    c.stats.replies.Load(),
+		Timeouts:   c.stats.timeouts.Load(),
+		Stale:      c.stats.staleReplies.Load(),
+		Reconnects: c.stats.reconnects.Load(),
+		BytesIn:    c.stats.bytesIn.Load(),
+		BytesOut:   c.stats.bytesOut.Load(),
+		Push:       map[int]uint64{},
+		PushDrops:  c.stats.droppedPush.Load(),
+		Latency:    time.Duration(c.stats.latency.Load()),
+	}
+	for kind := range c.stats.push {
+		if n := c.stats.push[kind].Load(); n != 0 {
+			s.Push[kind] = n
+		}
+	}
+	return s
+}
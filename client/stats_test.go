@@ -0,0 +1,70 @@
+package client_test
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestStats(t *testing.T) {
+	conn := client.NewMockConn()
+	conn.HandleFunc(func(cmd string, args []string) []string {
+		if cmd == "ioadc" {
+			return nil // no reply
+		}
+		return mockStation()(cmd, args)
+	})
+	pushCh := make(chan client.Msg, 3)
+	c := client.New(conn, func(msg client.Msg, err error) { pushCh <- msg }, client.WithTimeout(20*time.Millisecond))
+	defer c.Close()
+
+	if _, err := c.Temp(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Help(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Board(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.LocoDir(3); !errors.Is(err, client.ErrInvCmd) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrInvCmd)
+	}
+	if _, err := c.SetLocoSpeed128(3, 40); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.MTE(); err != nil {
+		t.Fatal(err)
+	}
+	// no reply -> timeout
+	if _, err := c.IOADC(26); err == nil {
+		t.Fatal("expected timeout error")
+	}
+
+	conn.Push("ioie: 5 t")
+	conn.Push("ioie: 6 f")
+	conn.Push("wifi: connected")
+	for i := 0; i < 3; i++ {
+		<-pushCh
+	}
+
+	conn.Disconnect(io.ErrUnexpectedEOF)
+	if err := c.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := c.Stats()
+	if stats.Cmds != 7 || stats.Replies != 6 || stats.Timeouts != 1 || stats.Reconnects != 1 {
+		t.Fatalf("invalid stats %+v", stats)
+	}
+	if stats.BytesIn == 0 || stats.BytesOut == 0 {
+		t.Fatalf("invalid byte counters %+v", stats)
+	}
+	if expected := map[int]uint64{client.MkIOIE: 2, client.MkWifi: 1}; !maps.Equal(stats.Push, expected) {
+		t.Fatalf("invalid push counters %v - expected %v", stats.Push, expected)
+	}
+}
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// NewSync returns a new client instance in synchronous mode.
+//
+// In synchronous mode the client does not start any background reader goroutine: the replies are
+// read inline on the goroutine calling the client method. This is intended for simple scripts and
+// command line tools executing one command after the other.
+//
+// Please note the limitations of the synchronous mode:
+//   - Push messages are not supported: push messages received while reading a reply are discarded
+//     (see Stats.PushDrops) and WatchGPIO does not deliver any state. The handler (see WithHandler)
+//     is called for client generated messages (like ReconnectMsg) only.
+//   - Coalescing (see WithCoalescing) is not supported and the option is ignored.
+//   - The replies of async commands (like SetLocoSpeed128Async) are consumed by the next synchronous
+//     command, so that command station errors are reported to the async error handler only then.
+//   - The read timeout (see WithTimeout) is applied via read deadlines for connections supporting
+//     them (TCPClient and TLSClient). For other connections (like Serial or MockConn) each read is
+//     run on a helper goroutine, which is left running on a timeout and continued by the next read.
+func NewSync(conn Conn, opts ...Option) *Client {
+	c := newClient(conn, opts)
+	c.syncMode = true
+	c.coalesce = nil
+	c.startup()
+	return c
+}
+
+// readDeadliner is implemented by connections supporting read deadlines (like network connections).
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// readResult is the result of a read of a deadlineReader.
+type readResult struct {
+	n   int
+	err error
+}
+
+// deadlineReader applies read deadlines to connections not supporting them. A read is run on a
+// helper goroutine: if the deadline is exceeded the read is left pending and its result is
+// returned by the next read.
+type deadlineReader struct {
+	r        io.Reader
+	deadline time.Time
+	buf      []byte          // read buffer of the helper goroutine
+	data     []byte          // data read but not yet returned
+	pending  chan readResult // result of the read in progress (nil: no read in progress)
+}
+
+// SetReadDeadline sets the deadline of subsequent reads. A zero value means no deadline.
+func (r *deadlineReader) SetReadDeadline(t time.Time) error {
+	r.deadline = t
+	return nil
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	if len(r.data) > 0 {
+		n := copy(p, r.data)
+		r.data = r.data[n:]
+		return n, nil
+	}
+	if r.pending == nil {
+		if cap(r.buf) < len(p) {
+			r.buf = make([]byte, len(p))
+		}
+		buf, ch := r.buf[:len(p)], make(chan readResult, 1)
+		go func() {
+			n, err := r.r.Read(buf)
+			ch <- readResult{n: n, err: err}
+		}()
+		r.pending = ch
+	}
+
+	var timeout <-chan time.Time
+	if !r.deadline.IsZero() {
+		timer := time.NewTimer(time.Until(r.deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case res := <-r.pending:
+		r.pending = nil
+		n := copy(p, r.buf[:res.n])
+		r.data = r.buf[n:res.n]
+		return n, res.err
+	case <-timeout:
+		return 0, os.ErrDeadlineExceeded
+	}
+}
+
+// syncReader reads the reply lines of a connection in synchronous mode.
+type syncReader struct {
+	d    readDeadliner // read deadline of the connection
+	r    *bufio.Reader
+	line []byte // line read so far
+	eol  bool   // line is complete
+	h    *lineHandler
+}
+
+func newSyncReader(c *clientState, replies *replyQueue) *syncReader {
+	var r io.Reader = c.conn
+	d, ok := c.conn.(readDeadliner)
+	if !ok {
+		dr := &deadlineReader{r: c.conn}
+		r, d = dr, dr
+	}
+	return &syncReader{
+		d: d,
+		r: bufio.NewReader(countReader{r: r, n: &c.stats.bytesIn}),
+		h: &lineHandler{c: c, replies: replies, push: func(msg string) {
+			c.stats.droppedPush.Add(1)
+			if c.debugEnabled() {
+				c.logger.Debug("discard push message in synchronous mode", "msg", msg)
+			}
+		}},
+	}
+}
+
+// readLine returns the next line without line terminator. A partially read line is kept on error
+// (like a read timeout), so that the line is completed by the next read.
+// The line is valid until the next call of readLine.
+func (s *syncReader) readLine() ([]byte, error) {
+	if s.eol {
+		s.line, s.eol = s.line[:0], false
+	}
+	for {
+		b, err := s.r.ReadSlice('\n')
+		s.line = append(s.line, b...)
+		if len(s.line) > s.h.c.maxLineSize {
+			return nil, bufio.ErrTooLong
+		}
+		switch err {
+		case nil:
+			s.eol = true
+			return bytes.TrimSuffix(s.line[:len(s.line)-1], []byte{'\r'}), nil
+		case bufio.ErrBufferFull: // continue reading the line
+		default:
+			return nil, err
+		}
+	}
+}
+
+// readSync reads the reply lines until the reply of command p is received.
+func (c *Client) readSync(p *pendingReply, timeout time.Duration) (any, error) {
+	s := c.syncReader
+	if timeout > 0 {
+		s.d.SetReadDeadline(time.Now().Add(timeout)) //nolint: errcheck
+		defer s.d.SetReadDeadline(time.Time{})       //nolint: errcheck
+	}
+
+	for {
+		select {
+		case reply, ok := <-p.ch:
+			return c.received(reply, ok)
+		default:
+		}
+
+		line, err := s.readLine()
+		switch {
+		case errors.Is(err, os.ErrDeadlineExceeded):
+			return c.timedOut(p, timeout)
+		case err != nil:
+			// connection finished: the reply channel of p is closed
+			c.readerDone(c.replies, err)
+		default:
+			s.h.handle(line)
+		}
+	}
+}
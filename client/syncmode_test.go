@@ -0,0 +1,123 @@
+package client_test
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestSyncMode(t *testing.T) {
+
+	t.Run("Command", func(t *testing.T) {
+		station := mockStation()
+		conn := client.NewMockConn()
+		conn.HandleFunc(func(cmd string, args []string) []string {
+			lines := station(cmd, args)
+			if cmd == "t" { // push message received while reading the reply
+				lines = append([]string{"!short: t"}, lines...)
+			}
+			return lines
+		})
+		c := client.NewSync(conn)
+		defer c.Close()
+
+		temp, err := c.Temp()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if temp != 27.5 {
+			t.Fatalf("invalid temperature %f - expected %f", temp, 27.5)
+		}
+
+		help, err := c.Help()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(help) != 2 {
+			t.Fatalf("invalid number of help lines %d - expected %d", len(help), 2)
+		}
+
+		stats := c.Stats()
+		if stats.Replies != 2 {
+			t.Fatalf("invalid number of replies %d - expected %d", stats.Replies, 2)
+		}
+		if stats.PushDrops != 1 {
+			t.Fatalf("invalid number of dropped push messages %d - expected %d", stats.PushDrops, 1)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		clientConn, stationConn := net.Pipe()
+		defer stationConn.Close()
+
+		// station does not reply to the first command
+		go func() {
+			r := bufio.NewReader(stationConn)
+			for i := 0; ; i++ {
+				if _, err := r.ReadString('\r'); err != nil {
+					return
+				}
+				if i > 0 {
+					stationConn.Write([]byte("=27.5\r\n")) //nolint: errcheck
+				}
+			}
+		}()
+
+		c := client.NewSync(&pipeConn{Conn: clientConn}, client.WithTimeout(20*time.Millisecond))
+		defer c.Close()
+
+		if _, err := c.Temp(); err == nil {
+			t.Fatal("missing read timeout error")
+		}
+		if stats := c.Stats(); stats.Timeouts != 1 {
+			t.Fatalf("invalid number of timeouts %d - expected %d", stats.Timeouts, 1)
+		}
+
+		// wait for the end of the grace period of the timed out command, so that the reply
+		// of the next command is not discarded as reply of the timed out command
+		time.Sleep(50 * time.Millisecond)
+
+		temp, err := c.Temp()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if temp != 27.5 {
+			t.Fatalf("invalid temperature %f - expected %f", temp, 27.5)
+		}
+	})
+
+	t.Run("TimeoutNoDeadline", func(t *testing.T) {
+		// MockConn does not support read deadlines
+		var n int
+		conn := client.NewMockConn()
+		conn.HandleFunc(func(cmd string, args []string) []string {
+			n++
+			if n == 1 { // station does not reply to the first command
+				return nil
+			}
+			return []string{"=27.5"}
+		})
+		c := client.NewSync(conn, client.WithTimeout(20*time.Millisecond))
+		defer c.Close()
+
+		if _, err := c.Temp(); err == nil {
+			t.Fatal("missing read timeout error")
+		}
+		if stats := c.Stats(); stats.Timeouts != 1 {
+			t.Fatalf("invalid number of timeouts %d - expected %d", stats.Timeouts, 1)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		temp, err := c.Temp()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if temp != 27.5 {
+			t.Fatalf("invalid temperature %f - expected %f", temp, 27.5)
+		}
+	})
+}
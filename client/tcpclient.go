@@ -1,35 +1,69 @@
 package client
 
 import (
+	"context"
+	"fmt"
 	"net"
+	"time"
 )
 
 // DefaultTCPPort is the default TCP Port used by Pico W.
 const DefaultTCPPort = "4242"
 
+// DefaultDialTimeout is the default timeout for establishing a TCP/IP connection.
+const DefaultDialTimeout = 5 * time.Second
+
 // TCPClient provides a TCP/IP connection to to the Raspberry Pi Pico W.
 type TCPClient struct {
-	host, port string
-	conn       net.Conn
+	host, port  string
+	dialTimeout time.Duration
+	conn        net.Conn
 }
 
 // NewTCPClient returns a new TCP/IP connection instance.
-func NewTCPClient(host, port string) (*TCPClient, error) {
+// An optional dial timeout limits the time waiting for the connection to be established
+// (zero or omitted: DefaultDialTimeout).
+func NewTCPClient(host, port string, dialTimeout ...time.Duration) (*TCPClient, error) {
+	return NewTCPClientContext(context.Background(), host, port, dialTimeout...)
+}
+
+// NewTCPClientContext is like NewTCPClient, but the connection establishment is aborted
+// if the context is done before, returning the context error.
+func NewTCPClientContext(ctx context.Context, host, port string, dialTimeout ...time.Duration) (*TCPClient, error) {
 	if port == "" {
 		port = DefaultTCPPort
 	}
 
-	c := &TCPClient{host: host, port: port}
-	if err := c.Connect(); err != nil {
+	c := &TCPClient{host: host, port: port, dialTimeout: DefaultDialTimeout}
+	if len(dialTimeout) > 0 && dialTimeout[0] > 0 {
+		c.dialTimeout = dialTimeout[0]
+	}
+	if err := c.ConnectContext(ctx); err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
+// DialTimeout returns the dial timeout of the connection.
+func (c *TCPClient) DialTimeout() time.Duration { return c.dialTimeout }
+
 // Connect connects to the tcp address.
-func (c *TCPClient) Connect() (err error) {
-	c.conn, err = net.Dial("tcp", net.JoinHostPort(c.host, c.port))
-	return err
+func (c *TCPClient) Connect() error { return c.ConnectContext(context.Background()) }
+
+// ConnectContext connects to the tcp address. If the context is done before the connection
+// is established the context error is returned.
+func (c *TCPClient) ConnectContext(ctx context.Context) error {
+	addr := net.JoinHostPort(c.host, c.port)
+	dialer := &net.Dialer{Timeout: c.dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("connect to %s - %w", addr, err)
+	}
+	c.conn = conn
+	return nil
 }
 
 // Read implements the Conn interface.
@@ -42,6 +76,16 @@ func (c *TCPClient) Write(p []byte) (n int, err error) {
 	return c.conn.Write(p)
 }
 
+// SetWriteDeadline sets the write deadline of the connection.
+func (c *TCPClient) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline of the connection.
+func (c *TCPClient) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
 // Close implements the Conn interface.
 func (c *TCPClient) Close() error {
 	return c.conn.Close()
@@ -0,0 +1,65 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestTCPDialTimeout(t *testing.T) {
+	// unroutable address (TEST-NET-1, RFC 5737)
+	const host, port = "192.0.2.1", "4242"
+
+	const dialTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := client.NewTCPClient(host, port, dialTimeout)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("expected connection error")
+	}
+	if addr := net.JoinHostPort(host, port); !strings.Contains(err.Error(), addr) {
+		t.Fatalf("error %v does not identify address %s", err, addr)
+	}
+	if elapsed > 10*dialTimeout {
+		t.Fatalf("dial took %s - expected timeout after %s", elapsed, dialTimeout)
+	}
+}
+
+func TestTCPConnectContext(t *testing.T) {
+	// unroutable address (TEST-NET-1, RFC 5737)
+	const host, port = "192.0.2.1", "4242"
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := client.NewTCPClientContext(ctx, host, port, time.Minute)
+		elapsed := time.Since(start)
+
+		if !errors.Is(err, context.Canceled) {
+			if ctx.Err() == nil { // dial failed before the cancellation (like no network route)
+				t.Skipf("dial to unroutable host failed immediately - %v", err)
+			}
+			t.Fatalf("invalid error %v - expected %v", err, context.Canceled)
+		}
+		if elapsed > time.Second {
+			t.Fatalf("dial took %s after cancellation", elapsed)
+		}
+	})
+
+	t.Run("Done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := client.NewTCPClientContext(ctx, host, port); !errors.Is(err, context.Canceled) {
+			t.Fatalf("invalid error %v - expected %v", err, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestTemperature(t *testing.T) {
+	tests := []struct {
+		temp       client.Temperature
+		fahrenheit float64
+		s          string
+	}{
+		{0, 32, "0°C"},
+		{100, 212, "100°C"},
+		{-40, -40, "-40°C"},
+		{27.5, 81.5, "27.5°C"},
+	}
+
+	for _, test := range tests {
+		if c := test.temp.Celsius(); c != float64(test.temp) {
+			t.Errorf("invalid celsius %g - expected %g", c, float64(test.temp))
+		}
+		if f := test.temp.Fahrenheit(); f != test.fahrenheit {
+			t.Errorf("%s: invalid fahrenheit %g - expected %g", test.temp, f, test.fahrenheit)
+		}
+		if s := test.temp.String(); s != test.s {
+			t.Errorf("invalid string %s - expected %s", s, test.s)
+		}
+	}
+
+	c := newTestClient(t, func(cmd string, args []string) []string { return []string{"=27.5"} }, nil)
+	temp, err := c.Temperature()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp.Fahrenheit() != 81.5 {
+		t.Fatalf("invalid temperature %s (%g°F) - expected %g°F", temp, temp.Fahrenheit(), 81.5)
+	}
+}
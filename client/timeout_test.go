@@ -0,0 +1,82 @@
+package client_test
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestMaxTimeouts(t *testing.T) {
+	// silent station
+	c := newTestClient(t, func(cmd string, args []string) []string { return nil }, nil,
+		client.WithTimeout(10*time.Millisecond),
+		client.WithMaxTimeouts(2),
+	)
+
+	if _, err := c.Temp(); err == nil || errors.Is(err, client.ErrConnDead) {
+		t.Fatalf("invalid error %v - expected timeout", err)
+	}
+	if _, err := c.Temp(); !errors.Is(err, client.ErrConnDead) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrConnDead)
+	}
+	if _, err := c.Temp(); err == nil {
+		t.Fatal("expected error on dead connection")
+	}
+}
+
+// pipeConn is an in-memory network connection.
+type pipeConn struct {
+	net.Conn
+}
+
+// Connect implements the client.Conn interface.
+func (c *pipeConn) Connect() error { return nil }
+
+func TestWriteTimeout(t *testing.T) {
+	// stalled station: never reads
+	clientConn, stationConn := net.Pipe()
+	defer stationConn.Close()
+
+	c := client.New(&pipeConn{Conn: clientConn}, nil, client.WithWriteTimeout(20*time.Millisecond))
+	defer c.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Temp()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("invalid error %v - expected %v", err, os.ErrDeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("write blocked")
+	}
+
+	// station is reading again: the timed out write does not affect subsequent commands
+	go func() {
+		r := bufio.NewReader(stationConn)
+		for {
+			if _, err := r.ReadString('\r'); err != nil {
+				return
+			}
+			if _, err := stationConn.Write([]byte("=25\r\n")); err != nil {
+				return
+			}
+		}
+	}()
+	temp, err := c.Temp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp != 25 {
+		t.Fatalf("invalid temperature %f - expected %d", temp, 25)
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// TLSClient provides a TLS secured TCP/IP connection to the Raspberry Pi Pico W
+// (like via a TLS terminating proxy in front of the command station).
+type TLSClient struct {
+	host, port string
+	cfg        *tls.Config
+	conn       *tls.Conn
+}
+
+// NewTLSClient returns a new TLS connection instance.
+// A nil configuration uses the default configuration verifying the server certificate
+// against the system root certificates.
+func NewTLSClient(host, port string, cfg *tls.Config) (*TLSClient, error) {
+	if port == "" {
+		port = DefaultTCPPort
+	}
+
+	c := &TLSClient{host: host, port: port, cfg: cfg}
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Connect connects to the tcp address and establishes a new TLS session.
+func (c *TLSClient) Connect() (err error) {
+	c.conn, err = tls.Dial("tcp", net.JoinHostPort(c.host, c.port), c.cfg)
+	return err
+}
+
+// Read implements the Conn interface.
+func (c *TLSClient) Read(p []byte) (n int, err error) {
+	return c.conn.Read(p)
+}
+
+// Write implements the Conn interface.
+func (c *TLSClient) Write(p []byte) (n int, err error) {
+	return c.conn.Write(p)
+}
+
+// SetWriteDeadline sets the write deadline of the connection.
+func (c *TLSClient) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline of the connection.
+func (c *TLSClient) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// Close implements the Conn interface.
+func (c *TLSClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,97 @@
+package client_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// selfSignedCert returns a self-signed certificate for localhost.
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "pico-cs test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, cert
+}
+
+func TestTLSClient(t *testing.T) {
+	tlsCert, cert := selfSignedCert(t)
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{tlsCert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go runStation(conn, mockStation())
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	conn, err := client.NewTLSClient(host, port, &tls.Config{RootCAs: roots})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.New(conn, nil)
+	defer c.Close()
+
+	board, err := c.Board()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.Type != client.BtPicoW {
+		t.Fatalf("invalid board type %s - expected %s", board.Type, client.BtPicoW)
+	}
+
+	// new TLS session on reconnect
+	if err := c.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Board(); err != nil {
+		t.Fatal(err)
+	}
+
+	// untrusted server certificate
+	if _, err := client.NewTLSClient(host, port, nil); err == nil {
+		t.Fatal("expected certificate verification error")
+	}
+}
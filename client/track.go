@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrShortCircuit is returned by EnableTrack if the track could not be enabled due to a latched short circuit
+// (see ShortLatched and ClearShort).
+var ErrShortCircuit = errors.New("short circuit")
+
+// trackPollInterval is the interval polling the main track state (see EnableTrack).
+const trackPollInterval = 50 * time.Millisecond
+
+// EnableTrack enables the main track DCC signal generation (see SetMTE) and polls the main track state
+// (see MTE) until the command station reports the track as enabled, so that subsequent loco commands
+// do not race with the startup of the signal generation. EnableTrack is idempotent: an enabled track
+// is confirmed immediately.
+// If the command station latched a short circuit, ErrShortCircuit is returned. If the context is done before
+// the track is reported as enabled, the context error is returned.
+func (c *Client) EnableTrack(ctx context.Context) error { return c.setTrack(ctx, true) }
+
+// DisableTrack disables the main track DCC signal generation and polls the main track state until the command
+// station reports the track as disabled (see EnableTrack).
+func (c *Client) DisableTrack(ctx context.Context) error { return c.setTrack(ctx, false) }
+
+// shortCircuit returns ErrShortCircuit if the command station latched a short circuit.
+// Command stations not supporting short circuit latching are considered as not latched.
+func (c *Client) shortCircuit() error {
+	latched, err := c.ShortLatched()
+	switch {
+	case errors.Is(err, ErrNotImpl) || errors.Is(err, ErrInvCmd):
+		return nil
+	case err != nil:
+		return err
+	case latched:
+		return ErrShortCircuit
+	}
+	return nil
+}
+
+func (c *Client) setTrack(ctx context.Context, enabled bool) error {
+	state := map[bool]string{false: "disable", true: "enable"}[enabled]
+
+	on, err := c.SetMTE(enabled)
+	if err != nil {
+		if enabled && errors.Is(c.shortCircuit(), ErrShortCircuit) {
+			return fmt.Errorf("%s track - %w", state, ErrShortCircuit)
+		}
+		return fmt.Errorf("%s track - %w", state, err)
+	}
+
+	ticker := time.NewTicker(trackPollInterval)
+	defer ticker.Stop()
+
+	for on != enabled {
+		if enabled {
+			if err := c.shortCircuit(); err != nil {
+				return fmt.Errorf("%s track - %w", state, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s track not confirmed - %w", state, ctx.Err())
+		case <-ticker.C:
+		}
+		if on, err = c.MTE(); err != nil {
+			return fmt.Errorf("%s track - %w", state, err)
+		}
+	}
+	return nil
+}
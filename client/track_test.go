@@ -0,0 +1,87 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// delayedTrackStation returns a station handler reporting the requested main track state after delay polls.
+// If shortLatched is set, the track can not be enabled.
+func delayedTrackStation(delay int, shortLatched bool) client.MockHandler {
+	mte, target, polls := false, false, 0
+	return func(cmd string, args []string) []string {
+		switch cmd {
+		case "h":
+			return []string{"-mte [t|f|~]: main track enabled", "-short [f]: short circuit latch", "."}
+		case "mte":
+			if len(args) == 1 {
+				target, polls = args[0] == "t" && !shortLatched, 0
+			} else if polls++; polls >= delay {
+				mte = target
+			}
+			return []string{"=" + map[bool]string{false: "f", true: "t"}[mte]}
+		case "short":
+			return []string{"=" + map[bool]string{false: "f", true: "t"}[shortLatched]}
+		default:
+			return []string{"?invcmd"}
+		}
+	}
+}
+
+func TestTrack(t *testing.T) {
+	tests := []struct {
+		name         string
+		enable       bool
+		delay        int
+		shortLatched bool
+		timeout      time.Duration
+		err          error
+	}{
+		{"Enable", true, 3, false, 5 * time.Second, nil},
+		{"Disable", false, 3, false, 5 * time.Second, nil},
+		{"Short", true, 3, true, 5 * time.Second, client.ErrShortCircuit},
+		{"Timeout", true, 100, false, 100 * time.Millisecond, context.DeadlineExceeded},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := client.NewMockConn()
+			conn.HandleFunc(delayedTrackStation(test.delay, test.shortLatched))
+			c := client.New(conn, nil)
+			defer c.Close()
+
+			if !test.enable { // start with enabled track
+				if err := c.EnableTrack(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+			defer cancel()
+
+			setTrack := map[bool]func(context.Context) error{false: c.DisableTrack, true: c.EnableTrack}[test.enable]
+			if err := setTrack(ctx); !errors.Is(err, test.err) {
+				t.Fatalf("invalid error %v - expected %v", err, test.err)
+			}
+			if test.err != nil {
+				return
+			}
+
+			mte, err := c.MTE()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mte != test.enable {
+				t.Fatalf("invalid main track state %t - expected %t", mte, test.enable)
+			}
+			// idempotent: confirmed immediately
+			if err := setTrack(ctx); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
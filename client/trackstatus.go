@@ -0,0 +1,50 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// TrackStatus represents the main track status of a command station (see Client.TrackStatus).
+type TrackStatus struct {
+	Enabled bool        // main track DCC signal generation enabled (see MTE)
+	Current float64     // 'raw' value of the current sense ADC input (see IOADC)
+	Temp    Temperature // command station temperature
+}
+
+func (s *TrackStatus) String() string {
+	return fmt.Sprintf("enabled %t current %g temperature %s", s.Enabled, s.Current, s.Temp)
+}
+
+// TrackStatus returns the main track status like for the refresh of a status panel: the enabled state of
+// the main track, the current measured by the ADC input currentInput and the command station temperature.
+// The reads are pipelined (see Batch), so that the status is read within a single round trip.
+// If a read fails, the status containing the values of the successful reads is returned together
+// with the combined error of the failed reads.
+func (c *Client) TrackStatus(currentInput uint) (*TrackStatus, error) {
+	results, err := c.Batch().add(cmdMTE).add(cmdIOADC, currentInput).add(cmdTemp).Run()
+
+	status := &TrackStatus{}
+	errs := []error{err}
+	for _, result := range results {
+		if result.Err != nil { // part of the batch error
+			continue
+		}
+		var perr error
+		switch result.Cmd {
+		case cmdMTE:
+			status.Enabled, perr = parseBool(result.Reply)
+		case cmdIOADC:
+			status.Current, perr = strconv.ParseFloat(result.Reply, 64)
+		case cmdTemp:
+			var temp float64
+			temp, perr = strconv.ParseFloat(result.Reply, 64)
+			status.Temp = Temperature(temp)
+		}
+		if perr != nil {
+			errs = append(errs, fmt.Errorf("track status %s: %w", result.Cmd, perr))
+		}
+	}
+	return status, errors.Join(errs...)
+}
@@ -0,0 +1,43 @@
+package client_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestTrackStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  uint
+		status client.TrackStatus
+		err    error
+	}{
+		{"Complete", 2, client.TrackStatus{Enabled: true, Current: 512, Temp: 27.5}, nil},
+		{"Partial", 7, client.TrackStatus{Enabled: true, Temp: 27.5}, client.ErrInvPrm},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := client.NewMockConn()
+			conn.Reply("mte", "=t")
+			conn.Reply("ioadc 2", "=512")
+			conn.Reply("ioadc 7", "?invprm")
+			conn.Reply("t", "=27.5")
+			c := client.New(conn, nil)
+			defer c.Close()
+
+			status, err := c.TrackStatus(test.input)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("invalid error %v - expected %v", err, test.err)
+			}
+			if *status != test.status {
+				t.Fatalf("invalid status %s - expected %s", status, &test.status)
+			}
+			if written := conn.Written(); len(written) != 3 {
+				t.Fatalf("invalid number of commands %d - expected %d", len(written), 3)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package client_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestTryCall(t *testing.T) {
+	conn := client.NewMockConn()
+	conn.HandleFunc(func(cmd string, args []string) []string { return nil }) // reply is injected
+	c := client.New(conn, nil)
+	defer c.Close()
+
+	var temp float64
+	poll := func() (err error) {
+		temp, err = c.Temp()
+		return err
+	}
+
+	tempErrCh := startTemp(t, c, conn) // blocked command
+
+	called := false
+	if err := c.TryCall(func() error { called = true; return nil }); !errors.Is(err, client.ErrBusy) {
+		t.Fatalf("invalid error %v - expected %v", err, client.ErrBusy)
+	}
+	if called {
+		t.Fatal("function called while busy")
+	}
+
+	conn.Inject("=27.5")
+	if err := <-tempErrCh; err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Reply("t", "=28")
+	if err := c.TryCall(poll); err != nil {
+		t.Fatal(err)
+	}
+	if temp != 28 {
+		t.Fatalf("invalid temperature %g - expected %g", temp, 28.0)
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+const maxDatagramSize = 65535
+
+// UDPClient provides a UDP connection to the Raspberry Pi Pico W.
+//
+// Compared to TCPClient the commands are sent as datagrams without connection handshake,
+// acknowledgements and Nagle delays, which reduces the latency of frequent updates like
+// throttle speed changes. On the other hand UDP is unreliable: datagrams might get lost,
+// duplicated or reordered. A lost command or reply results in a read timeout (see WithTimeout)
+// and a reordered reply might be assigned to the wrong command, so that UDP should only be used
+// on reliable networks and for commands which are safe to be repeated.
+// The command station firmware needs to support UDP.
+type UDPClient struct {
+	host, port string
+	conn       *net.UDPConn
+	buf        []byte // datagram read buffer
+	data       []byte // unread datagram data
+}
+
+// NewUDPClient returns a new UDP connection instance.
+func NewUDPClient(host, port string) (*UDPClient, error) {
+	if port == "" {
+		port = DefaultTCPPort
+	}
+
+	c := &UDPClient{host: host, port: port, buf: make([]byte, maxDatagramSize)}
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Connect sets the udp address the datagrams are sent to.
+func (c *UDPClient) Connect() error {
+	addr := net.JoinHostPort(c.host, c.port)
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return fmt.Errorf("resolve %s - %w", addr, err)
+	}
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return fmt.Errorf("connect to %s - %w", addr, err)
+	}
+	c.conn = conn
+	c.data = nil
+	return nil
+}
+
+// Read implements the Conn interface.
+// The data of a datagram not fitting into p is returned by the following reads.
+func (c *UDPClient) Read(p []byte) (n int, err error) {
+	if len(c.data) == 0 {
+		if n, err = c.conn.Read(c.buf); err != nil {
+			return 0, err
+		}
+		c.data = c.buf[:n]
+	}
+	n = copy(p, c.data)
+	c.data = c.data[n:]
+	return n, nil
+}
+
+// Write implements the Conn interface. Each write is sent as a single datagram.
+func (c *UDPClient) Write(p []byte) (n int, err error) {
+	return c.conn.Write(p)
+}
+
+// SetWriteDeadline sets the write deadline of the connection.
+func (c *UDPClient) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// Close implements the Conn interface.
+func (c *UDPClient) Close() error {
+	return c.conn.Close()
+}
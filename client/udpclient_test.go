@@ -0,0 +1,73 @@
+package client_test
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// runUDPStation simulates a command station replying to the command datagrams.
+func runUDPStation(conn net.PacketConn, handler stationHandler) {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var reply bytes.Buffer
+		for _, cmdLine := range strings.Split(string(buf[:n]), "\r") {
+			if cmdLine == "" {
+				continue
+			}
+			fields := strings.Split(strings.TrimPrefix(cmdLine, "+"), " ")
+			for _, line := range handler(fields[0], fields[1:]) {
+				reply.WriteString(line + "\r\n")
+			}
+		}
+		if _, err := conn.WriteTo(reply.Bytes(), addr); err != nil {
+			return
+		}
+	}
+}
+
+func TestUDPClient(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	go runUDPStation(pc, mockStation())
+
+	host, port, err := net.SplitHostPort(pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := client.NewUDPClient(host, port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.New(conn, nil)
+	defer c.Close()
+
+	temp, err := c.Temp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp != 27.5 {
+		t.Fatalf("invalid temperature %f - expected %f", temp, 27.5)
+	}
+
+	if _, err := c.SetLocoSpeed128(3, 40); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := c.RefreshBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf.Entries) != 1 {
+		t.Fatalf("invalid refresh buffer %v", buf)
+	}
+}
@@ -0,0 +1,54 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestValidateLocoArgs(t *testing.T) {
+	c := newTestClient(t, func(cmd string, args []string) []string {
+		switch cmd {
+		case "ls":
+			return []string{"=" + args[1]}
+		case "lf":
+			if len(args) == 3 {
+				return []string{"=t"}
+			}
+			return []string{"=f"}
+		}
+		return []string{"?invcmd"}
+	}, nil)
+
+	tests := []struct {
+		name string
+		fn   func() error
+		ok   bool
+	}{
+		{"SetLocoSpeed128Min", func() error { _, err := c.SetLocoSpeed128(3, 0); return err }, true},
+		{"SetLocoSpeed128Max", func() error { _, err := c.SetLocoSpeed128(3, client.MaxSpeed128); return err }, true},
+		{"SetLocoSpeed128Invalid", func() error { _, err := c.SetLocoSpeed128(3, client.MaxSpeed128+1); return err }, false},
+		{"LocoFctMax", func() error { _, err := c.LocoFct(3, client.MaxLocoFct); return err }, true},
+		{"LocoFctInvalid", func() error { _, err := c.LocoFct(3, client.MaxLocoFct+1); return err }, false},
+		{"SetLocoFctMin", func() error { _, err := c.SetLocoFct(3, 0, true); return err }, true},
+		{"SetLocoFctMax", func() error { _, err := c.SetLocoFct(3, client.MaxLocoFct, true); return err }, true},
+		{"SetLocoFctInvalid", func() error { _, err := c.SetLocoFct(3, client.MaxLocoFct+1, true); return err }, false},
+		{"ToggleLocoFctInvalid", func() error { _, err := c.ToggleLocoFct(3, client.MaxLocoFct+1); return err }, false},
+		{"BatchSpeedMax", func() error { _, err := c.Batch().SetLocoSpeed128(3, client.MaxSpeed128).Run(); return err }, true},
+		{"BatchSpeedInvalid", func() error { _, err := c.Batch().SetLocoSpeed128(3, client.MaxSpeed128+1).Run(); return err }, false},
+		{"BatchFctMax", func() error { _, err := c.Batch().SetLocoFct(3, client.MaxLocoFct, true).Run(); return err }, true},
+		{"BatchFctInvalid", func() error { _, err := c.Batch().SetLocoFct(3, client.MaxLocoFct+1, true).Run(); return err }, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.fn()
+			if test.ok && err != nil {
+				t.Fatal(err)
+			}
+			if !test.ok && err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
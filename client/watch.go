@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pico-cs/go-client/client/rbuf"
+)
+
+// evictFilter keeps track of the locos removed from the refresh buffer by the client
+// so that they are not reported as evicted.
+// A removal is marked before the command is sent, so that a poll between the execution of the
+// command and its reply does not report the removal.
+type evictFilter struct {
+	mu         sync.Mutex
+	all        bool
+	deleted    map[uint]bool
+	pendingAll int          // resets in progress
+	pending    map[uint]int // deletions in progress
+}
+
+// beginReset marks a reset in progress.
+func (f *evictFilter) beginReset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pendingAll++
+}
+
+// endReset ends a reset in progress, ok reports if the reset succeeded.
+func (f *evictFilter) endReset(ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pendingAll--
+	if ok {
+		f.all = true
+	}
+}
+
+// beginDelete marks the deletion of addr in progress.
+func (f *evictFilter) beginDelete(addr uint) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.pending == nil {
+		f.pending = map[uint]int{}
+	}
+	f.pending[addr]++
+}
+
+// endDelete ends the deletion of addr in progress, ok reports if the deletion succeeded.
+func (f *evictFilter) endDelete(addr uint, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.pending[addr]--; f.pending[addr] == 0 {
+		delete(f.pending, addr)
+	}
+	if !ok {
+		return
+	}
+	if f.deleted == nil {
+		f.deleted = map[uint]bool{}
+	}
+	f.deleted[addr] = true
+}
+
+// take returns and clears the removals since the last call including the removals in progress.
+func (f *evictFilter) take() (bool, map[uint]bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	all, deleted := f.all || f.pendingAll > 0, f.deleted
+	for addr := range f.pending {
+		if deleted == nil {
+			deleted = map[uint]bool{}
+		}
+		deleted[addr] = true
+	}
+	f.all, f.deleted = false, nil
+	return all, deleted
+}
+
+// WatchRefreshBuffer polls the refresh buffer every interval and detects locos dropped by the command station
+// (like on a full refresh buffer). Each dropped loco is reported as EvictMsg to the push message handler.
+// Locos removed by RefreshBufferDelete or RefreshBufferReset of this client are not reported.
+// Please note that the handler might be called concurrently to the push messages of the command station.
+// WatchRefreshBuffer blocks until the context is done or an error occurs.
+func (c *Client) WatchRefreshBuffer(ctx context.Context, interval time.Duration) error {
+	c.evict.take() // ignore removals before first poll
+
+	prev, err := c.RefreshBuffer()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		buf, err := c.RefreshBuffer()
+		if err != nil {
+			return err
+		}
+		all, deleted := c.evict.take()
+		if !all {
+			diff := rbuf.Diff(prev, buf)
+			for _, addr := range diff.Removed {
+				if !deleted[addr] && c.handler != nil {
+					c.handler(&EvictMsg{Addr: addr}, nil)
+				}
+			}
+		}
+		prev = buf
+	}
+}
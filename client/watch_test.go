@@ -0,0 +1,66 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+func TestWatchRefreshBufferEvict(t *testing.T) {
+	// refresh buffer content per poll
+	polls := [][]uint{
+		{3, 5, 7},
+		{3, 5, 7},
+		{3, 7}, // 5 evicted
+		{7},    // 3 deleted by client
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	poll := 0
+	var evicted []uint
+
+	var c *client.Client
+	c = newTestClient(t, func(cmd string, args []string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		switch cmd {
+		case "r":
+			if poll == len(polls)-1 {
+				cancel()
+			}
+			addrs := polls[min(poll, len(polls)-1)]
+			poll++
+			return refreshBufferReply(addrs...)
+		case "rd":
+			return []string{"=" + args[0]}
+		}
+		return []string{"?invcmd"}
+	}, func(msg client.Msg, err error) {
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if msg, ok := msg.(*client.EvictMsg); ok {
+			evicted = append(evicted, msg.Addr)
+			// handler is called synchronously by the watcher: delete before next poll
+			if _, err := c.RefreshBufferDelete(3); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	if err := c.WatchRefreshBuffer(ctx, time.Millisecond); !errors.Is(err, context.Canceled) {
+		t.Fatalf("invalid error %v - expected %v", err, context.Canceled)
+	}
+	if !slices.Equal(evicted, []uint{5}) {
+		t.Fatalf("invalid evicted locos %v - expected %v", evicted, []uint{5})
+	}
+}
@@ -0,0 +1,274 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes (RFC 6455).
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xa
+)
+
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocketClient provides a WebSocket connection to the Raspberry Pi Pico W
+// (like via a gateway bridging WebSocket to the command station).
+//
+// Each command line is sent as text message. The messages received are reassembled
+// into the line oriented reply stream, a message not ending with a line feed is terminated
+// by a line feed.
+type WebSocketClient struct {
+	url  *url.URL
+	wmu  sync.Mutex // frames are written by Write and by Read (pong)
+	conn net.Conn
+	r    *bufio.Reader
+	line []byte // partially written command line
+	data []byte // unread message data
+}
+
+// NewWebSocketClient returns a new WebSocket connection instance.
+// The url scheme needs to be "ws" or "wss" (TLS).
+func NewWebSocketClient(rawURL string) (*WebSocketClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil, fmt.Errorf("invalid websocket url scheme %q - expected ws or wss", u.Scheme)
+	}
+
+	c := &WebSocketClient{url: u}
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// URL returns the WebSocket url.
+func (c *WebSocketClient) URL() string { return c.url.String() }
+
+func (c *WebSocketClient) dial() (net.Conn, error) {
+	host := c.url.Host
+	if c.url.Port() == "" {
+		port := "80"
+		if c.url.Scheme == "wss" {
+			port = "443"
+		}
+		host = net.JoinHostPort(c.url.Hostname(), port)
+	}
+	dialer := &net.Dialer{Timeout: DefaultDialTimeout}
+	if c.url.Scheme == "wss" {
+		return tls.DialWithDialer(dialer, "tcp", host, &tls.Config{ServerName: c.url.Hostname()})
+	}
+	return dialer.Dial("tcp", host)
+}
+
+// Connect dials the WebSocket url and executes the opening handshake.
+func (c *WebSocketClient) Connect() error {
+	conn, err := c.dial()
+	if err != nil {
+		return fmt.Errorf("connect to %s - %w", c.url, err)
+	}
+	r, err := wsHandshake(conn, c.url)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("websocket handshake %s - %w", c.url, err)
+	}
+	c.conn, c.r = conn, r
+	c.line, c.data = nil, nil
+	return nil
+}
+
+func wsHandshake(conn net.Conn, u *url.URL) (*bufio.Reader, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-WebSocket-Key":     {key},
+			"Sec-WebSocket-Version": {"13"},
+		},
+		Host: u.Host,
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("invalid status %s", resp.Status)
+	}
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != wsAccept(key) {
+		return nil, fmt.Errorf("invalid accept key %s", accept)
+	}
+	return r, nil
+}
+
+// wsAccept returns the accept key of the server handshake.
+func wsAccept(key string) string {
+	h := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// writeFrame writes a single masked client frame.
+func (c *WebSocketClient) writeFrame(opcode byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	hdr := []byte{0x80 | opcode} // final fragment
+	switch n := len(payload); {
+	case n < 126:
+		hdr = append(hdr, 0x80|byte(n))
+	case n <= 0xffff:
+		hdr = append(hdr, 0x80|126)
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(n))
+	default:
+		hdr = append(hdr, 0x80|127)
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	hdr = append(hdr, mask[:]...)
+
+	frame := append(hdr, payload...)
+	masked := frame[len(hdr):]
+	for i := range masked {
+		masked[i] ^= mask[i%4]
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// readFrame reads a single server frame.
+func (c *WebSocketClient) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.r, hdr[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, opcode = hdr[0]&0x80 != 0, hdr[0]&0x0f
+	n := uint64(hdr[1] & 0x7f)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.r, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.r, b[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	if n > maxDatagramSize {
+		return false, 0, nil, fmt.Errorf("websocket frame size %d exceeds maximum %d", n, maxDatagramSize)
+	}
+	var mask []byte
+	if hdr[1]&0x80 != 0 { // servers should not mask frames, but accept them anyway
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(c.r, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if mask != nil {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// Read implements the Conn interface.
+func (c *WebSocketClient) Read(p []byte) (n int, err error) {
+	for len(c.data) == 0 {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, err
+		}
+		switch opcode {
+		case wsOpText, wsOpBinary, wsOpContinuation:
+			// terminate message (last fragment)
+			if fin && len(payload) != 0 && !bytes.HasSuffix(payload, []byte{'\n'}) {
+				payload = append(payload, '\n')
+			}
+			c.data = payload
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return 0, err
+			}
+		case wsOpClose:
+			return 0, io.EOF
+		}
+	}
+	n = copy(p, c.data)
+	c.data = c.data[n:]
+	return n, nil
+}
+
+// Write implements the Conn interface.
+// Each complete command line (terminated by a carriage return) is sent as text message.
+func (c *WebSocketClient) Write(p []byte) (n int, err error) {
+	for _, b := range p {
+		c.line = append(c.line, b)
+		if b != '\r' {
+			continue
+		}
+		if err := c.writeFrame(wsOpText, c.line); err != nil {
+			return 0, err
+		}
+		c.line = c.line[:0]
+	}
+	return len(p), nil
+}
+
+// SetWriteDeadline sets the write deadline of the connection.
+func (c *WebSocketClient) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// Close implements the Conn interface.
+// A close message is sent (best-effort) before the connection is closed.
+func (c *WebSocketClient) Close() error {
+	c.writeFrame(wsOpClose, binary.BigEndian.AppendUint16(nil, 1000)) //nolint: errcheck // normal closure
+	return c.conn.Close()
+}
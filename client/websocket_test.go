@@ -0,0 +1,157 @@
+package client_test
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// wsReadFrame reads a (masked) client frame.
+func wsReadFrame(r io.Reader) (opcode byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := uint64(hdr[1] & 0x7f)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(b[:])
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return hdr[0] & 0x0f, payload, nil
+}
+
+// wsWriteFrame writes an unmasked server frame (payload < 126 bytes).
+func wsWriteFrame(w io.Writer, fin bool, opcode byte, payload []byte) error {
+	b0 := opcode
+	if fin {
+		b0 |= 0x80
+	}
+	_, err := w.Write(append([]byte{b0, byte(len(payload))}, payload...))
+	return err
+}
+
+// wsStation returns a http handler simulating a command station behind a WebSocket gateway.
+// Replies are sent as one text message per line without line terminator.
+func wsStation(t *testing.T, handler stationHandler, pongCh chan<- string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			http.Error(w, "upgrade required", http.StatusUpgradeRequired)
+			return
+		}
+		h := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(h[:]) + "\r\n\r\n")
+		rw.Flush()
+
+		// ping to be answered by the client
+		wsWriteFrame(conn, true, 0x9, []byte("ping"))
+
+		br := bufio.NewReader(rw)
+		for {
+			opcode, payload, err := wsReadFrame(br)
+			if err != nil {
+				return
+			}
+			switch opcode {
+			case 0x8: // close
+				return
+			case 0xa: // pong
+				pongCh <- string(payload)
+			case 0x1: // text
+				cmdLine := strings.TrimSuffix(string(payload), "\r")
+				fields := strings.Split(strings.TrimPrefix(cmdLine, "+"), " ")
+				for _, line := range handler(fields[0], fields[1:]) {
+					// fragment multi line replies
+					if len(line) > 1 && line[0] == '-' {
+						wsWriteFrame(conn, false, 0x1, []byte(line[:1]))
+						wsWriteFrame(conn, true, 0x0, []byte(line[1:]))
+						continue
+					}
+					wsWriteFrame(conn, true, 0x1, []byte(line))
+				}
+			}
+		}
+	}
+}
+
+func TestWebSocketClient(t *testing.T) {
+	pongCh := make(chan string, 10)
+	server := httptest.NewServer(wsStation(t, mockStation(), pongCh))
+	defer server.Close()
+
+	conn, err := client.NewWebSocketClient("ws" + strings.TrimPrefix(server.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.New(conn, nil)
+	defer c.Close()
+
+	temp, err := c.Temp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp != 27.5 {
+		t.Fatalf("invalid temperature %f - expected %f", temp, 27.5)
+	}
+	help, err := c.Help()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(help) != 2 {
+		t.Fatalf("invalid help %v", help)
+	}
+	if pong := <-pongCh; pong != "ping" {
+		t.Fatalf("invalid pong %s", pong)
+	}
+
+	// reconnect re-dials the websocket
+	if err := c.Reconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Temp(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebSocketClientInvalidScheme(t *testing.T) {
+	if _, err := client.NewWebSocketClient("http://localhost"); err == nil {
+		t.Fatal("expected error on invalid url scheme")
+	}
+}
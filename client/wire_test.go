@@ -0,0 +1,134 @@
+package client_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/pico-cs/go-client/client"
+)
+
+// rawConn is a mock connection recording the raw bytes written.
+type rawConn struct {
+	*client.MockConn
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (c *rawConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	c.buf.Write(p)
+	c.mu.Unlock()
+	return c.MockConn.Write(p)
+}
+
+// written returns and resets the bytes written.
+func (c *rawConn) written() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.buf.String()
+	c.buf.Reset()
+	return s
+}
+
+// wireStation is a test station handler replying valid values to all commands.
+func wireStation(cmd string, args []string) []string {
+	switch cmd {
+	case "h":
+		return []string{"."}
+	case "f":
+		return []string{"-0 0 0", "-ff ff ff ff", "."}
+	case "r":
+		return refreshBufferReply(3)
+	case "b":
+		return []string{"=pico E66038B713849D31"}
+	case "t", "ioadc":
+		return []string{"=1.5"}
+	case "lcv1718":
+		return []string{"=192 3"}
+	case "cv", "ls", "lcvbyte", "lladdr", "rd":
+		return []string{"=" + args[len(args)-1]}
+	}
+	return []string{"=t"}
+}
+
+// TestWireFormat pins the command lines written by the client methods.
+// Only commands provided by the firmware are pinned: commands gated on the command station
+// help (like ReadLocoCVByte, see HasCommand) are covered by their own tests.
+func TestWireFormat(t *testing.T) {
+	conn := &rawConn{MockConn: client.NewMockConn()}
+	conn.HandleFunc(wireStation)
+	c := client.New(conn, nil)
+	defer c.Close()
+
+	tests := []struct {
+		name string
+		fct  func() error
+		wire string
+	}{
+		{"Help", func() error { _, err := c.Help(); return err }, "+h\r"},
+		{"Board", func() error { _, err := c.Board(); return err }, "+b\r"},
+		{"Store", func() error { _, err := c.Store(); return err }, "+s\r"},
+		{"Temp", func() error { _, err := c.Temp(); return err }, "+t\r"},
+		{"CV", func() error { _, err := c.CV(client.CVNumSyncBit); return err }, "+cv 1\r"},
+		{"SetCV", func() error { _, err := c.SetCV(client.CVNumRepeat, 5); return err }, "+cv 2 5\r"},
+		{"MTE", func() error { _, err := c.MTE(); return err }, "+mte\r"},
+		{"SetMTETrue", func() error { _, err := c.SetMTE(true); return err }, "+mte t\r"},
+		{"SetMTEFalse", func() error { _, err := c.SetMTE(false); return err }, "+mte f\r"},
+		{"LocoDir", func() error { _, err := c.LocoDir(3); return err }, "+ld 3\r"},
+		{"SetLocoDir", func() error { _, err := c.SetLocoDir(3, true); return err }, "+ld 3 t\r"},
+		{"ToggleLocoDir", func() error { _, err := c.ToggleLocoDir(3); return err }, "+ld 3 ~\r"},
+		{"LocoSpeed128", func() error { _, err := c.LocoSpeed128(3); return err }, "+ls 3\r"},
+		{"SetLocoSpeed128", func() error { _, err := c.SetLocoSpeed128(3, 40); return err }, "+ls 3 40\r"},
+		{"LocoFct", func() error { _, err := c.LocoFct(3, 0); return err }, "+lf 3 0\r"},
+		{"SetLocoFct", func() error { _, err := c.SetLocoFct(3, 28, false); return err }, "+lf 3 28 f\r"},
+		{"ToggleLocoFct", func() error { _, err := c.ToggleLocoFct(3, 68); return err }, "+lf 3 68 ~\r"},
+		{"SetLocoCVByte", func() error { _, err := c.SetLocoCVByte(3, 29, 6); return err }, "+lcvbyte 3 29 6\r"},
+		{"SetLocoCVBytes", func() error { _, err := c.SetLocoCVBytes(3, map[uint]byte{5: 200, 2: 10}); return err }, "+lcvbyte 3 2 10\r+lcvbyte 3 5 200\r"},
+		{"SetLocoCVBit", func() error { _, err := c.SetLocoCVBit(3, 29, 5, true); return err }, "+lcvbit 3 29 5 t\r"},
+		{"SetLocoCV29Bit5", func() error { _, err := c.SetLocoCV29Bit5(3, false); return err }, "+lcv29bit5 3 f\r"},
+		{"SetLocoLaddr", func() error { _, err := c.SetLocoLaddr(3, 1024); return err }, "+lladdr 3 1024\r"},
+		{"LocoCV1718", func() error { _, _, err := c.LocoCV1718(3); return err }, "+lcv1718 3\r"},
+		{"SetLocoConsist", func() error {
+			_, err := c.SetLocoConsist(3, client.CV19{Addr: 10, Reversed: true})
+			return err
+		}, "+lcvbyte 3 19 138\r"},
+		{"SetAccFct", func() error { _, err := c.SetAccFct(10, 1, true); return err }, "+af 10 1 t\r"},
+		{"SetAccTime", func() error { _, err := c.SetAccTime(10, 1, 5); return err }, "+at 10 1 5\r"},
+		{"SetAccStatus", func() error { _, err := c.SetAccStatus(10, 8); return err }, "+as 10 8\r"},
+		{"IOADC", func() error { _, err := c.IOADC(4); return err }, "+ioadc 4\r"},
+		{"IOVal", func() error { _, err := c.IOVal(client.IOCmdLocal, 25); return err }, "+ioval 0 25\r"},
+		{"SetIOVal", func() error { _, err := c.SetIOVal(client.IOCmdLocal, 25, true); return err }, "+ioval 0 25 t\r"},
+		{"ToggleIOVal", func() error { _, err := c.ToggleIOVal(client.IOCmdLocal, 25); return err }, "+ioval 0 25 ~\r"},
+		{"IODir", func() error { _, err := c.IODir(client.IOCmdLocal, 2); return err }, "+iodir 0 2\r"},
+		{"SetIODir", func() error { _, err := c.SetIODir(client.IOCmdLocal, 2, false); return err }, "+iodir 0 2 f\r"},
+		{"ToggleIODir", func() error { _, err := c.ToggleIODir(client.IOCmdLocal, 2); return err }, "+iodir 0 2 ~\r"},
+		{"IOUp", func() error { _, err := c.IOUp(client.IOCmdLocal, 2); return err }, "+ioup 0 2\r"},
+		{"SetIOUp", func() error { _, err := c.SetIOUp(client.IOCmdLocal, 2, true); return err }, "+ioup 0 2 t\r"},
+		{"ToggleIOUp", func() error { _, err := c.ToggleIOUp(client.IOCmdLocal, 2); return err }, "+ioup 0 2 ~\r"},
+		{"IODown", func() error { _, err := c.IODown(client.IOCmdLocal, 2); return err }, "+iodown 0 2\r"},
+		{"SetIODown", func() error { _, err := c.SetIODown(client.IOCmdLocal, 2, true); return err }, "+iodown 0 2 t\r"},
+		{"ToggleIODown", func() error { _, err := c.ToggleIODown(client.IOCmdLocal, 2); return err }, "+iodown 0 2 ~\r"},
+		{"RefreshBuffer", func() error { _, err := c.RefreshBuffer(); return err }, "+r\r"},
+		{"RefreshBufferReset", func() error { _, err := c.RefreshBufferReset(); return err }, "+rr\r"},
+		{"RefreshBufferDelete", func() error { _, err := c.RefreshBufferDelete(3); return err }, "+rd 3\r"},
+		{"EmergencyStopAll", func() error { return c.EmergencyStopAll() }, "+r\r+ls 3 1\r"},
+		{"Flash", func() error { _, err := c.Flash(); return err }, "+f\r"},
+		{"FlashFormat", func() error { _, err := c.FlashFormat(); return err }, "+ff\r"},
+		{"Batch", func() error {
+			_, err := c.Batch().SetLocoSpeed128(3, 40).SetLocoFct(3, 0, true).SetIOVal(client.IOCmdLocal, 25, false).Run()
+			return err
+		}, "+ls 3 40\r+lf 3 0 t\r+ioval 0 25 f\r"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.fct(); err != nil {
+				t.Fatal(err)
+			}
+			if wire := conn.written(); wire != test.wire {
+				t.Fatalf("invalid wire format %q - expected %q", wire, test.wire)
+			}
+		})
+	}
+}